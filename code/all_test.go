@@ -175,3 +175,29 @@ func TestComparePackagePaths(t *testing.T) {
 		}
 	}
 }
+
+func TestPackageCouplingMetrics(t *testing.T) {
+	var vs = []struct {
+		ca, ce      int
+		instability float64
+		distance    float64
+	}{
+		{0, 0, 0, 1},
+		{3, 0, 0, 1},
+		{0, 2, 1, 0},
+		{1, 3, 0.75, 0.25},
+	}
+
+	for _, v := range vs {
+		pkg := &Package{
+			Deps:     make([]*Package, v.ce),
+			DepedBys: make([]*Package, v.ca),
+		}
+		if i := pkg.Instability(); i != v.instability {
+			t.Errorf("instability not match (Ca=%d, Ce=%d): %v vs. %v", v.ca, v.ce, i, v.instability)
+		}
+		if d := pkg.DistanceFromMainSequence(); d != v.distance {
+			t.Errorf("distance not match (Ca=%d, Ce=%d): %v vs. %v", v.ca, v.ce, d, v.distance)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"go/types"
 	"log"
 	"strings"
+	"sync"
 	//"runtime/debug"
 
 	"golang.org/x/tools/go/types/typeutil"
@@ -76,6 +77,11 @@ type CodeAnalyzer struct {
 	ttype2TypeInfoTable typeutil.Map
 	allTypeInfos        []*TypeInfo
 
+	// After the analysis phase, the exported methods which might
+	// register types might be called concurrently (when building
+	// pages concurrently). They are protected by this mutex.
+	typesMutex sync.Mutex
+
 	instantiatedTypes        *list.List
 	numSeenInstantiatedTypes uint32
 
@@ -362,7 +368,10 @@ func (d *CodeAnalyzer) RegisterType(t types.Type) *TypeInfo {
 }
 
 // LookForType trys to find out the TypeInfo registered for the spefified types.Type.
+// It is concurrency safe after the analysis phase.
 func (d *CodeAnalyzer) LookForType(t types.Type) *TypeInfo {
+	d.typesMutex.Lock()
+	defer d.typesMutex.Unlock()
 	return d.registeringType(t, false)
 }
 
@@ -450,7 +459,11 @@ func (d *CodeAnalyzer) registeringType(tt types.Type, createOnNonexist bool) *Ty
 }
 
 // RetrieveTypeName trys to retrieve the TypeName from a TypeInfo.
+// It is concurrency safe after the analysis phase.
 func (d *CodeAnalyzer) RetrieveNamedType(t *TypeInfo) (*TypeInfo, bool) {
+	d.typesMutex.Lock()
+	defer d.typesMutex.Unlock()
+
 	if tn := t.TypeName; tn != nil {
 		return t, false
 	}
@@ -518,14 +531,18 @@ func (d *CodeAnalyzer) CheckTypeMethodContributingToTypeImplementations(pkg, typ
 }
 
 // CleanImplements returns a clean list of the implementions for a TypeInfo.
+// It is concurrency safe after the analysis phase.
 func (d *CodeAnalyzer) CleanImplements(self *TypeInfo, includingUnnamed bool) []Implementation {
 	// remove:
 	// * self
 	// * unnameds whose underlied names are also in the list (or are self)
 	// The ones in internal packages are kept.
 
-	typeLookupTable := d.tempTypeLookupTable()
-	defer d.resetTempTypeLookupTable()
+	d.typesMutex.Lock()
+	defer d.typesMutex.Unlock()
+
+	// Not use d.tempTypeLookupTable(), which is not concurrency safe.
+	typeLookupTable := make(map[uint32]struct{}, 2*len(self.Implements)+2)
 
 	if itt, ok := self.TT.Underlying().(*types.Interface); ok {
 		typeLookupTable[self.index] = struct{}{}
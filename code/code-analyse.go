@@ -2122,6 +2122,11 @@ func (d *CodeAnalyzer) analyzePackage_CollectMoreStatistics(pkg *Package) {
 	}
 	var isBuiltinPkg = pkg == d.builtinPkg // pkg.Path == "builtin"
 
+	// The abstractness needs all the type names in the package.
+	if !isBuiltinPkg {
+		d.stat_OnPackageCouplingMetrics(pkg)
+	}
+
 	for _, tn := range pkg.PackageAnalyzeResult.AllTypeNames {
 		d.stats.roughTypeNameCount++
 
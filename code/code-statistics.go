@@ -1,6 +1,7 @@
 package code
 
 import (
+	"math"
 	"reflect"
 )
 
@@ -30,6 +31,13 @@ type Stats struct {
 	PackagesByCodeLinesWithBlankLines [100]int32
 	PackagesCodeLineTopList           TopList
 
+	// Coupling metrics. Each element covers a 0.1 wide range.
+	// The last element is for the value 1.0.
+	PackagesByInstability   [11]int32
+	PackagesByAbstractness  [11]int32
+	PackagesByDistance      [11]int32
+	PackagesDistanceTopList TopList
+
 	// Types
 	ExportedTypeNamesByKind    [KindCount]int32
 	ExportedTypeNames          int32
@@ -272,3 +280,15 @@ func (d *CodeAnalyzer) stat_OnNewExportedIdentifer(length int, obj interface{})
 	d.stats.ExportedIdentiferLengthTopList.TryToInit(32)
 	d.stats.ExportedIdentiferLengthTopList.Push(length, obj)
 }
+
+func (d *CodeAnalyzer) stat_OnPackageCouplingMetrics(pkg *Package) {
+	tenths := func(v float64) int {
+		return int(math.Floor(v*10 + 1e-9))
+	}
+	incSliceStat(d.stats.PackagesByInstability[:], tenths(pkg.Instability()))
+	incSliceStat(d.stats.PackagesByAbstractness[:], tenths(pkg.Abstractness()))
+	distance := tenths(pkg.DistanceFromMainSequence())
+	incSliceStat(d.stats.PackagesByDistance[:], distance)
+	d.stats.PackagesDistanceTopList.TryToInit(9) // 0.9
+	d.stats.PackagesDistanceTopList.Push(distance, &pkg.Path)
+}
@@ -6,9 +6,11 @@ import (
 	"go/token"
 	"go/types"
 	"log"
+	"math"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 
 	"golang.org/x/tools/go/packages"
 )
@@ -236,6 +238,23 @@ func (m *Module) printPackageHierarchy() {
 
 }
 
+// IteratePackageHierarchy iterates the package hierarchy of a Module
+// in depth-first order and passes the packages and their depths
+// to the specified callback f. The root package is at depth 0.
+// Fake packages (see IsFake) are also passed.
+func (m *Module) IteratePackageHierarchy(f func(pkg *Package, depth int)) {
+	var iterate func(pkg *Package, depth int)
+	iterate = func(pkg *Package, depth int) {
+		f(pkg, depth)
+		for _, child := range pkg.children {
+			iterate(child, depth+1)
+		}
+	}
+	if m.rootPkg != nil {
+		iterate(m.rootPkg, 0)
+	}
+}
+
 // ToDo: build a trie to run faster?
 func (m *Module) PackageByPath(path string) (r *Package) {
 	if !strings.HasPrefix(path, m.Path) {
@@ -417,6 +436,56 @@ func (p *Package) ParentPackage() *Package {
 	return p.parent
 }
 
+// AfferentCouplings returns the number of packages which depend on a Package (Ca).
+func (p *Package) AfferentCouplings() int {
+	return len(p.DepedBys)
+}
+
+// EfferentCouplings returns the number of packages a Package depends on (Ce).
+func (p *Package) EfferentCouplings() int {
+	return len(p.Deps)
+}
+
+// Instability returns Ce / (Ca + Ce) of a Package.
+// The result is in range [0, 1]. A package without any couplings is viewed as stable (0).
+func (p *Package) Instability() float64 {
+	ca, ce := p.AfferentCouplings(), p.EfferentCouplings()
+	if ca+ce == 0 {
+		return 0
+	}
+	return float64(ce) / float64(ca+ce)
+}
+
+// Abstractness returns the ratio of exported interface type names
+// to all exported type names declared in a Package.
+// The result is in range [0, 1].
+func (p *Package) Abstractness() float64 {
+	if p.PackageAnalyzeResult == nil {
+		return 0
+	}
+	var numExporteds, numInterfaces int
+	for _, tn := range p.AllTypeNames {
+		if !tn.Exported() {
+			continue
+		}
+		numExporteds++
+		if tn.Denoting.Kind() == reflect.Interface {
+			numInterfaces++
+		}
+	}
+	if numExporteds == 0 {
+		return 0
+	}
+	return float64(numInterfaces) / float64(numExporteds)
+}
+
+// DistanceFromMainSequence returns |A + I - 1| of a Package,
+// where A is the abstractness and I is the instability.
+// The result is in range [0, 1]. The lower, the better.
+func (p *Package) DistanceFromMainSequence() float64 {
+	return math.Abs(p.Abstractness() + p.Instability() - 1)
+}
+
 // PackageAnalyzeResult holds the analysis result of a Go package.
 type PackageAnalyzeResult struct {
 	AllTypeNames []*TypeName // not including instantiated ones
@@ -967,11 +1036,18 @@ func (t *TypeInfo) Kind() reflect.Kind {
 	return Kind(t.TT)
 }
 
+// allSelectorsMutex protects the lazily built TypeInfo.AllSelectors maps.
+var allSelectorsMutex sync.Mutex
+
+// SelectorByName returns the selector with the specified name of a TypeInfo.
+// It is concurrency safe.
 func (t *TypeInfo) SelectorByName(name string) *Selector {
 	var n = len(t.AllMethods) + len(t.AllFields)
 	if n == 0 {
 		return nil
 	}
+	allSelectorsMutex.Lock()
+	defer allSelectorsMutex.Unlock()
 	if t.AllSelectors == nil {
 		t.AllSelectors = make(map[string]*Selector, n)
 		for _, sel := range t.AllMethods {
@@ -1123,6 +1199,8 @@ func (c *Constant) TType() types.Type {
 
 // TypeInfo returns the type of a Constant.
 func (c *Constant) TypeInfo(d *CodeAnalyzer) *TypeInfo {
+	d.typesMutex.Lock()
+	defer d.typesMutex.Unlock()
 	if c.Type == nil {
 		c.Type = d.RegisterType(c.TType())
 	}
@@ -1210,6 +1288,8 @@ func (v *Variable) TType() types.Type {
 
 // TypeInfo returns the type of a Variable.
 func (v *Variable) TypeInfo(d *CodeAnalyzer) *TypeInfo {
+	d.typesMutex.Lock()
+	defer d.typesMutex.Unlock()
 	if v.Type == nil {
 		v.Type = d.RegisterType(v.TType())
 	}
@@ -1307,6 +1387,8 @@ func (f *Function) TType() types.Type {
 
 // TypeInfo returns the tyoe of a Function.
 func (f *Function) TypeInfo(d *CodeAnalyzer) *TypeInfo {
+	d.typesMutex.Lock()
+	defer d.typesMutex.Unlock()
 	if f.Type == nil {
 		f.Type = d.RegisterType(f.TType())
 	}
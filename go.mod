@@ -3,12 +3,10 @@ module go101.org/golds
 go 1.22.0
 
 require (
+	golang.org/x/mod v0.22.0
 	golang.org/x/net v0.33.0
 	golang.org/x/text v0.21.0
 	golang.org/x/tools v0.28.0
 )
 
-require (
-	golang.org/x/mod v0.22.0 // indirect
-	golang.org/x/sync v0.10.0 // indirect
-)
+require golang.org/x/sync v0.10.0 // indirect
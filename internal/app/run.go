@@ -217,7 +217,43 @@ func run() {
 		RenderDocLinks:         *renderDocLinksFlag,
 		UnfoldAllInitially:     *unfoldAllInitiallyFlag,
 		Theme:                  *themeFlag,
+		HeaderTemplate:         *headerTemplateFlag,
+		FooterTemplate:         *footerTemplateFlag,
+		SidebarTemplate:        *sidebarTemplateFlag,
+		LogoFile:               *logoFlag,
+		ProductName:            *productNameFlag,
+		TemplatesDir:           *templatesFlag,
+		TranslationsDir:        *translationsFlag,
 		VerboseLogs:            verboseMode,
+		GeneratedPackages:      *generatedPackagesFlag,
+		ExternalDocsURL:        *externalDocsURLFlag,
+		DocsVersion:            *docsVersionFlag,
+		BaseURL:                *baseURLFlag,
+		CheckLinks:             *checkLinksFlag,
+	}
+
+	// terminal docs printing mode
+	if flag.NArg() >= 2 && flag.Arg(0) == "doc" {
+		if err := server.PrintDoc(options, flag.Arg(1), flag.Args()[2:], os.Stdout, printUsage); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	// relation querying mode
+	if flag.NArg() >= 3 && flag.Arg(0) == "query" {
+		jsonOutput := false
+		switch format := *queryFormatFlag; format {
+		default:
+			log.Fatalln("Unknown query-format option:", format)
+		case "lines":
+		case "json":
+			jsonOutput = true
+		}
+		if err := server.Query(options, flag.Arg(1), flag.Arg(2), flag.Args()[3:], jsonOutput, os.Stdout, printUsage); err != nil {
+			log.Fatalln(err)
+		}
+		return
 	}
 
 	// static docs generating mode
@@ -233,8 +269,19 @@ func run() {
 			viewDocsCommand := func(docsDir string) string {
 				return os.Args[0] + " -dir=" + docsDir
 			}
-			// ToDo: also support json format output
 			server.GenDocs(options, flag.Args(), outputDir, silentMode, printUsage, *moregcFlag, viewDocsCommand)
+		case "json":
+			server.GenJSON(options, flag.Args(), outputDir, silentMode, printUsage)
+		case "markdown":
+			server.GenMarkdown(options, flag.Args(), outputDir, silentMode, printUsage)
+		case "docset":
+			server.GenDocset(options, flag.Args(), outputDir, *docsetNameFlag, silentMode, printUsage, *moregcFlag)
+		case "book", "epub":
+			server.GenBook(options, flag.Args(), outputDir, *bookModuleFlag, intent == "epub", silentMode, printUsage)
+		case "lsif":
+			server.GenLSIF(options, flag.Args(), outputDir, silentMode, printUsage)
+		case "tags":
+			server.GenTags(options, flag.Args(), outputDir, silentMode, printUsage)
 		}
 
 		return
@@ -267,8 +314,9 @@ var verboseFlag = flag.Bool("verbose", false, "verbose mode")
 // var updateFlag = flag.Bool("update", false, "update self")
 var versionFlag = flag.Bool("version", false, "show version info")
 var genFlag = flag.Bool("gen", false, "HTML generation mode")
-var genIntentFlag = flag.String("gen-intent", "docs", "docs | testdata")
+var genIntentFlag = flag.String("gen-intent", "docs", "docs | docset | json | markdown | book | epub | lsif | tags | testdata")
 var langFlag = flag.String("lang", "", "docs generation language tag")
+var translationsFlag = flag.String("translations", "", "the directory containing translation catalog files")
 var dirFlag = flag.String("dir", "", "directory for file serving or HTML generation")
 var portFlag = flag.String("port", "", "preferred server port [1024, 65536]. Default: 56789 or 9999")
 var sFlag = flag.Bool("s", false, "not open a browser automatically")
@@ -287,6 +335,13 @@ var allowNetworkConnection = flag.Bool("allow-network-connection", false, "speci
 
 var footerShowingMannerFlag = flag.String("footer", "verbose+qrcode", "verbose+qrcode | verbose | simple | none")
 
+var headerTemplateFlag = flag.String("header-template", "", "the HTML template file of custom page headers")
+var footerTemplateFlag = flag.String("footer-template", "", "the HTML template file of custom page footers")
+var sidebarTemplateFlag = flag.String("sidebar-template", "", "the HTML template file of custom page sidebars")
+var logoFlag = flag.String("logo", "", "the PNG or SVG logo image file shown in page headers")
+var productNameFlag = flag.String("product-name", "", "the product name shown in page headers and titles")
+var templatesFlag = flag.String("templates", "", "the directory containing templates overriding the default page templates")
+
 var renderDocLinksFlag = flag.Bool("render-doclinks", false, "render links in doc comments")
 var unfoldAllInitiallyFlag = flag.Bool("unfold-all-initially", false, "unfold all foldables initially")
 
@@ -294,7 +349,18 @@ var unfoldAllInitiallyFlag = flag.Bool("unfold-all-initially", false, "unfold al
 var emphasizeWdPackagesFlag = flag.Bool("emphasize-wdpkgs", false, "promote working directory packages")
 var wdPkgsListingMannerFlag = flag.String("wdpkgs-listing", "", "specify how to list working directory packages")
 
-var themeFlag = flag.String("theme", "auto", "auto | light | dark")
+var themeFlag = flag.String("theme", "auto", "auto | light | dark | a user theme name")
+
+var docsetNameFlag = flag.String("docset-name", "GoPackages", "the name of the generated docset")
+var bookModuleFlag = flag.String("book-module", "", "the module to generate a book for")
+
+var generatedPackagesFlag = flag.String("generated-packages", "all", "all | wd | comma-separated package patterns")
+var externalDocsURLFlag = flag.String("external-docs-url", "", "the docs base URL of the not generated packages")
+var docsVersionFlag = flag.String("docs-version", "", "generate versioned docs for the specified version")
+var baseURLFlag = flag.String("base-url", "", "the URL the generated docs will be deployed at")
+var checkLinksFlag = flag.Bool("check-links", false, "check the links in the generated docs")
+
+var queryFormatFlag = flag.String("query-format", "lines", "lines | json")
 
 func printVersion(out io.Writer) {
 	fmt.Fprintf(out, "Golds %s\n", Version)
@@ -307,15 +373,13 @@ func printVersion(out io.Writer) {
 //		generation mode only. Enabling it will
 //		slow down the docs generation speed.
 
-// Planning options:
-//	-generated-packages=wd|all
-//		Determine which packages the docs will be generated for.
-
 func printUsage(out io.Writer) {
 	fmt.Fprintf(out, `Golds - a Go local docs server (%[2]s).
 
 Usage:
 	%[1]v [options] [arguments]
+	%[1]v [options] doc <query> [arguments]
+	%[1]v [options] query <relation> <target> [arguments]
 
 Options:
 	-h/-help
@@ -333,12 +397,97 @@ Options:
 		logs in docs generation mode.
 	-gen
 		Static HTML docs generation mode.
+		On reruns, unchanged pages are not
+		rewritten and stale pages are removed.
+	-gen-intent=docs|docset|json|markdown|book|epub|lsif|tags
+		Specify what to generate in generation
+		mode (default is docs):
+		* docs: HTML docs pages.
+		* docset: a Dash/Zeal docset bundle
+		  (<DocsetName>.docset), containing
+		  the HTML docs pages and a search
+		  index of packages and identifiers.
+		* json: JSON documents of the analysis
+		  data, one for each package, plus
+		  modules, overview and statistics ones.
+		* markdown: Markdown docs, one file for
+		  each package, plus a module index
+		  file (index.md).
+		* book: a single self-contained HTML
+		  file (book.html) containing the docs
+		  of all the packages in a module.
+		* epub: the same as book, but as an
+		  EPUB file (book.epub).
+		* lsif: a LSIF dump (dump.lsif) of the
+		  definitions, references and
+		  implementations, which monikers are
+		  in the pkg..Type.selector form.
+		* tags: a Universal-ctags file (tags)
+		  and an Emacs etags file (TAGS).
+	-docset-name=<DocsetName>
+		Specify the name of the generated
+		docset (default is GoPackages).
+	-book-module=<ModulePath>
+		Specify the module to generate a book
+		for. By default, it is the module of
+		the current directory.
+	-generated-packages=all|wd|<Patterns>
+		Specify the packages whose full docs
+		pages will be generated in docs and
+		docset generation modes (default is
+		all). The value may be a comma-separated
+		list of "wd" (the packages in the module
+		of the current directory) and package
+		patterns, in which "..." matches any
+		string. Links to other packages point to
+		the URL specified by -external-docs-url,
+		or to minimal stub pages if it is blank.
+	-external-docs-url=<BaseURL>
+		Specify the docs website URL which the
+		links to the not generated packages are
+		based on, such as https://pkg.go.dev/.
+	-docs-version=<Version>|auto
+		Generate versioned docs pages into the
+		<ContentDirectory>/<ModulePath>/<Version>
+		directory, for the module of the current
+		directory. "auto" means using the module
+		version or "git describe". Every page has a
+		version switcher. The docs of different
+		versions share a <ContentDirectory>/assets
+		directory.
+	-base-url=<URL>
+		Specify the URL the generated docs will
+		be deployed at (the URL of the
+		<ContentDirectory> directory). When
+		specified, pages contain canonical links,
+		and sitemap.xml, robots.txt, 404.html and
+		.nojekyll files are also generated, so
+		that the docs could be hosted on static
+		hosting services, such as GitHub Pages.
+		For versioned docs, the sitemap.xml in
+		the module docs directory lists the
+		sitemaps of all versions.
+		The generated docs always contain a
+		client-side search index, which is used
+		by the search page.
+	-check-links
+		Check the links in the generated docs
+		after generating them. Links to files
+		which don't exist and links to anchors
+		which are not found are reported, grouped
+		by the kinds of the pages containing them,
+		and the program exits with a non-zero code
+		if there are such broken links.
 	-dir=<ContentDirectory>|memory
 		Specify the docs generation or file
 		serving diretory. A new created subfolder
 		with a random name under the current directory
 		will be used if this option is not specified.
 		"memory" means not to save (for testing).
+		If the path ends with ".zip" or ".tar.gz",
+		the generated docs are written into the
+		archive file, and the files in the archive
+		file are served in file serving mode.
 	-nostats
 		Disable the statistics feature.
 	-nouses
@@ -388,6 +537,36 @@ Options:
 		  promotion info.
 		* verbose+qrcode: include verbose content
 		  and a qr-code.
+	-header-template=<TemplateFile>
+	-footer-template=<TemplateFile>
+	-sidebar-template=<TemplateFile>
+		Specify the Go html/template files of the
+		custom header, footer and sidebar HTML
+		fragments, which are shown in every page
+		(in the custom-header, custom-footer and
+		custom-sidebar elements). The templates
+		may use the page metadata: .PageType,
+		.PackagePath, .Title, .GoldsVersion,
+		.GoVersion, .ProductName, .LogoURL and
+		.OverviewURL. The URLs are relative.
+	-logo=<ImageFile>
+		Specify a PNG or SVG logo image file,
+		which is shown in page headers.
+	-product-name=<Name>
+		Specify a product name, which is shown
+		in page headers and titles.
+	-templates=<TemplateDirectory>
+		Specify a directory containing Go
+		html/template files, each of which
+		overrides the default page template
+		with the same name. Overridable ones
+		include package-details.html,
+		package-header.html, package-stub.html,
+		package-dependencies.html,
+		method-implementations.html,
+		source-code.html, references.html and
+		not-found.html. The directory may also
+		contain other templates used by them.
 	-render-doclinks
 		Whether or not to render links in docs.
 	-unfold-all-initially
@@ -400,6 +579,40 @@ Options:
 		  it exists).
 		* light
 		* dark
+		* the name of a user theme. User themes
+		  are loaded from the subdirectories of the
+		  $UserConfigDir/golds/themes directory.
+		  Each of them contains a theme.css file
+		  and/or a theme.json file, which specifies
+		  the name, whether or not the theme is dark
+		  and the syntax-highlight colors, such as
+		  {"dark": true, "tokens": {"keyword": "#f77"}}.
+		All themes are listed in the theme chooser
+		on every page. In serving mode, the theme
+		chosen by a visitor (or specified with the
+		theme query parameter, such as ?theme=dark)
+		is remembered in a cookie.
+	-lang=<LanguageTag>
+		Specify the language of HTML pages, such as
+		en, zh or ja. In serving mode, the language
+		preferred by the browser (the Accept-Language
+		header) is used if this option is not set,
+		and each visitor may choose a language with
+		the lang query parameter (such as ?lang=zh),
+		which is remembered in a cookie.
+	-translations=<CatalogDirectory>
+		Specify the directory containing translation
+		catalog files (.json or gettext .po files),
+		which add UI languages without rebuilding.
+		The messages missing in a catalog fall back
+		to English. Catalogs with unknown message
+		keys or invalid messages are rejected with
+		errors. By default, the catalogs in the
+		$UserConfigDir/golds/translations directory
+		(if it exists) are loaded.
+	-query-format=lines|json
+		Specify the output format of the query
+		subcommand (default is lines).
 
 Examples:
 	%[1]v std
@@ -417,9 +630,60 @@ Examples:
 		specified by the -dir flag for the
 		packages under the current directory
 		and their dependency packages.
+	%[1]v -gen -generated-packages=wd -external-docs-url=https://pkg.go.dev/ ./...
+		Generate HTML docs pages only for the
+		packages in the module of the current
+		directory, and link other packages to
+		their docs on pkg.go.dev.
+	%[1]v -gen -docs-version=v1.2.0 -dir=./site ./...
+		Generate HTML docs pages of version v1.2.0
+		for the module of the current directory
+		into ./site/<ModulePath>/v1.2.0.
+	%[1]v -gen -base-url=https://example.com/docs/ -dir=./docs ./...
+		Generate HTML docs pages which will be
+		deployed at https://example.com/docs/.
+	%[1]v -gen -check-links -dir=./docs ./...
+		Generate HTML docs pages and check the
+		links in them, which is useful in CI.
+	%[1]v -gen -gen-intent=json -dir=./data ./...
+		Generate JSON documents of the analysis
+		data into the path specified by the -dir
+		flag for the same packages.
+	%[1]v -gen -gen-intent=markdown -dir=./api ./...
+		Generate Markdown docs into the path
+		specified by the -dir flag for the
+		same packages.
+	%[1]v -gen -gen-intent=docset -docset-name=MyLib -dir=. ./...
+		Generate a MyLib.docset bundle in the
+		current directory for the packages
+		under the current directory.
+	%[1]v -gen -gen-intent=epub -dir=./book ./...
+		Generate an EPUB book of the packages in
+		the module of the current directory.
+	%[1]v doc net/http.Client.Do
+		Print the docs of the Do method of the
+		net/http.Client type in terminal, including
+		the implementation and reference info.
+		Use ".." to separate the package path and
+		identifiers if the last token of the
+		package path contains dots, for example:
+		gopkg.in/yaml.v3..Node.Decode
+	%[1]v doc io.Writer std
+		Print the docs of the io.Writer type,
+		with all standard packages analyzed.
+	%[1]v query implements io.Writer ./...
+		List the types implementing io.Writer
+		in the packages under the current
+		directory and their dependencies.
+		Other supported relations include refs
+		(pkg.Identifier[.Selector]), importers
+		(pkg) and methods (pkg.Type).
 	%[1]v -dir=. -s
 		Serve the files in working directory
 		without opening a browser window.
+	%[1]v -dir=./docs.zip
+		Serve the files in the docs.zip archive
+		file without extracting it.
 	%[1]v
 		Serve the files in working directory and
 		open a browser window to list items. If
@@ -30,6 +30,10 @@ func typesNamedTypeParams(*types.Named) *typesTypeParamList {
 	return nil
 }
 
+func typesNamedOrigin(named *types.Named) *types.Named {
+	return named
+}
+
 func writeTypeParamsOfTypeName(page *htmlPage, res *code.TypeName) {
 }
 
@@ -24,6 +24,10 @@ func typesNamedTypeParams(named *types.Named) *typesTypeParamList {
 	return named.TypeParams()
 }
 
+func typesNamedOrigin(named *types.Named) *types.Named {
+	return named.Origin()
+}
+
 func _writeTypeParams(page *htmlPage, fields []*ast.Field) {
 	page.WriteByte('[')
 	defer page.WriteByte(']')
@@ -1,12 +1,32 @@
 package server
 
 import (
+	"bytes"
+	"container/list"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
 
+	"go101.org/golds/code"
+	theme "go101.org/golds/internal/server/themes"
+	translation "go101.org/golds/internal/server/translations"
 	"go101.org/golds/internal/util"
 )
 
@@ -148,6 +168,913 @@ func TestPreviousVersion(t *testing.T) {
 	}
 }
 
+func TestNameMatchScore(t *testing.T) {
+	type testCase struct {
+		name, word string
+		score      int
+	}
+	var testCases = []testCase{
+		{"reader", "reader", 1000},
+		{"readerat", "reader", 600},
+		{"bufio.reader", "reader", 300},
+		{"readall", "rdal", 100},
+		{"writer", "reader", 0},
+		{"rd", "rdal", 0},
+		{"readdir", "rdr", 0}, // too short to be fuzzy matched
+		{"readdir", "rd", 0},
+	}
+	for _, tc := range testCases {
+		if score := nameMatchScore(tc.name, tc.word); score != tc.score {
+			t.Errorf("name match score not match (%s, %s): %d vs. %d", tc.name, tc.word, score, tc.score)
+		}
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	var names = []string{
+		"Reader", "ReaderAt", "ReaderFrom", "MultiReader", "LimitedReader",
+		"ReadHeader", "ReadRequest", "ReadDir", "ReadAll", "ReadFile",
+		"NewReader", "TeeReader", "Writer", "Printf", "Errorf", "Sprint",
+		"RawConn", "Request", "Header", "Rand", "Read", "Ready", "Order",
+	}
+	ds := &docServer{}
+	for _, name := range names {
+		ds.searchItems = append(ds.searchItems, &SearchItem{
+			Kind:      "type",
+			Name:      name,
+			Package:   "io",
+			Exported:  true,
+			lowerKeys: []string{strings.ToLower(name), "io." + strings.ToLower(name)},
+		})
+	}
+
+	var testCases = []struct {
+		query string
+		names []string
+	}{
+		// exact > prefix > contains > fuzzy
+		{"reader", []string{"Reader", "ReaderAt", "ReaderFrom", "LimitedReader", "MultiReader", "NewReader", "TeeReader", "ReadHeader"}},
+		{"rdhdr", []string{"ReadHeader"}},
+		// Short words are not fuzzy matched (but still matched as substrings).
+		{"rd", []string{"Order"}},
+		{"rdr", nil},
+		{"rea", []string{"Read", "ReadAll", "ReadDir", "ReadFile", "ReadHeader", "ReadRequest", "Reader", "ReaderAt", "ReaderFrom", "Ready", "LimitedReader", "MultiReader", "NewReader", "TeeReader"}},
+	}
+	for _, tc := range testCases {
+		var names []string
+		for _, g := range ds.search(tc.query).Groups {
+			for _, item := range g.Items {
+				names = append(names, item.Name)
+			}
+		}
+		if strings.Join(names, " ") != strings.Join(tc.names, " ") {
+			t.Errorf("search results of %q not match:\n%v\nvs.\n%v", tc.query, names, tc.names)
+		}
+	}
+}
+
+func TestDocSummary(t *testing.T) {
+	var testCases = [][2]string{
+		{"", ""},
+		{"Reader reads data.", "Reader reads data."},
+		{"Reader reads\ndata. It is buffered.", "Reader reads data."},
+		{"First paragraph\n\nSecond paragraph.", "First paragraph"},
+	}
+	for _, tc := range testCases {
+		if summary := docSummary(tc[0]); summary != tc[1] {
+			t.Errorf("doc summary not match (%q): %q vs. %q", tc[0], summary, tc[1])
+		}
+	}
+}
+
+func TestSearchCodeInContent(t *testing.T) {
+	var content = []byte("a\nfoo\nb\nc\nd\ne\nfoo bar foo\nf")
+	type testCase struct {
+		query         string
+		maxMatches    int
+		numMatches    int
+		more          bool
+		lineNumbers   []int
+		matchedNumber []int
+	}
+	var testCases = []testCase{
+		{"foo", 100, 2, false, []int{1, 2, 3, 4, 5, 6, 7, 8}, []int{2, 7}},
+		{"foo", 2, 2, false, []int{1, 2, 3, 4, 5, 6, 7, 8}, []int{2, 7}},
+		{"foo", 1, 1, true, []int{1, 2, 3, 4}, []int{2}},
+		{"^f$", 100, 1, false, []int{6, 7, 8}, []int{8}},
+		{"xyz", 100, 0, false, nil, nil},
+	}
+	for _, tc := range testCases {
+		re := regexp.MustCompile("(?m)" + tc.query)
+		lines, n, more := searchCodeInContent(re, content, 2, tc.maxMatches)
+		if n != tc.numMatches || more != tc.more {
+			t.Errorf("matched line count not match (%s): %d, %v vs. %d, %v", tc.query, n, more, tc.numMatches, tc.more)
+			continue
+		}
+		var lineNumbers, matchedNumbers []int
+		for _, ln := range lines {
+			lineNumbers = append(lineNumbers, ln.Number)
+			if ln.Matched {
+				matchedNumbers = append(matchedNumbers, ln.Number)
+			}
+		}
+		if fmt.Sprint(lineNumbers) != fmt.Sprint(tc.lineNumbers) {
+			t.Errorf("line numbers not match (%s): %v vs. %v", tc.query, lineNumbers, tc.lineNumbers)
+		}
+		if fmt.Sprint(matchedNumbers) != fmt.Sprint(tc.matchedNumber) {
+			t.Errorf("matched line numbers not match (%s): %v vs. %v", tc.query, matchedNumbers, tc.matchedNumber)
+		}
+	}
+}
+
+func TestSearchCodeInPackages(t *testing.T) {
+	newPackage := func(path string, numMatchedLines ...int) *code.Package {
+		pkg := &code.Package{Path: path}
+		for i, n := range numMatchedLines {
+			pkg.SourceFiles = append(pkg.SourceFiles, code.SourceFileInfo{
+				BareFilename: fmt.Sprintf("f%d.go", i),
+				Content:      []byte(strings.Repeat("foo\nbar\n", n)),
+			})
+		}
+		return pkg
+	}
+	re := regexp.MustCompile("(?m)foo")
+
+	var testCases = []struct {
+		pkgs       []*code.Package
+		numMatches int
+		truncated  bool
+	}{
+		// Exactly maxCodeSearchMatches matches.
+		{[]*code.Package{newPackage("a", 600, 0), newPackage("b", 400)}, maxCodeSearchMatches, false},
+		{[]*code.Package{newPackage("a", maxCodeSearchMatches)}, maxCodeSearchMatches, false},
+		// One more match in another file.
+		{[]*code.Package{newPackage("a", 600, 400), newPackage("b", 0, 1)}, maxCodeSearchMatches, true},
+		// One more match in the same file.
+		{[]*code.Package{newPackage("a", maxCodeSearchMatches+1)}, maxCodeSearchMatches, true},
+		{[]*code.Package{newPackage("a", 3)}, 3, false},
+	}
+	for i, tc := range testCases {
+		result := searchCodeInPackages(re, tc.pkgs, maxCodeSearchMatches)
+		if result.NumMatches != tc.numMatches || result.Truncated != tc.truncated {
+			t.Errorf("%d: code search result not match: %d, %v vs. %d, %v", i, result.NumMatches, result.Truncated, tc.numMatches, tc.truncated)
+		}
+	}
+}
+
+func TestSignatureQueryMatching(t *testing.T) {
+	var (
+		tInt    = types.Typ[types.Int]
+		tString = types.Typ[types.String]
+		tBytes  = types.NewSlice(types.Typ[types.Byte])
+		tError  = types.Universe.Lookup("error").Type()
+		tAny    = types.NewInterfaceType(nil, nil)
+	)
+	newSig := func(params, results []types.Type) *types.Signature {
+		vars := func(ts []types.Type) *types.Tuple {
+			vs := make([]*types.Var, len(ts))
+			for i, t := range ts {
+				vs[i] = types.NewParam(token.NoPos, nil, "", t)
+			}
+			return types.NewTuple(vs...)
+		}
+		return types.NewSignatureType(nil, nil, nil, vars(params), vars(results), false)
+	}
+
+	type testCase struct {
+		query   string
+		sig     *types.Signature
+		matched bool
+	}
+	var testCases = []testCase{
+		{"func([]byte) (int, error)", newSig([]types.Type{tBytes}, []types.Type{tInt, tError}), true},
+		{"func([]byte) (error, int)", newSig([]types.Type{tBytes}, []types.Type{tInt, tError}), true},
+		{"returns error", newSig([]types.Type{tBytes}, []types.Type{tInt, tError}), true},
+		{"returns string", newSig([]types.Type{tBytes}, []types.Type{tInt, tError}), false},
+		{"takes string, _", newSig([]types.Type{tInt, tString}, nil), true},
+		{"takes string, _", newSig([]types.Type{tString}, nil), false},
+		{"takes string", newSig([]types.Type{tAny}, nil), true},
+		{"int", newSig(nil, []types.Type{tInt}), true},
+		{"func(map[string]int)", newSig([]types.Type{types.NewMap(tString, tInt)}, nil), true},
+	}
+
+	var ds docServer
+	ds.analyzer = &code.CodeAnalyzer{}
+	for _, tc := range testCases {
+		sq, err := ds.parseSignatureQuery(tc.query)
+		if err != nil {
+			t.Errorf("parse signature query (%s) error: %s", tc.query, err)
+			continue
+		}
+		if matched := sq.matchScore(tc.sig) > 0; matched != tc.matched {
+			t.Errorf("signature matching not match (%s, %s): %v vs. %v", tc.query, tc.sig, matched, tc.matched)
+		}
+	}
+
+	exact, _ := ds.parseSignatureQuery("func(string) int")
+	if a, b := exact.matchScore(newSig([]types.Type{tString}, []types.Type{tInt})), exact.matchScore(newSig([]types.Type{tAny}, []types.Type{tInt})); a <= b {
+		t.Errorf("identical signature should rank higher: %d vs. %d", a, b)
+	}
+
+	if _, err := ds.parseSignatureQuery("func(foo.Bar)"); err == nil {
+		t.Errorf("unknown type should be reported")
+	}
+}
+
+func TestParseDataAPIPath(t *testing.T) {
+	var testCases = []struct {
+		path    string
+		resType pageResType
+		pkgPath string
+		id      string
+		ok      bool
+	}{
+		{"pkg/net/http", ResTypePackage, "net/http", "", true},
+		{"dep/gopkg.in/yaml.v3", ResTypeDependency, "gopkg.in/yaml.v3", "", true},
+		{"imp/net/http.Handler", ResTypeImplementation, "net/http", "Handler", true},
+		{"imp/gopkg.in/yaml.v3.Node", ResTypeImplementation, "gopkg.in/yaml.v3", "Node", true},
+		{"use/gopkg.in/yaml.v3..Node.Kind", ResTypeReference, "gopkg.in/yaml.v3", "Node.Kind", true},
+		{"use/fmt..", "", "", "", false},
+		{"imp/Handler", "", "", "", false},
+		{"src/fmt/print.go", "", "", "", false},
+		{"pkg", "", "", "", false},
+	}
+	for _, tc := range testCases {
+		resType, pkgPath, id, ok := parseDataAPIPath(tc.path)
+		if ok != tc.ok || ok && (resType != tc.resType || pkgPath != tc.pkgPath || id != tc.id) {
+			t.Errorf("data API path parsing result not match (%s): %s %s %s %v", tc.path, resType, pkgPath, id, ok)
+		}
+	}
+}
+
+func TestSplitDocQuery(t *testing.T) {
+	var testCases = []struct {
+		query   string
+		pkgPath string
+		idents  []string
+	}{
+		{"fmt", "fmt", nil},
+		{"fmt.Println", "fmt", []string{"Println"}},
+		{"net/http.Client.Do", "net/http", []string{"Client", "Do"}},
+		{"gopkg.in/yaml.v3..Node.Kind", "gopkg.in/yaml.v3", []string{"Node", "Kind"}},
+		{"gopkg.in/yaml.v3..", "gopkg.in/yaml.v3", nil},
+		{".", ".", nil},
+		{"./foo.Bar", "./foo", []string{"Bar"}},
+		{"../foo", "../foo", nil},
+		{".Bar", "", nil},
+	}
+	for _, tc := range testCases {
+		pkgPath, idents := splitDocQuery(tc.query)
+		if pkgPath != tc.pkgPath || strings.Join(idents, ".") != strings.Join(tc.idents, ".") {
+			t.Errorf("doc query splitting result not match (%s): %s %v vs. %s %v", tc.query, pkgPath, idents, tc.pkgPath, tc.idents)
+		}
+	}
+}
+
+func TestQueryTypeRefText(t *testing.T) {
+	var testCases = []struct {
+		ref  APIData_TypeRef
+		text string
+	}{
+		{APIData_TypeRef{Package: "bufio", Name: "Writer", IsPointer: true}, "*bufio.Writer"},
+		{APIData_TypeRef{Package: "os", Name: "File"}, "os.File"},
+		{APIData_TypeRef{Name: "func([]byte) (int, error)"}, "func([]byte) (int, error)"},
+	}
+	for _, tc := range testCases {
+		if text := queryTypeRefText(tc.ref); text != tc.text {
+			t.Errorf("query type ref text not match: %s vs. %s", text, tc.text)
+		}
+	}
+}
+
+func TestMarkdownHeadingAnchor(t *testing.T) {
+	var testCases = []struct {
+		heading string
+		anchor  string
+	}{
+		{"type Client", "type-client"},
+		{"func NewRequest_WithContext", "func-newrequest_withcontext"},
+		{"As Inputs Of", "as-inputs-of"},
+		{"Example (Client.Do)", "example-clientdo"},
+		{"const Δx", "const-δx"},
+	}
+	for _, tc := range testCases {
+		if anchor := markdownHeadingAnchor(tc.heading); anchor != tc.anchor {
+			t.Errorf("markdown heading anchor not match: %s vs. %s", anchor, tc.anchor)
+		}
+	}
+}
+
+func TestMarkdownPackageFile(t *testing.T) {
+	var testcases = []struct {
+		from, to string
+		file     string
+	}{
+		{"net/http", "net/http/httptest", "http/httptest.md"},
+		{"net/http/httptest", "net/http", "../http.md"},
+		{"net", "net/http", "net/http.md"},
+		{"net/http", "net/url", "url.md"},
+		{"net/http", "io", "../io.md"},
+		{"io", "io", ""},
+	}
+	for _, tc := range testcases {
+		from, to := &code.Package{Path: tc.from}, &code.Package{Path: tc.to}
+		if tc.from == tc.to {
+			to = from
+		}
+		mp := &markdownPrinter{docPrinter{pkg: from}}
+		if file := mp.packageFile(to); file != tc.file {
+			t.Errorf("markdown file of %s in %s not match: %s vs. %s", tc.to, tc.from, file, tc.file)
+		}
+	}
+}
+
+func TestMarkdownCodeFence(t *testing.T) {
+	var testCases = []struct {
+		code  string
+		fence string
+	}{
+		{"func F()", "```"},
+		{"var s = `abc`", "```"},
+		{"// ```go", "````"},
+		{"const s = `````", "``````"},
+	}
+	for _, tc := range testCases {
+		if fence := markdownCodeFence(tc.code); fence != tc.fence {
+			t.Errorf("markdown code fence not match: %s vs. %s", fence, tc.fence)
+		}
+	}
+}
+
+func TestBookToc(t *testing.T) {
+	a := &code.Package{Path: "a"}
+	ab := &code.Package{Path: "a/b"}
+	abc := &code.Package{Path: "a/b/c"}
+	ad := &code.Package{Path: "a/d"}
+	bw := &bookWriter{
+		toc: []bookTocItem{{a, 0}, {ab, 1}, {abc, 2}, {ad, 1}},
+		pkgIDs: map[*code.Package]string{
+			a:   "pkg-0",
+			abc: "pkg-1",
+			ad:  "pkg-2",
+		},
+	}
+	var buf bytes.Buffer
+	bw.writeToc(&buf)
+	expected := `<ul>
+<li><a href="#pkg-0">a</a><ul>
+<li>a/b<ul>
+<li><a href="#pkg-1">a/b/c</a></li>
+</ul>
+</li>
+<li><a href="#pkg-2">a/d</a></li>
+</ul>
+</li>
+</ul>
+`
+	if toc := buf.String(); toc != expected {
+		t.Errorf("book toc not match:\n%s\nvs.\n%s", toc, expected)
+	}
+}
+
+func TestDocsetIdentifier(t *testing.T) {
+	var testCases = []struct {
+		name string
+		id   string
+	}{
+		{"GoPackages", "gopackages"},
+		{"My Lib v2", "mylibv2"},
+		{"Δ-lib", "lib"},
+		{"中文", "golds"},
+	}
+	for _, tc := range testCases {
+		if id := docsetIdentifier(tc.name); id != tc.id {
+			t.Errorf("docset identifier not match: %s vs. %s", id, tc.id)
+		}
+	}
+}
+
+func TestRemoveStalePages(t *testing.T) {
+	dir := t.TempDir()
+	for _, page := range []string{"index.html", "pkg/a/b.html", "pkg/c.html"} {
+		path := filepath.Join(dir, filepath.FromSlash(page))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(page), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldManifest := &docsManifest{Pages: map[string]string{"index.html": "1", "pkg/a/b.html": "2", "pkg/c.html": "3", "pkg/d.html": "4"}}
+	newManifest := &docsManifest{Pages: map[string]string{"index.html": "1", "pkg/c.html": "5"}}
+	if n := removeStalePages(dir, oldManifest, newManifest); n != 1 {
+		t.Errorf("number of removed stale pages not match: %d vs. %d", n, 1)
+	}
+	for path, shouldExist := range map[string]bool{"index.html": true, "pkg/c.html": true, "pkg/a/b.html": false, "pkg/a": false, "pkg": true} {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path)))
+		if exists := err == nil; exists != shouldExist {
+			t.Errorf("existence of %s not match: %v vs. %v", path, exists, shouldExist)
+		}
+	}
+
+	if err := newManifest.save(dir); err != nil {
+		t.Fatal(err)
+	}
+	if m := loadDocsManifest(dir); len(m.Pages) != 2 || m.Pages["pkg/c.html"] != "5" {
+		t.Errorf("loaded docs manifest not match: %v", m.Pages)
+	}
+}
+
+func TestConcurrentPageLoading(t *testing.T) {
+	oldPageHrefList := pageHrefList
+	defer func() { pageHrefList = oldPageHrefList }()
+	pageHrefList = list.New()
+
+	// Loading page N registers pages 2N+1 and 2N+2.
+	const numPages = 1000
+	var loadedCounts [numPages]int32
+	registerPageHref(genPageInfo{FilePath: "0"})
+
+	var wg sync.WaitGroup
+	for range [8]struct{}{} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for info := nextPageToLoad(); info != nil; info = nextPageToLoad() {
+				n, _ := strconv.Atoi(info.FilePath)
+				atomic.AddInt32(&loadedCounts[n], 1)
+				for _, k := range []int{2*n + 1, 2*n + 2} {
+					if k < numPages {
+						registerPageHref(genPageInfo{FilePath: strconv.Itoa(k)})
+					}
+				}
+				pageLoaded()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for n, count := range loadedCounts {
+		if count != 1 {
+			t.Errorf("page %d is loaded %d times (not match 1)", n, count)
+		}
+	}
+}
+
+func TestPackagePatternMatcher(t *testing.T) {
+	var testcases = []struct {
+		pattern string
+		pkgPath string
+		matched bool
+	}{
+		{"net/http", "net/http", true},
+		{"net/http", "net/http/httptest", false},
+		{"net/...", "net", true},
+		{"net/...", "net/http/httptest", true},
+		{"net/...", "network", false},
+		{"net...", "network", true},
+		{"go101.org/...", "go101.org/golds/code", true},
+		{"go101.org/...", "go101xorg/golds", false},
+		{".../internal/...", "go101.org/golds/internal/util", true},
+		{".../internal/...", "go101.org/golds/code", false},
+	}
+
+	for _, tc := range testcases {
+		if matched := packagePatternMatcher(tc.pattern)(tc.pkgPath); matched != tc.matched {
+			t.Errorf("packagePatternMatcher(%q)(%q) not match: %v vs. %v", tc.pattern, tc.pkgPath, matched, tc.matched)
+		}
+	}
+}
+
+func TestExternalDocsHref(t *testing.T) {
+	oldExternalDocsURL := externalDocsURL
+	defer func() { externalDocsURL = oldExternalDocsURL }()
+	externalDocsURL = "https://pkg.go.dev/"
+
+	var testcases = []struct {
+		pkgPath   string
+		fragments []string
+		href      string
+	}{
+		{"net/http", nil, "https://pkg.go.dev/net/http"},
+		{"net/http", []string{"name-", "Client"}, "https://pkg.go.dev/net/http#Client"},
+		{"builtin", []string{"name-error"}, "https://pkg.go.dev/builtin#error"},
+		{"os", []string{"line-", "123"}, "https://pkg.go.dev/os"},
+		{"vendor/golang.org/x/net/route", nil, "https://pkg.go.dev/golang.org/x/net/route"},
+	}
+
+	for _, tc := range testcases {
+		if href := externalDocsHref(tc.pkgPath, tc.fragments); href != tc.href {
+			t.Errorf("externalDocsHref(%q, %v) not match: %s vs. %s", tc.pkgPath, tc.fragments, href, tc.href)
+		}
+	}
+}
+
+func TestSortDocsVersions(t *testing.T) {
+	var versions = []string{"v1.2.0", "main", "v1.10.0", "v1.2.0", "abc1234", "v1.2.0-rc.1", "v0.9.9"}
+	var expected = []string{"v1.10.0", "v1.2.0", "v1.2.0-rc.1", "v0.9.9", "main", "abc1234"}
+	if sorted := sortDocsVersions(versions); strings.Join(sorted, " ") != strings.Join(expected, " ") {
+		t.Errorf("sortDocsVersions not match: %v vs. %v", sorted, expected)
+	}
+}
+
+func TestUpdateDocsVersions(t *testing.T) {
+	dir := t.TempDir()
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		if err := os.MkdirAll(filepath.Join(dir, v), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, v, "index.html"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// v0.9.0 is listed but its docs don't exist.
+	if err := ioutil.WriteFile(filepath.Join(dir, docsVersionsFilename), []byte(`["v1.0.0", "v0.9.0"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	updatedVersions, err := updateDocsVersions(dir, "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var versions []string
+	if data, err := ioutil.ReadFile(filepath.Join(dir, docsVersionsFilename)); err != nil {
+		t.Fatal(err)
+	} else if err := json.Unmarshal(data, &versions); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"v1.1.0", "v1.0.0"}; strings.Join(versions, " ") != strings.Join(expected, " ") {
+		t.Errorf("docs versions not match: %v vs. %v", versions, expected)
+	} else if strings.Join(updatedVersions, " ") != strings.Join(expected, " ") {
+		t.Errorf("updated docs versions not match: %v vs. %v", updatedVersions, expected)
+	}
+
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "index.html")); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Contains(data, []byte(`url=v1.1.0/index.html`)) {
+		t.Errorf("module docs index page doesn't redirect to the latest version:\n%s", data)
+	}
+}
+
+func TestWriteStaticHostingFiles(t *testing.T) {
+	oldDocsBaseURL := docsBaseURL
+	defer func() { docsBaseURL = oldDocsBaseURL }()
+	docsBaseURL = normalizeDocsBaseURL("https://example.com/docs")
+
+	if url := docsPageURL("pkg/a&b/Type^1a2b3.html"); url != "https://example.com/docs/pkg/a&b/Type%5E1a2b3.html" {
+		t.Errorf("docsPageURL not match: %s", url)
+	}
+
+	dir := t.TempDir()
+	pages := []string{"pkg/net/http.html", "index.html", "pkg/a&b.html"}
+	notFoundPage := Content{[]byte("<html>"), []byte("</html>")}
+	if err := writeStaticHostingFiles(&docsOutput{dir: dir}, "", pages, notFoundPage, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var testcases = []struct {
+		file     string
+		contains string
+	}{
+		{"sitemap.xml", "<url><loc>https://example.com/docs/index.html</loc></url>\n<url><loc>https://example.com/docs/pkg/a&amp;b.html</loc></url>"},
+		{"robots.txt", "Sitemap: https://example.com/docs/sitemap.xml"},
+		{"404.html", "<html></html>"},
+		{".nojekyll", ""},
+	}
+	for _, tc := range testcases {
+		data, err := ioutil.ReadFile(filepath.Join(dir, tc.file))
+		if err != nil {
+			t.Errorf("read %s error: %s", tc.file, err)
+			continue
+		}
+		if !strings.Contains(string(data), tc.contains) {
+			t.Errorf("content of %s not match:\n%s", tc.file, data)
+		}
+	}
+}
+
+func TestWriteVersionedStaticHostingFiles(t *testing.T) {
+	oldDocsBaseURL := docsBaseURL
+	defer func() { docsBaseURL = oldDocsBaseURL }()
+
+	dir := t.TempDir()
+	output := &docsOutput{dir: dir}
+	notFoundPage := Content{[]byte("<html></html>")}
+	// v1.0.0 is generated without -base-url.
+	if _, err := output.writeFile("example.com/m/v1.0.0/index.html", nil); err != nil {
+		t.Fatal(err)
+	}
+	// v1.1.0 has a sitemap index.
+	docsBaseURL = "https://example.com/docs/example.com/m/v1.1.0/"
+	if _, err := output.writeFile("example.com/m/v1.1.0/sitemap.xml", Content{[]byte(xml.Header + `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<sitemap><loc>https://example.com/docs/example.com/m/v1.1.0/sitemap-1.xml</loc></sitemap>
+<sitemap><loc>https://example.com/docs/example.com/m/v1.1.0/sitemap-2.xml</loc></sitemap>
+</sitemapindex>
+`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	docsBaseURL = "https://example.com/docs/example.com/m/v1.2.0/"
+	versions := []string{"v1.2.0", "v1.1.0", "v1.0.0"}
+	if err := writeStaticHostingFiles(output, "example.com/m/v1.2.0", []string{"index.html"}, notFoundPage, versions); err != nil {
+		t.Fatal(err)
+	}
+
+	var testcases = []struct {
+		file     string
+		contains string
+	}{
+		{"example.com/m/v1.2.0/sitemap.xml", "<url><loc>https://example.com/docs/example.com/m/v1.2.0/index.html</loc></url>"},
+		{"example.com/m/sitemap.xml", `<sitemap><loc>https://example.com/docs/example.com/m/v1.2.0/sitemap.xml</loc></sitemap>
+<sitemap><loc>https://example.com/docs/example.com/m/v1.1.0/sitemap-1.xml</loc></sitemap>
+<sitemap><loc>https://example.com/docs/example.com/m/v1.1.0/sitemap-2.xml</loc></sitemap>
+</sitemapindex>`},
+		{"robots.txt", "Sitemap: https://example.com/docs/example.com/m/sitemap.xml"},
+	}
+	for _, tc := range testcases {
+		data, err := ioutil.ReadFile(filepath.Join(dir, tc.file))
+		if err != nil {
+			t.Errorf("read %s error: %s", tc.file, err)
+			continue
+		}
+		if !strings.Contains(string(data), tc.contains) {
+			t.Errorf("content of %s not match:\n%s", tc.file, data)
+		}
+	}
+}
+
+func TestCheckDocsLinks(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":          {Data: []byte(`<a href="pkg/io.html#name-Writer">Writer</a> <a href="pkg/os.html">os</a> <a href="https://pkg.go.dev/">pkg.go.dev</a>`)},
+		"pkg/io.html":         {Data: []byte(`<link href="../css/default.css" rel="stylesheet"><div class="anchor" id="name-Writer"><a href="#name-Writer">Writer</a> <a href="#name-Reader">Reader</a></div> <a href="../src/io/io.go.html#line-99">io.go</a>`)},
+		"src/io/io.go.html":   {Data: []byte(`<a href="../../pkg/io.html#name-Writer">Writer</a> <a href="../../../outside.html">outside</a>`)},
+		"use/io..Writer.html": {Data: []byte(`<a href="../pkg/io.html#name-Closer">Closer</a>`)},
+		"404.html":            {Data: []byte(`<base href="https://example.com/"><a href="missing.html">missing</a>`)},
+		"css/default.css":     {Data: []byte(``)},
+	}
+
+	broken, err := checkDocsLinks(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []brokenLink{
+		{"index.html", "pkg/os.html"},
+		{"pkg/io.html", "#name-Reader"},
+		{"src/io/io.go.html", "../../../outside.html"},
+		{"use/io..Writer.html", "../pkg/io.html#name-Closer"},
+	}
+	if len(broken) != len(expected) {
+		t.Fatalf("broken links not match: %v", broken)
+	}
+	for i, bl := range broken {
+		if bl != expected[i] {
+			t.Errorf("broken link not match: %v vs. %v", bl, expected[i])
+		}
+	}
+
+	var testcases = []struct {
+		page    string
+		builder string
+	}{
+		{"index.html", "overview and other"},
+		{"pkg/io.html", "package details"},
+		{"src/io/io.go.html", "source"},
+		{"use/io..Writer.html", "references"},
+		{"imp/io.Writer.html", "implementations"},
+	}
+	for _, tc := range testcases {
+		if builder := pageBuilderName(tc.page); builder != tc.builder {
+			t.Errorf("page builder of %s not match: %s vs. %s", tc.page, builder, tc.builder)
+		}
+	}
+}
+
+func TestPageBranding(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	defer loadPageBranding(PageOutputOptions{})
+	loadPageBranding(PageOutputOptions{
+		HeaderTemplate:  writeFile("header.html", `<a href="{{.OverviewURL}}">{{.ProductName}}</a> <img src="{{.LogoURL}}">`),
+		FooterTemplate:  writeFile("footer.html", `Golds {{.GoldsVersion}}, {{.PageType}}: {{.PackagePath}}`),
+		SidebarTemplate: writeFile("sidebar.html", `<a href="{{.OverviewURL}}">{{.Title}}</a>`),
+		LogoFile:        writeFile("logo.svg", `<svg></svg>`),
+		ProductName:     "Acme <Docs>",
+	})
+
+	page := NewHtmlPage(goldsVersion, "Package io", theme.Light{}, &translation.English{}, createPagePathInfo1(ResTypePackage, "io"))
+	w := &docGenResponseWriter{}
+	w.reset()
+	page.Done(w)
+	var buf bytes.Buffer
+	for _, bs := range w.content {
+		buf.Write(bs)
+	}
+	html := buf.String()
+	var testcases = []string{
+		`<title>Package io - Acme &lt;Docs&gt;</title>`,
+		`<div id="custom-header"><a href="../index.html">Acme &lt;Docs&gt;</a> <img src="../svg/custom-logo.svg"></div>`,
+		`<div id="custom-sidebar"><a href="../index.html">Package io</a></div>`,
+		`<div id="custom-footer">Golds ` + goldsVersion + `, pkg: io</div>`,
+	}
+	for _, tc := range testcases {
+		if !strings.Contains(html, tc) {
+			t.Errorf("branded page doesn't contain %s:\n%s", tc, html)
+		}
+	}
+
+	var pathInfos = []struct {
+		pathInfo pagePathInfo
+		pkgPath  string
+	}{
+		{createPagePathInfo(ResTypeNone, ""), ""},
+		{createPagePathInfo1(ResTypeDependency, "net/http"), "net/http"},
+		{createPagePathInfo2b(ResTypeSource, "example.com/a.b", "/", "c.go"), "example.com/a.b"},
+		{createPagePathInfo2(ResTypeImplementation, "example.com/a.b", ".", "Reader"), "example.com/a.b"},
+		{createPagePathInfo3(ResTypeReference, "example.com/a.b", "..", "Reader", "Read"), "example.com/a.b"},
+	}
+	for _, pi := range pathInfos {
+		if pkgPath := pagePackagePath(pi.pathInfo); pkgPath != pi.pkgPath {
+			t.Errorf("package path of %v not match: %s vs. %s", pi.pathInfo, pkgPath, pi.pkgPath)
+		}
+	}
+}
+
+func TestPageTemplates(t *testing.T) {
+	render := func(name string, pathInfo pagePathInfo, data interface{}, sections map[string]func()) string {
+		ds := &docServer{}
+		page := NewHtmlPage(goldsVersion, "", nil, &translation.English{}, createPagePathInfo(ResTypeAPI, ""))
+		page.PathInfo = pathInfo
+		if err := ds.writePageTemplate(page, name, data, sections); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		for _, bs := range page.content {
+			buf.Write(bs)
+		}
+		return buf.String()
+	}
+
+	defer loadPageTemplates("")
+	loadPageTemplates("")
+
+	depInfo := &PackageDependencyInfo{Name: "http", ImportPath: "net/http"}
+	html := render("package-dependencies.html", createPagePathInfo1(ResTypeDependency, "net/http"), depInfo, nil)
+	expected := `
+<pre><code><span style="font-size:xx-large;">package <b>http</b></span>
+
+<span class="title">Import Path</span>
+	<a href="../../pkg/net/http.html">net/http</a>
+`
+	if html != expected {
+		t.Errorf("package dependencies page not match:\n%s", html)
+	}
+
+	details := &PackageDetails{Name: "io", ImportPath: "io", NumDeps: 2, NumDepedBys: 3}
+	html = render("package-details.html", createPagePathInfo1(ResTypePackage, "io"), details, nil)
+	if !strings.HasPrefix(html, "\n"+`<pre id="package-details"><code><span style="font-size:xx-large;">package <b>io</b></span>`) ||
+		!strings.Contains(html, `<a href="../index.html#pkg-io">io</a>`) ||
+		!strings.HasSuffix(html, `and imported by <a href="../dep/io.html#imported-by">3 packages</a>`+"\n</code></pre>") {
+		t.Errorf("package details page header not match:\n%s", html)
+	}
+
+	details.Functions = make([]ResourceWithPosition, 2)
+	details.NumExportedFunctions = 1
+	html = render("package-details.html", createPagePathInfo1(ResTypePackage, "io"), details, map[string]func(){
+		"functions": func() {},
+	})
+	if expected := `<div id="exported-functions"><span class="title">Package-Level Functions<span class="title-stat"><i> (total 2, in which 1 is exported)</i></span></span>` + "\n\n</div></code></pre>"; !strings.HasSuffix(html, expected) {
+		t.Errorf("package details page listings not match:\n%s", html)
+	}
+
+	source := &SourceFileAnalyzeResult{PkgPath: "io", BareFilename: "io.go", OriginalPath: "/go/src/io/io.go"}
+	html = render("source-code.html", createPagePathInfo2b(ResTypeSource, "io", "/", "io.go"), source, map[string]func(){
+		"selectors": func() {},
+		"lines":     func() {},
+	})
+	expected = `
+<pre id="header"><code><span class="title">Source File</span>
+	io.go
+
+<span class="title">Belonging Package</span>
+	<a href="../../pkg/io.html">io</a>
+</code></pre>
+
+<pre class="line-numbers">
+</pre>`
+	if html != expected {
+		t.Errorf("source code page not match:\n%s", html)
+	}
+
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "not-found.html"), []byte(`{{template "message" .}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "message.html"), []byte(`{{define "message"}}<p>{{.T.Text_PageNotFound}}: <a href="{{.OverviewHref}}">&lt;home&gt;</a></p>{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loadPageTemplates(dir)
+	html = render("not-found.html", notFoundPagePathInfo, nil, nil)
+	if expected := `<p>Page not found: <a href="index.html">&lt;home&gt;</a></p>`; html != expected {
+		t.Errorf("overridden not-found page not match:\n%s", html)
+	}
+
+	// Pages failing to be built from templates are discarded.
+	if err := ioutil.WriteFile(filepath.Join(dir, "not-found.html"), []byte(`<p>{{.NoSuchField}}</p>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loadPageTemplates(dir)
+	page := NewHtmlPage(goldsVersion, "", nil, &translation.English{}, createPagePathInfo(ResTypeAPI, ""))
+	page.PathInfo = notFoundPagePathInfo
+	if err := (&docServer{}).writePageTemplate(page, "not-found.html", nil, nil); err == nil {
+		t.Errorf("executing a bad template should fail")
+	} else if page.content != nil {
+		t.Errorf("the page failing to be built is not discarded")
+	}
+}
+
+func TestLSIFElements(t *testing.T) {
+	var buf bytes.Buffer
+	lg := &lsifGenerator{encoder: json.NewEncoder(&buf)}
+	rs := lg.vertex("resultSet", nil)
+	lg.edge("next", 5, []int{rs}, nil)
+	lg.edge("item", 6, []int{7}, map[string]interface{}{"document": 8})
+
+	var testCases = []string{
+		`{"id":1,"label":"resultSet","type":"vertex"}`,
+		`{"id":2,"inV":1,"label":"next","outV":5,"type":"edge"}`,
+		`{"document":8,"id":3,"inVs":[7],"label":"item","outV":6,"type":"edge"}`,
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(testCases) {
+		t.Fatalf("LSIF element count not match: %d vs. %d", len(lines), len(testCases))
+	}
+	for i, tc := range testCases {
+		if lines[i] != tc {
+			t.Errorf("LSIF element not match: %s vs. %s", lines[i], tc)
+		}
+	}
+}
+
+func TestLSIFIdentRange(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "foo.go")
+	content := "package foo\n\nvar _ = \"é😀\"; var 名字 int\n"
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ident := file.Decls[1].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Names[0]
+
+	var buf bytes.Buffer
+	lg := &lsifGenerator{encoder: json.NewEncoder(&buf), documents: make(map[string]*lsifDocument)}
+	lg.identRange(fset, ident)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("LSIF element count not match: %d vs. 2", len(lines))
+	}
+	// "var _ = \"é😀\"; var " is 22 bytes but 19 UTF-16 code units.
+	if expected := `{"end":{"line":2,"character":21},"id":2,"label":"range","start":{"line":2,"character":19},"type":"vertex"}`; lines[1] != expected {
+		t.Errorf("LSIF range not match: %s vs. %s", lines[1], expected)
+	}
+}
+
+func TestWriteTags(t *testing.T) {
+	content := []byte("package foo\n\ntype T struct {\n\tX int\n}\n")
+	entries := []tagEntry{
+		{Name: "X", File: "foo.go", Line: 4, Offset: bytes.Index(content, []byte("X")), Kind: "member", Fields: []string{"struct:T"}},
+		{Name: "T", File: "foo.go", Line: 3, Offset: bytes.Index(content, []byte("T")), Kind: "struct"},
+	}
+	sortTagEntries(entries)
+
+	var buf bytes.Buffer
+	if err := writeCtags(&buf, entries, "v0.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if n := len(lines); n < 2 || lines[n-2] != "T\tfoo.go\t3;\"\tkind:struct" || lines[n-1] != "X\tfoo.go\t4;\"\tkind:member\tstruct:T" {
+		t.Errorf("ctags lines not match: %q", lines)
+	}
+
+	var line bytes.Buffer
+	writeEtagsLine(&line, content, &entries[1])
+	if expected := "\tX\x7fX\x014,29\n"; line.String() != expected {
+		t.Errorf("etags line not match: %q vs. %q", line.String(), expected)
+	}
+}
+
 func TestDocsForStandardPackages(t *testing.T) {
 	// ...
 	data, err := ioutil.ReadFile(filepath.Join("..", "testing", "data", "testdata.json.tar.gz"))
@@ -246,5 +1173,287 @@ func TestGenerateDocsOfStandardPackages(t *testing.T) {
 	GenDocs(opts, []string{"std"}, "", true, nil, false, nil)
 	opts = PageOutputOptions{GoldsVersion: "v0.0.0", PreferredLang: "en-US", SourceReadingStyle: SourceReadingStyle_external}
 	GenDocs(opts, []string{"std"}, "", true, nil, false, nil)
+	opts = PageOutputOptions{GoldsVersion: "v0.0.0", PreferredLang: "en-US", SourceReadingStyle: SourceReadingStyle_rich, GeneratedPackages: "net/..."}
+	GenDocs(opts, []string{"std"}, "", true, nil, false, nil)
+	opts.ExternalDocsURL = "https://pkg.go.dev"
+	GenDocs(opts, []string{"std"}, "", true, nil, false, nil)
+	opts = PageOutputOptions{GoldsVersion: "v0.0.0", PreferredLang: "en-US", SourceReadingStyle: SourceReadingStyle_rich, DocsVersion: "v1.0.0"}
+	GenDocs(opts, []string{"std"}, "", true, nil, false, nil)
+	opts.BaseURL = "https://example.com/docs/"
+	GenDocs(opts, []string{"std"}, "", true, nil, false, nil)
 	GenTestData([]string{"std"}, "", true, nil)
+	opts = PageOutputOptions{GoldsVersion: "v0.0.0", PreferredLang: "en-US"}
+	GenJSON(opts, []string{"std"}, "", true, nil)
+	GenMarkdown(opts, []string{"std"}, "", true, nil)
+	GenBook(opts, []string{"std"}, "", "", false, true, nil)
+	GenBook(opts, []string{"std"}, "", "", true, true, nil)
+	GenLSIF(opts, []string{"std"}, "", true, nil)
+	GenTags(opts, []string{"std"}, "", true, nil)
+	GenDocset(opts, []string{"std"}, "", "Go", true, nil, false)
+}
+
+func TestUserThemes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("night/theme.json", `{"dark": true, "tokens": {"keyword": "#f77", "chosen-ident": "background: #333; color: #ff0"}}`)
+	writeFile("paper/theme.css", `body {background: #ffe;}`)
+	writeFile("plain/theme.json", `{"name": "plain-text", "tokens": {"comment": "gray"}}`)
+	writeFile("bad-token/theme.json", `{"tokens": {"identifier": "red"}}`)
+	writeFile("bad-name/theme.json", `{"name": "a b"}`)
+	writeFile("bad-template/theme.css", `body {font-family: {{ .Font }};}`)
+	writeFile("bad-syntax/theme.css", `body {content: "{{";}`)
+	writeFile("empty/readme.txt", `no theme files`)
+
+	themes, errs := theme.LoadUserThemes(dir)
+	if len(errs) != 5 {
+		t.Errorf("number of theme errors not match: %d vs. 5 (%v)", len(errs), errs)
+	}
+	var testcases = []struct {
+		name     string
+		dark     bool
+		contains []string
+	}{
+		{"night", true, []string{"code .keyword {color: #f77;}\n", "code.chosen-ident {background: #333; color: #ff0;}\n"}},
+		{"paper", false, []string{"body {background: #ffe;}\n"}},
+		{"plain-text", false, []string{"code .comment {color: gray;}\n"}},
+	}
+	if len(themes) != len(testcases) {
+		t.Fatalf("number of themes not match: %d vs. %d", len(themes), len(testcases))
+	}
+	for i, tc := range testcases {
+		th := themes[i]
+		if th.Name() != tc.name || th.Dark() != tc.dark {
+			t.Errorf("theme %d not match: %s, %v vs. %s, %v", i, th.Name(), th.Dark(), tc.name, tc.dark)
+		}
+		for _, s := range tc.contains {
+			if !strings.Contains(th.CSS(), s) {
+				t.Errorf("CSS of theme %s doesn't contain %q", th.Name(), s)
+			}
+		}
+	}
+
+	defer func() { userThemes = nil }()
+	userThemes = []Theme{themes[0]}
+
+	ds := &docServer{}
+	ds.initSettings("")
+	if ds.currentTheme.Name() != "auto" {
+		t.Errorf("current theme not match: %s vs. auto", ds.currentTheme.Name())
+	}
+	ds.changeSettings("night")
+	if ds.currentTheme.Name() != "night" {
+		t.Errorf("current theme not match: %s vs. night", ds.currentTheme.Name())
+	}
+	if expected := "{background: #333; color: #ff0;}"; ds.css.chosenIdent != expected {
+		t.Errorf("chosen ident style not match: %s vs. %s", ds.css.chosenIdent, expected)
+	}
+
+	page := NewHtmlPage(goldsVersion, "", ds.currentTheme, &translation.English{}, createPagePathInfo1(ResTypePackage, "io"))
+	w := &docGenResponseWriter{}
+	w.reset()
+	page.Done(w)
+	var buf bytes.Buffer
+	for _, bs := range w.content {
+		buf.Write(bs)
+	}
+	html := buf.String()
+	for _, s := range []string{
+		`<meta name="color-scheme" content="dark">`,
+		`<option value="light" data-scheme="light" data-href="../css/light-` + goldsVersion + `.css">light</option>`,
+		`<option value="night" data-scheme="dark" data-href="../css/night-` + goldsVersion + `.css" selected>night</option>`,
+	} {
+		if !strings.Contains(html, s) {
+			t.Errorf("page doesn't contain %s:\n%s", s, html)
+		}
+	}
+}
+
+func TestTranslationCatalogs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("ja.json", `{
+	"name": "日本語",
+	"messages": {
+		"Overview": "概要",
+		"ImportStat": "{{.numImports}} 個のパッケージをインポート",
+		"SearchResultStat": "<b>{{.query}}</b>: {{.numResults}} 件",
+		"TypeStatistics.1": "{{.exportedStructTypeNames}} 個の構造体型",
+		"TypeStatistics.2": "{{.unknown}}"
+	}
+}`)
+	writeFile("misspelled-key.json", `{"messages": {"Overveiw": "x"}}`)
+	writeFile("extra-segment.json", `{"messages": {"TypeStatistics.9": "x"}}`)
+	writeFile("misspelled-arg.json", `{"messages": {"ImportStat": "{{.numImport}}"}}`)
+	writeFile("korean.po", `# Korean translations.
+#, fuzzy
+msgid ""
+msgstr ""
+"Language: ko\n"
+"X-Language-Name: 한국어\n"
+
+msgid "Overview"
+msgstr "개요"
+
+#, fuzzy
+msgid "Imports"
+msgstr "가져오기"
+
+msgid "ImportedBy"
+msgstr ""
+
+msgid "ObjectUses"
+msgstr ""
+"{{if eq .num 1}}한 번 사용{{else}}"
+"{{.num}}번 사용{{end}}"
+`)
+	writeFile("bad.po", `msgid "Overview"
+msgstr[0] "x"
+`)
+	writeFile("readme.txt", `not a catalog`)
+
+	catalogs, errs := translation.LoadCatalogs(dir)
+	if len(errs) != 4 {
+		t.Errorf("number of catalog errors not match: %d vs. 4 (%v)", len(errs), errs)
+	}
+	for _, expected := range []string{"plural forms", "unknown message keys: TypeStatistics.9", "message ImportStat", "unknown message keys: Overveiw"} {
+		found := false
+		for _, err := range errs {
+			found = found || strings.Contains(err.Error(), expected)
+		}
+		if !found {
+			t.Errorf("catalog error %q is not reported: %v", expected, errs)
+		}
+	}
+	if len(catalogs) != 2 {
+		t.Fatalf("number of catalogs not match: %d vs. 2", len(catalogs))
+	}
+	ja, ko := catalogs[0], catalogs[1]
+	if ja.Name() != "日本語" || ja.LangTag() != "ja" {
+		t.Errorf("ja catalog not match: %s, %s", ja.Name(), ja.LangTag())
+	}
+	if ko.Name() != "한국어" || ko.LangTag() != "ko" {
+		t.Errorf("ko catalog not match: %s, %s", ko.Name(), ko.LangTag())
+	}
+
+	var testcases = []struct {
+		text     string
+		expected string
+	}{
+		{ja.Text_Overview(), "概要"},
+		{ja.Text_ImportStat(3, 5, "dep.html"), "3 個のパッケージをインポート"},
+		{ja.Text_SearchResultStat(2, "<a>"), "<b>&lt;a&gt;</b>: 2 件"},
+		{ja.Text_Imports(), "Imports"},
+		{ja.Text_ImportedBy(), "Imported By"},
+		{ko.Text_Overview(), "개요"},
+		{ko.Text_Imports(), "Imports"},        // fuzzy
+		{ko.Text_ImportedBy(), "Imported By"}, // blank
+		{ko.Text_ObjectUses(1), "한 번 사용"},
+		{ko.Text_ObjectUses(7), "7번 사용"},
+	}
+	for i, tc := range testcases {
+		if tc.text != tc.expected {
+			t.Errorf("text %d not match: %s vs. %s", i, tc.text, tc.expected)
+		}
+	}
+
+	// The segment 2 fails to be executed, so the English one is used.
+	segments := ja.Text_TypeStatistics(map[string]interface{}{"exportedStructTypeNames": 9})
+	if len(segments) != 4 || segments[1] != "9 個の構造体型" || !strings.Contains(segments[0], "exported type names") || strings.Contains(segments[2], "no value") {
+		t.Errorf("type statistics segments not match: %q", segments)
+	}
+
+	defer func() { catalogTranslations = nil }()
+	catalogTranslations = []Translation{ja, ko}
+
+	ds := &docServer{}
+	ds.initSettings("ja-JP")
+	if ds.currentTranslation != ja {
+		t.Errorf("translation selected by -lang not match: %s", ds.currentTranslation.Name())
+	}
+	if tr := ds.translationByAcceptLanguage("ko-KR,ko;q=0.9,en;q=0.8"); tr != ko {
+		t.Errorf("translation selected by Accept-Language not match: %v", tr)
+	}
+	if tr := ds.translationByAcceptLanguage("fr"); tr.LangTag() != "en-US" {
+		t.Errorf("fallback translation not match: %s", tr.Name())
+	}
+}
+
+func TestRequestSettings(t *testing.T) {
+	ds := &docServer{}
+	ds.initSettings("")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/search?q=reader&theme=dark&lang=zh", nil)
+	if !ds.changeSettingsByQuery(w, r) {
+		t.Fatal("setting parameters are not handled")
+	}
+	if location := w.Header().Get("Location"); location != "/search?q=reader" {
+		t.Errorf("redirect location not match: %s", location)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("number of cookies not match: %d vs. 2", len(cookies))
+	}
+
+	settingsOf := func(r *http.Request) pageSettings {
+		r = ds.withRequestSettings(r)
+		ds.mutex.Lock()
+		defer ds.mutex.Unlock()
+		ds.useRequestSettings(r)
+		return ds.pageCacheOptions().(pageSettings)
+	}
+
+	var testcases = []struct {
+		cookies        []*http.Cookie
+		acceptLanguage string
+		langSpecified  bool
+		theme          string
+		lang           string
+	}{
+		{nil, "", false, "auto", "en-US"},
+		{nil, "zh-CN,zh;q=0.9", false, "auto", "zh-CN"},
+		{nil, "zh-CN,zh;q=0.9", true, "auto", "en-US"},
+		{cookies, "en", false, "dark", "zh-CN"},
+		{[]*http.Cookie{{Name: themeCookieName, Value: "unknown"}}, "", false, "auto", "en-US"},
+	}
+	for i, tc := range testcases {
+		ds.langSpecified = tc.langSpecified
+		r := httptest.NewRequest("GET", "/pkg:io", nil)
+		for _, c := range tc.cookies {
+			r.AddCookie(c)
+		}
+		if tc.acceptLanguage != "" {
+			r.Header.Set("Accept-Language", tc.acceptLanguage)
+		}
+		settings := settingsOf(r)
+		if settings.theme.Name() != tc.theme || settings.translation.LangTag() != tc.lang {
+			t.Errorf("settings %d not match: %s, %s vs. %s, %s", i, settings.theme.Name(), settings.translation.LangTag(), tc.theme, tc.lang)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/pkg:io", nil)
+	if ds.changeSettingsByQuery(w, r) {
+		t.Error("request without setting parameters is redirected")
+	}
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/pkg:io?theme=", nil)
+	if !ds.changeSettingsByQuery(w, r) {
+		t.Fatal("setting parameters are not handled")
+	}
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("theme cookie is not reset: %v", cookies)
+	}
 }
@@ -0,0 +1,578 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"go/types"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go101.org/golds/code"
+)
+
+// The data APIs expose the analysis results as JSON documents.
+// The APIs are only available in server mode.
+//
+// * /api:pkg/<pkg-path>: package details
+// * /api:dep/<pkg-path>: package dependencies
+// * /api:imp/<pkg-path>.<type-name>: method implementations
+// * /api:use/<pkg-path>..<id>[.<selector>]: identifier references
+// * /api:overview: all packages
+// * /api:statistics: code statistics
+// * /api:modules: all modules
+//
+// The structs used in the JSON documents are decoupled from the
+// ones used in building pages, so that the field names keep stable.
+
+type APIData_Position struct {
+	File   string // bare filename
+	Line   int
+	Column int
+}
+
+type APIData_TypeRef struct {
+	Package   string // blank for unnamed types
+	Name      string
+	IsPointer bool
+}
+
+type APIData_ValueRef struct {
+	Package  string
+	Name     string
+	Receiver string // for methods only
+}
+
+type APIData_Selector struct {
+	Name     string
+	IsField  bool
+	Type     string
+	Depth    int // 0 means not promoted
+	Exported bool
+}
+
+type APIData_Value struct {
+	Name     string
+	Kind     string // "func", "var" or "const"
+	Type     string
+	Exported bool
+	Doc      string
+	Position APIData_Position
+}
+
+type APIData_Type struct {
+	Name       string
+	Kind       string
+	Type       string // the underlying type
+	Exported   bool
+	IsAlias    bool
+	Doc        string
+	Position   APIData_Position
+	Popularity int
+
+	Fields         []APIData_Selector
+	Methods        []APIData_Selector
+	Implements     []APIData_TypeRef
+	ImplementedBys []APIData_TypeRef
+	AsInputsOf     []APIData_ValueRef
+	AsOutputsOf    []APIData_ValueRef
+	Values         []APIData_ValueRef
+}
+
+type APIData_Package struct {
+	ImportPath     string
+	Name           string
+	Module         string
+	IsStandard     bool
+	NumDeps        int
+	NumImportedBys int
+	Files          []string
+
+	TypeNames []APIData_Type
+	Functions []APIData_Value
+	Variables []APIData_Value
+	Constants []APIData_Value
+	Examples  []string
+}
+
+type APIData_Dependencies struct {
+	ImportPath  string
+	Name        string
+	Imports     []string
+	ImportedBys []string
+}
+
+type APIData_Implementation struct {
+	Receiver  APIData_TypeRef
+	Explicit  bool
+	Interface bool
+}
+
+type APIData_MethodImplementations struct {
+	Method          string
+	Implementations []APIData_Implementation
+}
+
+type APIData_Implementations struct {
+	Package     string
+	TypeName    string
+	IsInterface bool
+	Methods     []APIData_MethodImplementations
+}
+
+type APIData_PackageReferences struct {
+	Package      string
+	InCurrentPkg bool
+	Uses         []APIData_Position
+}
+
+type APIData_References struct {
+	Package    string
+	Identifier string
+	NumUses    int
+	References []APIData_PackageReferences
+}
+
+type APIData_PackageForListing struct {
+	ImportPath         string
+	Name               string
+	Module             string
+	OneLineDoc         string
+	LOC                int
+	NumImportedBys     int
+	DepHeight          int
+	DepDepth           int
+	Afferent           int
+	Efferent           int
+	Instability        float64
+	Abstractness       float64
+	Distance           float64
+	InWorkingDirectory bool
+}
+
+type APIData_Overview struct {
+	Packages []APIData_PackageForListing
+}
+
+type APIData_Module struct {
+	Path             string
+	Version          string
+	ReplacedBy       string // blank if not replaced
+	ReplacedVersion  string
+	RepositoryURL    string
+	RepositoryCommit string
+	Packages         []string
+}
+
+type APIData_Statistics struct {
+	Packages                int
+	StdPackages             int
+	AllPackageDeps          int
+	FilesWithoutGenerateds  int
+	FilesWithGenerateds     int
+	Imports                 int
+	CodeLinesWithBlankLines int
+
+	ExportedTypeNames   int
+	ExportedTypeAliases int
+	ExportedVariables   int
+	ExportedConstants   int
+	ExportedFunctions   int
+	ExportedMethods     int
+	ExportedIdentifers  int
+}
+
+// parseDataAPIPath splits the path of a data API,
+// for example, "imp/net/http.Handler" to ("imp", "net/http", "Handler").
+func parseDataAPIPath(resPath string) (resType pageResType, pkgPath, id string, ok bool) {
+	if len(resPath) < 5 || resPath[3] != '/' {
+		return
+	}
+	resType, pkgPath = pageResType(resPath[:3]), resPath[4:]
+	var sep string
+	switch resType {
+	default:
+		return
+	case ResTypePackage, ResTypeDependency:
+		return resType, pkgPath, "", true
+	case ResTypeImplementation:
+		sep = "."
+	case ResTypeReference:
+		sep = ".."
+	}
+	index := strings.LastIndex(pkgPath, sep)
+	if index <= 0 || index+len(sep) == len(pkgPath) {
+		return
+	}
+	return resType, pkgPath[:index], pkgPath[index+len(sep):], true
+}
+
+func (ds *docServer) dataAPI(w http.ResponseWriter, r *http.Request, resPath string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if genDocsMode {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": "data APIs are not supported in docs generation mode"}`)
+		return
+	}
+
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if ds.phase < Phase_Analyzed {
+		w.WriteHeader(http.StatusTooEarly)
+		fmt.Fprint(w, `{"error": "analyzing"}`)
+		return
+	}
+
+	pageKey := pageCacheKey{
+		resType: ResTypeAPI,
+		res:     resPath,
+	}
+	data, ok := ds.cachedPage(pageKey)
+	if !ok {
+		result, err := ds.buildAPIData(resPath)
+		if err == nil {
+			data, err = json.Marshal(result)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error": %q}`, err.Error())
+			return
+		}
+		ds.cachePage(pageKey, data)
+	}
+	w.Write(data)
+}
+
+// ds should be locked before calling this method.
+func (ds *docServer) buildAPIData(resPath string) (interface{}, error) {
+	switch resPath {
+	case "overview":
+		return buildAPIData_Overview(ds.buildOverviewData()), nil
+	case "statistics":
+		return buildAPIData_Statistics(ds.analyzer.Statistics()), nil
+	case "modules":
+		return buildAPIData_Modules(ds.analyzer), nil
+	}
+
+	resType, pkgPath, id, ok := parseDataAPIPath(resPath)
+	if !ok {
+		return nil, fmt.Errorf("invalid data API path: %s", resPath)
+	}
+	switch resType {
+	case ResTypePackage:
+		details := buildPackageDetailsData(ds.analyzer, pkgPath, collectUnexporteds)
+		if details == nil {
+			return nil, fmt.Errorf("package %s is not found", pkgPath)
+		}
+		return buildAPIData_Package(details), nil
+	case ResTypeDependency:
+		info := ds.buildPackageDependenciesData(pkgPath)
+		if info == nil {
+			return nil, fmt.Errorf("package %s is not found", pkgPath)
+		}
+		return buildAPIData_Dependencies(info), nil
+	case ResTypeImplementation:
+		// buildImplementationData panics for such cases.
+		if !collectUnexporteds && pkgPath != "builtin" && !token.IsExported(id) {
+			return nil, fmt.Errorf("%s.%s is not exported", pkgPath, id)
+		}
+		result, err := ds.buildImplementationData(ds.analyzer, pkgPath, id)
+		if err != nil {
+			return nil, err
+		}
+		return buildAPIData_Implementations(result), nil
+	case ResTypeReference:
+		result, err := ds.buildReferencesData(pkgPath, strings.Split(id, ".")...)
+		if err != nil {
+			return nil, err
+		}
+		return buildAPIData_References(result), nil
+	}
+	panic("should not go here (api): " + resPath)
+}
+
+func apiDataPosition(pos token.Position) APIData_Position {
+	return APIData_Position{
+		File:   filepath.Base(pos.Filename),
+		Line:   pos.Line,
+		Column: pos.Column,
+	}
+}
+
+func apiDataTypeString(tt types.Type, pkg *code.Package) string {
+	if tt == nil {
+		return ""
+	}
+	if pkg == nil || pkg.PPkg.Types == nil {
+		return types.TypeString(tt, nil)
+	}
+	return types.TypeString(tt, types.RelativeTo(pkg.PPkg.Types))
+}
+
+func apiDataTypeRefs(tfls []*TypeForListing) []APIData_TypeRef {
+	refs := make([]APIData_TypeRef, 0, len(tfls))
+	for _, t := range tfls {
+		refs = append(refs, apiDataTypeRef(t))
+	}
+	return refs
+}
+
+func apiDataTypeRef(t *TypeForListing) APIData_TypeRef {
+	ref := APIData_TypeRef{IsPointer: t.IsPointer}
+	if tn := t.BaseType.TypeName; tn != nil {
+		ref.Package = tn.Package().Path
+		ref.Name = t.NameWithTypeArgs
+		if ref.Name == "" {
+			ref.Name = tn.Name()
+		}
+	} else {
+		ref.Name = apiDataTypeString(t.BaseType.TT, nil)
+	}
+	return ref
+}
+
+func apiDataValueRefs(vfls []*ValueForListing) []APIData_ValueRef {
+	refs := make([]APIData_ValueRef, 0, len(vfls))
+	for _, v := range vfls {
+		ref := APIData_ValueRef{
+			Package: v.Package().Path,
+			Name:    v.Name(),
+		}
+		if f, ok := v.ValueResource.(code.FunctionResource); ok && f.IsMethod() {
+			if _, tn, isStar := f.ReceiverTypeName(); tn != nil {
+				ref.Receiver = tn.Name()
+				if isStar {
+					ref.Receiver = "*" + ref.Receiver
+				}
+			}
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func apiDataSelectors(sels []*code.Selector, pkg *code.Package) []APIData_Selector {
+	result := make([]APIData_Selector, 0, len(sels))
+	for _, sel := range sels {
+		s := APIData_Selector{
+			Name:     sel.Name(),
+			IsField:  sel.Field != nil,
+			Depth:    int(sel.Depth),
+			Exported: token.IsExported(sel.Name()),
+		}
+		if t := sel.Type(); t != nil {
+			s.Type = apiDataTypeString(t.TT, pkg)
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func apiDataValues(rwps []ResourceWithPosition, kind string, pkg *code.Package) []APIData_Value {
+	values := make([]APIData_Value, 0, len(rwps))
+	for _, rwp := range rwps {
+		v := rwp.Value
+		values = append(values, APIData_Value{
+			Name:     v.Name(),
+			Kind:     kind,
+			Type:     apiDataTypeString(v.TType(), pkg),
+			Exported: v.Exported(),
+			Doc:      v.Documentation(),
+			Position: apiDataPosition(rwp.Position),
+		})
+	}
+	return values
+}
+
+func buildAPIData_Package(details *PackageDetails) *APIData_Package {
+	pkg := details.Package
+	result := &APIData_Package{
+		ImportPath:     details.ImportPath,
+		Name:           details.Name,
+		Module:         pkg.ModulePath(),
+		IsStandard:     details.IsStandard,
+		NumDeps:        int(details.NumDeps),
+		NumImportedBys: int(details.NumDepedBys),
+		Files:          make([]string, 0, len(details.Files)),
+		TypeNames:      make([]APIData_Type, 0, len(details.TypeNames)),
+		Functions:      apiDataValues(details.Functions, "func", pkg),
+		Variables:      apiDataValues(details.Variables, "var", pkg),
+		Constants:      apiDataValues(details.Constants, "const", pkg),
+		Examples:       make([]string, 0, len(details.Examples)),
+	}
+
+	for _, f := range details.Files {
+		result.Files = append(result.Files, f.Filename)
+	}
+
+	for _, rwp := range details.TypeNames {
+		td := rwp.Type
+		tn := td.TypeName
+		t := APIData_Type{
+			Name:       tn.Name(),
+			Kind:       tn.Denoting.Kind().String(),
+			Exported:   tn.Exported(),
+			IsAlias:    tn.IsAlias(),
+			Doc:        tn.Documentation(),
+			Position:   apiDataPosition(rwp.Position),
+			Popularity: td.Popularity,
+
+			Fields:         make([]APIData_Selector, 0, len(td.Fields)),
+			Methods:        apiDataSelectors(td.Methods, pkg),
+			Implements:     apiDataTypeRefs(td.Implements),
+			ImplementedBys: apiDataTypeRefs(td.ImplementedBys),
+			AsInputsOf:     apiDataValueRefs(td.AsInputsOf),
+			AsOutputsOf:    apiDataValueRefs(td.AsOutputsOf),
+			Values:         apiDataValueRefs(td.Values),
+		}
+		if tt := tn.Denoting.TT; tt != nil {
+			t.Type = apiDataTypeString(tt.Underlying(), pkg)
+		}
+		for _, f := range td.Fields {
+			t.Fields = append(t.Fields, apiDataSelectors([]*code.Selector{f.Selector}, pkg)...)
+		}
+		result.TypeNames = append(result.TypeNames, t)
+	}
+
+	for _, ex := range details.Examples {
+		result.Examples = append(result.Examples, ex.Name)
+	}
+
+	return result
+}
+
+func buildAPIData_Dependencies(info *PackageDependencyInfo) *APIData_Dependencies {
+	paths := func(pkgs []*PackageForListing) []string {
+		r := make([]string, 0, len(pkgs))
+		for _, p := range pkgs {
+			r = append(r, p.Path)
+		}
+		return r
+	}
+	return &APIData_Dependencies{
+		ImportPath:  info.ImportPath,
+		Name:        info.Name,
+		Imports:     paths(info.Imports),
+		ImportedBys: paths(info.ImportedBys),
+	}
+}
+
+func buildAPIData_Implementations(result *MethodImplementationResult) *APIData_Implementations {
+	data := &APIData_Implementations{
+		Package:     result.Package.Path,
+		TypeName:    result.TypeName.Name(),
+		IsInterface: result.IsInterface,
+		Methods:     make([]APIData_MethodImplementations, 0, len(result.Methods)),
+	}
+	for _, m := range result.Methods {
+		impls := make([]APIData_Implementation, 0, len(m.Implementations))
+		for _, impl := range m.Implementations {
+			impls = append(impls, APIData_Implementation{
+				Receiver:  apiDataTypeRef(impl.Receiver),
+				Explicit:  impl.Explicit,
+				Interface: impl.Interface,
+			})
+		}
+		data.Methods = append(data.Methods, APIData_MethodImplementations{
+			Method:          m.Method.Name(),
+			Implementations: impls,
+		})
+	}
+	return data
+}
+
+func buildAPIData_References(result *ReferencesResult) *APIData_References {
+	data := &APIData_References{
+		Package:    result.Package.Path,
+		Identifier: result.Identifier,
+		NumUses:    result.UsesCount,
+		References: make([]APIData_PackageReferences, 0, len(result.References)),
+	}
+	for _, ref := range result.References {
+		uses := make([]APIData_Position, 0, len(ref.Identifiers))
+		for i := range ref.Identifiers {
+			id := &ref.Identifiers[i]
+			pos := apiDataPosition(ref.Pkg.PPkg.Fset.PositionFor(id.AstIdent.NamePos, false))
+			pos.File = id.FileInfo.AstBareFileName()
+			uses = append(uses, pos)
+		}
+		data.References = append(data.References, APIData_PackageReferences{
+			Package:      ref.Pkg.Path,
+			InCurrentPkg: ref.InCurrentPkg,
+			Uses:         uses,
+		})
+	}
+	return data
+}
+
+func buildAPIData_Overview(overview *Overview) *APIData_Overview {
+	data := &APIData_Overview{
+		Packages: make([]APIData_PackageForListing, 0, len(overview.Packages)),
+	}
+	for _, p := range overview.Packages {
+		data.Packages = append(data.Packages, APIData_PackageForListing{
+			ImportPath:         p.Path,
+			Name:               p.Name,
+			Module:             p.Module,
+			OneLineDoc:         p.OneLineDoc,
+			LOC:                int(p.LOC),
+			NumImportedBys:     int(p.NumImportedBys),
+			DepHeight:          int(p.DepHeight),
+			DepDepth:           int(p.DepDepth),
+			Afferent:           int(p.Afferent),
+			Efferent:           int(p.Efferent),
+			Instability:        p.Instability,
+			Abstractness:       p.Abstractness,
+			Distance:           p.Distance,
+			InWorkingDirectory: p.InWorkingDirectory,
+		})
+	}
+	return data
+}
+
+func buildAPIData_Modules(analyzer *code.CodeAnalyzer) []APIData_Module {
+	var modules []APIData_Module
+	analyzer.IterateModule(func(m *code.Module) {
+		pkgs := make([]string, 0, len(m.Pkgs))
+		for _, p := range m.Pkgs {
+			pkgs = append(pkgs, p.Path)
+		}
+		sort.Slice(pkgs, func(a, b int) bool {
+			return code.ComparePackagePaths(pkgs[a], pkgs[b], '/')
+		})
+		modules = append(modules, APIData_Module{
+			Path:             m.Path,
+			Version:          m.Version,
+			ReplacedBy:       m.Replace.Path,
+			ReplacedVersion:  m.Replace.Version,
+			RepositoryURL:    m.RepositoryURL,
+			RepositoryCommit: m.RepositoryCommit,
+			Packages:         pkgs,
+		})
+	})
+	return modules
+}
+
+func buildAPIData_Statistics(stats code.Stats) *APIData_Statistics {
+	return &APIData_Statistics{
+		Packages:                int(stats.Packages),
+		StdPackages:             int(stats.StdPackages),
+		AllPackageDeps:          int(stats.AllPackageDeps),
+		FilesWithoutGenerateds:  int(stats.FilesWithoutGenerateds),
+		FilesWithGenerateds:     int(stats.FilesWithGenerateds),
+		Imports:                 int(stats.Imports),
+		CodeLinesWithBlankLines: int(stats.CodeLinesWithBlankLines),
+
+		ExportedTypeNames:   int(stats.ExportedTypeNames),
+		ExportedTypeAliases: int(stats.ExportedTypeAliases),
+		ExportedVariables:   int(stats.ExportedVariables),
+		ExportedConstants:   int(stats.ExportedConstants),
+		ExportedFunctions:   int(stats.ExportedFunctions),
+		ExportedMethods:     int(stats.ExportedMethods),
+		ExportedIdentifers:  int(stats.ExportedIdentifers),
+	}
+}
@@ -144,6 +144,7 @@ func (ds *docServer) loadAPI(w http.ResponseWriter, r *http.Request) {
 
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
 
 	if fromIndex > len(ds.analyzingLogs) {
 		fromIndex = len(ds.analyzingLogs)
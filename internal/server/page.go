@@ -4,7 +4,9 @@ import (
 	//"bytes"
 	"fmt"
 	"go/build"
+	"html/template"
 	"io"
+	"log"
 	"net/http"
 	"strings"
 	"sync"
@@ -30,6 +32,28 @@ type PageOutputOptions struct {
 	FooterShowingManner    string
 	Theme                  string
 
+	// Custom page branding (see loadPageBranding).
+	HeaderTemplate  string // the path of an HTML template file
+	FooterTemplate  string // the path of an HTML template file
+	SidebarTemplate string // the path of an HTML template file
+	LogoFile        string // the path of a PNG or SVG image file
+	ProductName     string
+
+	// The directory containing the templates
+	// overriding the default page templates.
+	TemplatesDir string
+
+	// The directory containing translation catalog
+	// files (see loadTranslationCatalogs).
+	TranslationsDir string
+
+	// For docs generation mode only.
+	GeneratedPackages string // "all", "wd" or a comma-separated package pattern list
+	ExternalDocsURL   string // the docs base URL of the not generated packages
+	DocsVersion       string // generate versioned docs if it is not blank
+	BaseURL           string // the URL the docs will be deployed at
+	CheckLinks        bool   // check the links in the generated pages
+
 	// ToDo:
 	//ListUnexportedRes   bool
 }
@@ -56,6 +80,25 @@ var (
 
 	renderDocLinks     = false
 	unfoldAllInitially = false
+	writeDashAnchors   = false // for docset generation mode only
+
+	// For docs generation mode only.
+	// nil generatedPackages means all packages are generated.
+	// Links to the not generated packages point to externalDocsURL
+	// (if it is not blank) or to stub package pages.
+	generatedPackages map[string]bool
+	externalDocsURL   string
+
+	// For versioned docs generation mode only (see genDocPages).
+	// sharedAssetsPathPrefix is the path of the shared assets
+	// directory, relative to the docs directory of the version.
+	docsVersion            string
+	sharedAssetsPathPrefix string
+
+	// For docs generation mode only. The URL of the docs directory.
+	// If it is not blank, pages contain canonical links, and some
+	// static hosting files are generated (see writeStaticHostingFiles).
+	docsBaseURL string
 
 	verboseLogs = false
 
@@ -78,6 +121,20 @@ func setPageOutputOptions(options PageOutputOptions, forTesting bool) {
 	wdPkgsListingManner = options.WdPkgsListingManner
 	footerShowingManner = options.FooterShowingManner
 	pageTheme = options.Theme
+	if !forTesting {
+		loadUserThemes()
+		loadTranslationCatalogs(options.TranslationsDir)
+	}
+	if pageTheme != "" && !isThemeName(pageTheme) {
+		log.Fatalln("-theme: unknown theme:", pageTheme)
+	}
+	loadPageBranding(options)
+	loadPageTemplates(options.TemplatesDir)
+
+	externalDocsURL = options.ExternalDocsURL
+	if externalDocsURL != "" && !strings.HasSuffix(externalDocsURL, "/") {
+		externalDocsURL += "/"
+	}
 
 	verboseLogs = options.VerboseLogs
 }
@@ -198,6 +255,9 @@ type htmlPage struct {
 
 	isHTML bool
 
+	// Non-nil if custom branding is specified.
+	brandingData *pageBrandingData
+
 	htmlEscapeWriter *util.HTMLEscapeWriter
 }
 
@@ -217,29 +277,93 @@ func NewHtmlPage(goldsVersion, title string, theme Theme, translation Translatio
 	page.htmlEscapeWriter = util.NewHTMLEscapeWriter(&page)
 
 	if page.isHTML {
+		if hasCustomBranding() {
+			page.brandingData = newPageBrandingData(title, currentPageInfo)
+		}
+		if productName != "" {
+			title += " - " + template.HTMLEscapeString(productName)
+		}
+
 		fmt.Fprintf(&page, `<!DOCTYPE html>
 <html>
 <head>
 <meta charset="utf-8">
 <meta http-equiv="X-UA-Compatible" content="IE=edge">
 <meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="color-scheme" content="%s">
 <title>%s</title>
-<link href="%s" rel="stylesheet">
+<link id="theme-css" href="%s" rel="stylesheet">
 <script src="%s"></script>
-<body onload="onPageLoad()"><div>
 `,
+			colorScheme(theme),
 			title,
 			buildPageHref(currentPageInfo, createPagePathInfo(ResTypeCSS, addVersionToFilename(theme.Name(), goldsVersion)), nil, ""),
 			buildPageHref(currentPageInfo, createPagePathInfo(ResTypeJS, addVersionToFilename("golds", goldsVersion)), nil, ""),
 		)
+		if genDocsMode && docsBaseURL != "" {
+			writeDocsBaseURLLink(&page, currentPageInfo)
+		}
+		page.WriteString(`<body onload="onPageLoad()"><div>
+`)
+
+		page.WriteString(`<div id="page-settings" style="position: absolute; top: 8px; right: 8px;">`)
+		if genDocsMode && docsVersion != "" && currentPageInfo != notFoundPagePathInfo {
+			writeVersionSwitcher(&page, currentPageInfo)
+		}
+		writeThemeChooser(&page, theme, currentPageInfo)
+		page.WriteString("</div>\n")
+
+		if page.brandingData != nil {
+			writeBrandingFragment(&page, headerTemplate, "custom-header")
+			writeBrandingFragment(&page, sidebarTemplate, "custom-sidebar")
+		}
 	}
 
 	return &page
 }
 
+func colorScheme(theme Theme) string {
+	if theme.Dark() {
+		return "dark"
+	}
+	return "light"
+}
+
+// writeThemeChooser writes a selector to choose a theme from all themes.
+// In docs generation mode, the CSS files of all the themes are generated
+// and themes are switched in the browser (the choice is remembered in the
+// browser). In serving mode, choosing a theme requests the current page
+// with a theme query parameter, which changes the current theme.
+func writeThemeChooser(page *htmlPage, current Theme, currentPageInfo pagePathInfo) {
+	page.WriteString(`<span id="theme-chooser"><select title="theme">`)
+	for _, t := range themeChoices() {
+		page.WriteString(`<option value="`)
+		page.WriteString(t.Name())
+		page.WriteString(`" data-scheme="`)
+		page.WriteString(colorScheme(t))
+		page.WriteByte('"')
+		if genDocsMode {
+			page.WriteString(` data-href="`)
+			page.WriteString(buildPageHref(currentPageInfo, createPagePathInfo(ResTypeCSS, addVersionToFilename(t.Name(), goldsVersion)), nil, ""))
+			page.WriteByte('"')
+		}
+		if t.Name() == current.Name() {
+			page.WriteString(` selected`)
+		}
+		page.WriteByte('>')
+		page.WriteString(t.Name())
+		page.WriteString(`</option>`)
+	}
+	page.WriteString("</select></span>\n")
+}
+
 // ToDo: w is not used now. It will be used if the page cache feature is remvoed later.s
 func (page *htmlPage) Done(w io.Writer) []byte {
 	if page.isHTML {
+		if page.brandingData != nil {
+			writeBrandingFragment(page, footerTemplate, "custom-footer")
+		}
+
 		if footerShowingManner == FooterShowingManner_none {
 			//} else if genDocsMode && footerHTML != "" {
 			//	page.WriteString(footerHTML)
@@ -396,6 +520,9 @@ func createPagePathInfo(resType pageResType, resPath string) pagePathInfo {
 // scope should be an import path.
 func createPagePathInfo1(resType pageResType, scope string) pagePathInfo {
 	if genDocsMode {
+		if resType == ResTypeDependency && !isGeneratedPackage(scope) {
+			resType = ResTypePackage
+		}
 		scope = hashedScope(scope)
 	}
 
@@ -405,6 +532,9 @@ func createPagePathInfo1(resType pageResType, scope string) pagePathInfo {
 // scope should be an import path.
 func createPagePathInfo2(resType pageResType, scope, sep, resPath string) pagePathInfo {
 	if genDocsMode {
+		if !isGeneratedPackage(scope) {
+			return createPagePathInfo1(ResTypePackage, scope)
+		}
 		scope = hashedScope(scope)
 		resPath = hashedIdentifier(resPath)
 	}
@@ -415,6 +545,9 @@ func createPagePathInfo2(resType pageResType, scope, sep, resPath string) pagePa
 // scope should be an import path.
 func createPagePathInfo2b(resType pageResType, scope, sep, resPath string) pagePathInfo {
 	if genDocsMode {
+		if !isGeneratedPackage(scope) && sourceReadingStyle != SourceReadingStyle_external {
+			return createPagePathInfo1(ResTypePackage, scope)
+		}
 		scope = hashedScope(scope)
 		resPath = hashedFilename(resPath)
 	}
@@ -425,6 +558,9 @@ func createPagePathInfo2b(resType pageResType, scope, sep, resPath string) pageP
 // scope should be an import path.
 func createPagePathInfo3(resType pageResType, scope, sep, resPath, selector string) pagePathInfo {
 	if genDocsMode {
+		if !isGeneratedPackage(scope) {
+			return createPagePathInfo1(ResTypePackage, scope)
+		}
 		scope = hashedScope(scope)
 		resPath = hashedIdentifier(resPath)
 		selector = hashedIdentifier(selector)
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"html/template"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+// Projects may customize the pages with their own branding:
+// HTML template fragments for the header, footer and sidebar,
+// and a logo and product name. Custom branding is applied in
+// both the serving and the docs generation modes.
+var (
+	headerTemplate  *template.Template
+	footerTemplate  *template.Template
+	sidebarTemplate *template.Template
+
+	productName string
+	logoResType pageResType // ResTypePNG or ResTypeSVG, blank for no logo
+	logoData    []byte
+
+	// The version of the Go toolchain used to analyze code.
+	goToolchainVersion string
+)
+
+// The filename (without extension) of the custom logo image.
+const customLogoFilename = "custom-logo"
+
+// The header used if only the logo and product name are specified.
+const defaultHeaderTemplate = `<a href="{{.OverviewURL}}" style="font-size: x-large; text-decoration: none;">` +
+	`{{if .LogoURL}}<img src="{{.LogoURL}}" alt="" style="height: 32px; vertical-align: middle;"> {{end}}` +
+	`{{.ProductName}}</a>`
+
+// pageBrandingData is passed to the custom branding templates.
+type pageBrandingData struct {
+	// The kind of the page: "pkg" (package details), "dep"
+	// (package dependencies), "src" (source code), "imp"
+	// (implementations), "use" (references), "mod" (module),
+	// or "" (the overview page and other pages).
+	PageType string
+	// The package the page is for. Blank for some page types.
+	PackagePath string
+	Title       string
+
+	GoldsVersion string
+	GoVersion    string
+	ProductName  string
+
+	// The URLs are relative to the page.
+	LogoURL     string // blank if no logo is specified
+	OverviewURL string
+}
+
+// loadPageBranding loads the custom branding templates and logo.
+// Errors are fatal, for they are from the program options.
+func loadPageBranding(options PageOutputOptions) {
+	parse := func(option, file string) *template.Template {
+		if file == "" {
+			return nil
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatalf("%s: read template file error: %s", option, err)
+		}
+		t, err := template.New(filepath.Base(file)).Parse(string(data))
+		if err != nil {
+			log.Fatalf("%s: parse template error: %s", option, err)
+		}
+		return t
+	}
+	headerTemplate = parse("-header-template", options.HeaderTemplate)
+	footerTemplate = parse("-footer-template", options.FooterTemplate)
+	sidebarTemplate = parse("-sidebar-template", options.SidebarTemplate)
+	productName = options.ProductName
+
+	logoResType, logoData = "", nil
+	if options.LogoFile != "" {
+		switch ext := strings.ToLower(filepath.Ext(options.LogoFile)); ext {
+		default:
+			log.Fatalln("-logo: only .png and .svg images are supported:", options.LogoFile)
+		case ".png":
+			logoResType = ResTypePNG
+		case ".svg":
+			logoResType = ResTypeSVG
+		}
+		data, err := ioutil.ReadFile(options.LogoFile)
+		if err != nil {
+			log.Fatalln("-logo: read logo file error:", err)
+		}
+		logoData = data
+	}
+
+	if headerTemplate == nil && (productName != "" || logoData != nil) {
+		headerTemplate = template.Must(template.New("header").Parse(defaultHeaderTemplate))
+	}
+}
+
+func hasCustomBranding() bool {
+	return headerTemplate != nil || footerTemplate != nil || sidebarTemplate != nil
+}
+
+// newPageBrandingData returns the template data of a page.
+func newPageBrandingData(title string, currentPageInfo pagePathInfo) *pageBrandingData {
+	data := &pageBrandingData{
+		PageType:     string(currentPageInfo.resType),
+		PackagePath:  pagePackagePath(currentPageInfo),
+		Title:        title,
+		GoldsVersion: goldsVersion,
+		GoVersion:    goToolchainVersion,
+		ProductName:  productName,
+		OverviewURL:  buildPageHref(currentPageInfo, createPagePathInfo(ResTypeNone, ""), nil, ""),
+	}
+	if logoData != nil {
+		data.LogoURL = buildPageHref(currentPageInfo, createPagePathInfo(logoResType, customLogoFilename), nil, "")
+	}
+	return data
+}
+
+// pagePackagePath returns the path of the package a page is for.
+// The separators are the same as the ones used in ServeHTTP.
+func pagePackagePath(pathInfo pagePathInfo) (pkgPath string) {
+	resPath := pathInfo.resPath
+	switch pathInfo.resType {
+	default:
+		return ""
+	case ResTypePackage, ResTypeDependency:
+		pkgPath = resPath
+	case ResTypeSource:
+		if index := strings.LastIndex(resPath, "/"); index >= 0 {
+			pkgPath = resPath[:index]
+		}
+	case ResTypeImplementation:
+		if index := strings.LastIndex(resPath, "."); index >= 0 {
+			pkgPath = resPath[:index]
+		}
+	case ResTypeReference:
+		if index := strings.LastIndex(resPath, ".."); index >= 0 {
+			pkgPath = resPath[:index]
+		}
+	}
+	if genDocsMode {
+		pkgPath = deHashScope(pkgPath)
+	}
+	return pkgPath
+}
+
+// writeBrandingFragment executes a custom branding template
+// and writes the result in a div element with the specified id.
+func writeBrandingFragment(page *htmlPage, t *template.Template, id string) {
+	if t == nil {
+		return
+	}
+	page.WriteString(`<div id="` + id + `">`)
+	if err := t.Execute(page, page.brandingData); err != nil {
+		log.Printf("Execute template %s error: %s", t.Name(), err)
+	}
+	page.WriteString("</div>\n")
+}
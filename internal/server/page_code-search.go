@@ -0,0 +1,279 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go101.org/golds/code"
+)
+
+const (
+	codeSearchContextLines = 2
+	maxCodeSearchMatches   = 1000
+)
+
+// Code search is only available in server mode,
+// for it needs to access the cached source file contents.
+func (ds *docServer) codeSearchPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	if genDocsMode {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "Code search is not supported in docs generation mode")
+		return
+	}
+
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
+
+	if ds.phase < Phase_Analyzed {
+		w.WriteHeader(http.StatusTooEarly)
+		ds.loadingPage(w, r)
+		return
+	}
+
+	query := r.FormValue("q")
+	useRegexp := r.FormValue("regexp") != ""
+	scope := strings.Trim(strings.TrimSpace(r.FormValue("scope")), "/")
+
+	var result *CodeSearchResult
+	var err error
+	if query != "" {
+		result, err = ds.searchCode(query, useRegexp, scope)
+	}
+
+	// Results are not cached, for they depend on the query.
+	w.Write(ds.buildCodeSearchPage(w, query, useRegexp, scope, result, err))
+}
+
+func (ds *docServer) buildCodeSearchPage(w http.ResponseWriter, query string, useRegexp bool, scope string, result *CodeSearchResult, err error) []byte {
+	page := NewHtmlPage(goldsVersion, ds.currentTranslation.Text_CodeSearch(), ds.currentTheme, ds.currentTranslation, createPagePathInfo(ResTypeNone, "code-search"))
+	fmt.Fprintf(page, `
+<pre><code><span style="font-size:xx-large;">%s</span></code></pre>
+`,
+		page.Translation().Text_CodeSearch(),
+	)
+
+	checked := ""
+	if useRegexp {
+		checked = " checked"
+	}
+	fmt.Fprintf(page, `<pre><form action="%s" method="get">`, buildPageHref(page.PathInfo, createPagePathInfo(ResTypeNone, "code-search"), nil, ""))
+	page.WriteString(`<input type="text" name="q" size="48" value="`)
+	page.AsHTMLEscapeWriter().WriteString(query)
+	fmt.Fprintf(page, `"> <input type="submit" value="%s">`, page.Translation().Text_Search())
+	fmt.Fprintf(page, "\n"+`<label><input type="checkbox" name="regexp" value="1"%s> %s</label>`, checked, page.Translation().Text_CodeSearchOption("regexp"))
+	fmt.Fprintf(page, "\n%s%s", page.Translation().Text_CodeSearchOption("scope"), page.Translation().Text_Colon(false))
+	page.WriteString(`<input type="text" name="scope" size="40" value="`)
+	page.AsHTMLEscapeWriter().WriteString(scope)
+	page.WriteString(`"></form></pre>`)
+	page.WriteString("\n")
+
+	if err != nil {
+		page.WriteString(`<pre><code>`)
+		page.AsHTMLEscapeWriter().WriteString(err.Error())
+		page.WriteString("</code></pre>\n")
+	} else if result != nil {
+		ds.writeCodeSearchResult(page, result)
+	}
+
+	return page.Done(w)
+}
+
+func (ds *docServer) writeCodeSearchResult(page *htmlPage, result *CodeSearchResult) {
+	fmt.Fprintf(page, "<pre><code>%s</code></pre>\n", page.Translation().Text_CodeSearchResultStat(result.NumMatches, len(result.Files), result.Truncated))
+
+	for _, f := range result.Files {
+		page.WriteString(`<pre><code><span class="title">`)
+		page.WriteString(f.Package.Path)
+		page.WriteByte('/')
+		writeSrouceCodeFileLink(page, f.Package, f.Filename)
+		page.WriteString(`</span>`)
+
+		lastLine := 0
+		for _, ln := range f.Lines {
+			if lastLine > 0 && ln.Number > lastLine+1 {
+				page.WriteString("\n\t...")
+			}
+			lastLine = ln.Number
+
+			page.WriteString("\n\t")
+			buildPageHref(page.PathInfo, createPagePathInfo2b(ResTypeSource, f.Package.Path, "/", f.Filename), page, fmt.Sprintf("%6d", ln.Number), "line-", strconv.Itoa(ln.Number))
+			page.WriteString("  ")
+			if ln.Matched {
+				page.WriteString(`<span class="code-search-matched">`)
+			}
+			page.AsHTMLEscapeWriter().WriteString(ln.Text)
+			if ln.Matched {
+				page.WriteString(`</span>`)
+			}
+		}
+		page.WriteString("</code></pre>\n")
+	}
+}
+
+// CodeSearchResult holds the matched lines of a code search.
+type CodeSearchResult struct {
+	NumMatches int  // the number of matched lines
+	Truncated  bool // whether or not there are more matches than maxCodeSearchMatches
+	Files      []*CodeSearchFile
+}
+
+// CodeSearchFile holds the matched lines (and their context lines) in a source file.
+type CodeSearchFile struct {
+	Package  *code.Package
+	Filename string // bare filename
+	Lines    []CodeSearchLine
+}
+
+type CodeSearchLine struct {
+	Number  int // 1-based
+	Text    string
+	Matched bool
+}
+
+// codeSearchScopeMatched returns whether or not the package is
+// within the scope, which is a module path or a package path prefix.
+func codeSearchScopeMatched(pkg *code.Package, scope string) bool {
+	if scope == "" {
+		return true
+	}
+	if pkg.ModulePath() == scope {
+		return true
+	}
+	return pkg.Path == scope || strings.HasPrefix(pkg.Path, scope+"/")
+}
+
+// ds should be locked before calling this method.
+func (ds *docServer) searchCode(query string, useRegexp bool, scope string) (*CodeSearchResult, error) {
+	if !useRegexp {
+		query = regexp.QuoteMeta(query)
+	}
+	re, err := regexp.Compile("(?m)" + query)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs := make([]*code.Package, 0, ds.analyzer.NumPackages())
+	for i, n := 0, ds.analyzer.NumPackages(); i < n; i++ {
+		pkg := ds.analyzer.PackageAt(i)
+		if codeSearchScopeMatched(pkg, scope) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	sort.Slice(pkgs, func(a, b int) bool {
+		return code.ComparePackagePaths(pkgs[a].Path, pkgs[b].Path, '/')
+	})
+
+	return searchCodeInPackages(re, pkgs, maxCodeSearchMatches), nil
+}
+
+// searchCodeInPackages searches the source files of the packages
+// for at most maxMatches matched lines.
+func searchCodeInPackages(re *regexp.Regexp, pkgs []*code.Package, maxMatches int) *CodeSearchResult {
+	result := &CodeSearchResult{}
+	for _, pkg := range pkgs {
+		for i := range pkg.SourceFiles {
+			info := &pkg.SourceFiles[i]
+			if info.Content == nil {
+				continue
+			}
+			if result.NumMatches >= maxMatches {
+				// Only check whether or not there are more matches.
+				if re.Match(info.Content) {
+					result.Truncated = true
+					return result
+				}
+				continue
+			}
+			lines, numMatches, more := searchCodeInContent(re, info.Content, codeSearchContextLines, maxMatches-result.NumMatches)
+			if numMatches == 0 {
+				continue
+			}
+			result.NumMatches += numMatches
+			result.Files = append(result.Files, &CodeSearchFile{
+				Package:  pkg,
+				Filename: info.AstBareFileName(),
+				Lines:    lines,
+			})
+			if more {
+				result.Truncated = true
+				return result
+			}
+		}
+	}
+	return result
+}
+
+// searchCodeInContent returns the matched lines and their context lines,
+// the number of the matched lines (which is not larger than maxMatches),
+// and whether or not there are more matched lines than maxMatches.
+func searchCodeInContent(re *regexp.Regexp, content []byte, numContextLines, maxMatches int) (lines []CodeSearchLine, numMatches int, more bool) {
+	locs := re.FindAllIndex(content, -1)
+	if len(locs) == 0 {
+		return nil, 0, false
+	}
+
+	var lineStarts = make([]int, 1, bytes.Count(content, []byte{'\n'})+1)
+	for i, b := range content {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	lineIndex := func(offset int) int {
+		return sort.Search(len(lineStarts), func(i int) bool {
+			return lineStarts[i] > offset
+		}) - 1
+	}
+	lineText := func(index int) string {
+		end := len(content)
+		if index+1 < len(lineStarts) {
+			end = lineStarts[index+1] - 1
+		}
+		return strings.TrimRight(string(content[lineStarts[index]:end]), "\r")
+	}
+
+	matcheds := make([]int, 0, len(locs))
+	for _, loc := range locs {
+		index := lineIndex(loc[0])
+		if n := len(matcheds); n > 0 && matcheds[n-1] == index {
+			continue
+		}
+		if len(matcheds) >= maxMatches {
+			more = true
+			break
+		}
+		matcheds = append(matcheds, index)
+	}
+
+	lines = make([]CodeSearchLine, 0, len(matcheds)*(1+2*numContextLines))
+	next := 0 // the first line index which has not been listed
+	for k, index := range matcheds {
+		from := index - numContextLines
+		if from < next {
+			from = next
+		}
+		to := index + numContextLines
+		if k+1 < len(matcheds) && to >= matcheds[k+1] {
+			to = matcheds[k+1] - 1
+		}
+		if to >= len(lineStarts) {
+			to = len(lineStarts) - 1
+		}
+		for i := from; i <= to; i++ {
+			lines = append(lines, CodeSearchLine{
+				Number:  i + 1,
+				Text:    lineText(i),
+				Matched: i == index,
+			})
+		}
+		next = to + 1
+	}
+	return lines, len(matcheds), more
+}
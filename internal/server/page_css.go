@@ -1,6 +1,8 @@
 package server
 
 import (
+	"bytes"
+	"log"
 	"net/http"
 	"text/template"
 )
@@ -15,6 +17,7 @@ func (ds *docServer) cssFile(w http.ResponseWriter, r *http.Request, themeName s
 
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
 
 	if genDocsMode {
 		themeName = deHashFilename(themeName)
@@ -60,13 +63,16 @@ func (ds *docServer) cssFile(w http.ResponseWriter, r *http.Request, themeName s
 		css := commonCSS + theme.CSS()
 
 		t, err := template.New("css").Parse(css)
-		if err != nil {
-			panic("parse css template error: " + err.Error())
+		if err == nil {
+			var buf bytes.Buffer
+			if err = t.Execute(&buf, options); err == nil {
+				page.Write(buf.Bytes())
+			}
 		}
-		//var buf bytes.Buffer
-		//if t.Execute(&buf, options) != nil {
-		if t.Execute(page, options) != nil {
-			panic("execute css template error: " + err.Error())
+		if err != nil {
+			log.Printf("build css file for theme %s error: %s", themeName, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
 		}
 
 		data = page.Done(w)
@@ -105,6 +111,46 @@ div.codelines .codelines {display: inline;}
 div.codelines .depheight {display: none;}
 div.codelines .importedbys {display: none;}
 div.codelines .depdepth {display: none;}
+div.alphabet .instability {display: none;}
+div.alphabet .abstractness {display: none;}
+div.alphabet .distance {display: none;}
+div.importedbys .instability {display: none;}
+div.importedbys .abstractness {display: none;}
+div.importedbys .distance {display: none;}
+div.codelines .instability {display: none;}
+div.codelines .abstractness {display: none;}
+div.codelines .distance {display: none;}
+div.depdepth .instability {display: none;}
+div.depdepth .abstractness {display: none;}
+div.depdepth .distance {display: none;}
+div.depheight .instability {display: none;}
+div.depheight .abstractness {display: none;}
+div.depheight .distance {display: none;}
+div.instability .instability {display: inline;}
+div.instability .importedbys {display: none;}
+div.instability .codelines {display: none;}
+div.instability .depdepth {display: none;}
+div.instability .depheight {display: none;}
+div.instability .abstractness {display: none;}
+div.instability .distance {display: none;}
+div.abstractness .abstractness {display: inline;}
+div.abstractness .importedbys {display: none;}
+div.abstractness .codelines {display: none;}
+div.abstractness .depdepth {display: none;}
+div.abstractness .depheight {display: none;}
+div.abstractness .instability {display: none;}
+div.abstractness .distance {display: none;}
+div.distance .distance {display: inline;}
+div.distance .importedbys {display: none;}
+div.distance .codelines {display: none;}
+div.distance .depdepth {display: none;}
+div.distance .depheight {display: none;}
+div.distance .instability {display: none;}
+div.distance .abstractness {display: none;}
+
+/* search pages */
+
+.code-search-matched {font-weight: bold;}
 
 /* package details page */
 
@@ -56,6 +56,7 @@ func (ds *docServer) identifierReferencePage(w http.ResponseWriter, r *http.Requ
 
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
 
 	if ds.phase < Phase_Analyzed {
 		w.WriteHeader(http.StatusTooEarly)
@@ -80,6 +81,7 @@ func (ds *docServer) identifierReferencePage(w http.ResponseWriter, r *http.Requ
 	pageKey := pageCacheKey{
 		resType: ResTypeReference,
 		res:     [...]string{pkgPath, identifier},
+		options: ds.pageCacheOptions(),
 	}
 	data, ok := ds.cachedPage(pageKey)
 	if !ok {
@@ -90,91 +92,55 @@ func (ds *docServer) identifierReferencePage(w http.ResponseWriter, r *http.Requ
 			return
 		}
 
-		data = ds.buildReferencesPage(w, result)
+		data, err = ds.buildReferencesPage(w, result)
+		if err != nil {
+			writePageBuildError(w, err)
+			return
+		}
 		ds.cachePage(pageKey, data)
 	}
 	w.Write(data)
 }
 
-func (ds *docServer) buildReferencesPage(w http.ResponseWriter, result *ReferencesResult) []byte {
+func (ds *docServer) buildReferencesPage(w http.ResponseWriter, result *ReferencesResult) ([]byte, error) {
 	title := ds.currentTranslation.Text_ReferenceList() + ds.currentTranslation.Text_Colon(false) + result.Package.Path + "." + result.Identifier
 	page := NewHtmlPage(goldsVersion, title, ds.currentTheme, ds.currentTranslation, createPagePathInfo2(ResTypeReference, result.Package.Path, "..", result.Identifier))
 
-	var prefix string
-	if result.Selector == nil {
-		switch result.Resource.(type) {
-		case *code.Variable:
-			prefix = "var "
-		case *code.Constant:
-			prefix = "const "
-		case *code.Function:
-			prefix = "func "
-		case *code.TypeName:
-			prefix = "type "
-		}
-	}
-	fmt.Fprintf(page, `
-<pre><code><span style="font-size:x-large;">%s<b><a href="%s">%s</a>.`,
-		prefix,
-		buildPageHref(page.PathInfo, createPagePathInfo1(ResTypePackage, result.Package.Path), nil, ""),
-		result.Package.Path,
-	)
-
-	ds.writeResourceIndexHTML(page, result.Package, result.Resource, false, false, false)
-
-	if result.Selector != nil {
-		page.WriteByte('.')
+	writeSelector := func() {
 		if result.Selector.Field != nil {
 			ds.writeFieldCodeLink(page, result.Selector)
 		} else {
 			ds.writeMethodForListing(page, result.Package, result.Selector, nil, false, true)
 		}
 	}
-	page.WriteString(`</b></span>`)
 
-	if result.Selector != nil {
-		page.WriteString(`<span style="font-size: large;"><i>`)
-		page.WriteString(page.Translation().Text_Parenthesis(false))
-		if result.Selector.Field != nil {
-			page.WriteString(page.Translation().Text_ObjectKind("field"))
-		} else {
-			page.WriteString(page.Translation().Text_ObjectKind("method"))
+	writeImplementationsLink := func() {
+		methodName := result.Selector.Method.Name
+		var methodPkgPath string
+		if !token.IsExported(methodName) {
+			methodPkgPath = result.Selector.Method.Pkg.Path
+		}
+		var link string
+		if ds.analyzer.CheckTypeMethodContributingToTypeImplementations(result.Package.Path, result.Resource.Name(), methodPkgPath, methodName) {
+			// entering here meaning this must be a non-interface method.
 
-			methodName := result.Selector.Method.Name
-			var methodPkgPath string
+			anchorName := methodName
 			if !token.IsExported(methodName) {
-				methodPkgPath = result.Selector.Method.Pkg.Path
+				anchorName = methodPkgPath + "." + anchorName
 			}
-			var link string
-			if ds.analyzer.CheckTypeMethodContributingToTypeImplementations(result.Package.Path, result.Resource.Name(), methodPkgPath, methodName) {
-				// entering here meaning this must be a non-interface method.
-
-				anchorName := methodName
-				if !token.IsExported(methodName) {
-					anchorName = methodPkgPath + "." + anchorName
-				}
-				if sourceReadingStyle == SourceReadingStyle_rich { // enableSoruceNavigation {
-					if collectUnexporteds || result.Resource.Exported() || result.Package.Path == "builtin" {
-						link = buildPageHref(page.PathInfo, createPagePathInfo2(ResTypeImplementation, result.Package.Path, ".", result.Resource.Name()), nil, "", "name-", anchorName)
-					}
+			if sourceReadingStyle == SourceReadingStyle_rich { // enableSoruceNavigation {
+				if collectUnexporteds || result.Resource.Exported() || result.Package.Path == "builtin" {
+					link = buildPageHref(page.PathInfo, createPagePathInfo2(ResTypeImplementation, result.Package.Path, ".", result.Resource.Name()), nil, "", "name-", anchorName)
 				}
 			}
+		}
 
-			if link != "" {
-				page.WriteString(page.Translation().Text_Comma())
-				fmt.Fprintf(page, `<a href="%s">%s</a>`, link, page.Translation().Text_ViewMethodImplementations())
-			}
+		if link != "" {
+			page.WriteString(page.Translation().Text_Comma())
+			fmt.Fprintf(page, `<a href="%s">%s</a>`, link, page.Translation().Text_ViewMethodImplementations())
 		}
-		page.WriteString(page.Translation().Text_Parenthesis(true))
-		page.WriteString(`</i></span>`)
 	}
 
-	page.WriteString("\n\n")
-	page.WriteString(`<span class="title">`)
-	page.WriteString(page.Translation().Text_ObjectUses(result.UsesCount))
-	page.WriteString(`</span>`)
-	page.WriteString("\n")
-
 	type idpos struct {
 		id  *ast.Ident
 		pos token.Position
@@ -215,59 +181,67 @@ func (ds *docServer) buildReferencesPage(w http.ResponseWriter, result *Referenc
 		stack = stack[:0]
 	}
 
-	for _, refGroup := range result.References {
-		page.WriteString("\n\t")
-		if refGroup.Pkg.Path == result.Package.Path {
-			page.WriteString(refGroup.Pkg.Path)
-			page.WriteString(page.Translation().Text_CurrentPackage())
-		} else {
-			buildPageHref(page.PathInfo, createPagePathInfo1(ResTypePackage, refGroup.Pkg.Path), page, refGroup.Pkg.Path)
-		}
-		page.WriteByte('\n')
-
-		var fileInfo *code.SourceFileInfo
-		var lineNumber int
-		stack = stack[:0]
-		for i := range refGroup.Identifiers {
-			id := &refGroup.Identifiers[i]
-			if fileInfo != id.FileInfo {
-				if fileInfo != nil {
-					excerptCode(fileInfo)
-				}
-				lineNumber = 0
-				fileInfo = id.FileInfo
-				//page.WriteString("\t\t")
-				//writeSrouceCodeFileLink(page, refGroup.Pkg, fileInfo.AstBareFileName())
-				//page.WriteByte('\n')
+	writeReferences := func() {
+		for _, refGroup := range result.References {
+			page.WriteString("\n\t")
+			if refGroup.Pkg.Path == result.Package.Path {
+				page.WriteString(refGroup.Pkg.Path)
+				page.WriteString(page.Translation().Text_CurrentPackage())
+			} else {
+				buildPageHref(page.PathInfo, createPagePathInfo1(ResTypePackage, refGroup.Pkg.Path), page, refGroup.Pkg.Path)
 			}
-
-			pos := refGroup.Pkg.PPkg.Fset.PositionFor(id.AstIdent.NamePos, false)
-			if lineNumber != pos.Line {
-				if lineNumber > 0 {
-					// ExcerptNearbyCode(page, id.FileInfo, id.AstIdent, pos)
-					excerptCode(fileInfo)
+			page.WriteByte('\n')
+
+			var fileInfo *code.SourceFileInfo
+			var lineNumber int
+			stack = stack[:0]
+			for i := range refGroup.Identifiers {
+				id := &refGroup.Identifiers[i]
+				if fileInfo != id.FileInfo {
+					if fileInfo != nil {
+						excerptCode(fileInfo)
+					}
+					lineNumber = 0
+					fileInfo = id.FileInfo
+					//page.WriteString("\t\t")
+					//writeSrouceCodeFileLink(page, refGroup.Pkg, fileInfo.AstBareFileName())
+					//page.WriteByte('\n')
 				}
-				//page.WriteString("\t\t\t")
-				page.WriteString("\t\t")
-				if lineNumber > 0 {
-					linkText := fmt.Sprintf("%s", fileInfo.AstBareFileName())
-					writeSrouceCodeLineLink(page, refGroup.Pkg, pos, linkText, "path-duplicate")
-					linkText = fmt.Sprintf("#L%d", pos.Line)
-					writeSrouceCodeLineLink(page, refGroup.Pkg, pos, linkText, "")
-				} else {
-					linkText := fmt.Sprintf("%s#L%d", fileInfo.AstBareFileName(), pos.Line)
-					writeSrouceCodeLineLink(page, refGroup.Pkg, pos, linkText, "")
+
+				pos := refGroup.Pkg.PPkg.Fset.PositionFor(id.AstIdent.NamePos, false)
+				if lineNumber != pos.Line {
+					if lineNumber > 0 {
+						// ExcerptNearbyCode(page, id.FileInfo, id.AstIdent, pos)
+						excerptCode(fileInfo)
+					}
+					//page.WriteString("\t\t\t")
+					page.WriteString("\t\t")
+					if lineNumber > 0 {
+						linkText := fmt.Sprintf("%s", fileInfo.AstBareFileName())
+						writeSrouceCodeLineLink(page, refGroup.Pkg, pos, linkText, "path-duplicate")
+						linkText = fmt.Sprintf("#L%d", pos.Line)
+						writeSrouceCodeLineLink(page, refGroup.Pkg, pos, linkText, "")
+					} else {
+						linkText := fmt.Sprintf("%s#L%d", fileInfo.AstBareFileName(), pos.Line)
+						writeSrouceCodeLineLink(page, refGroup.Pkg, pos, linkText, "")
+					}
+					page.WriteString(": ")
+					lineNumber = pos.Line
 				}
-				page.WriteString(": ")
-				lineNumber = pos.Line
+				stack = append(stack, idpos{id: id.AstIdent, pos: pos})
 			}
-			stack = append(stack, idpos{id: id.AstIdent, pos: pos})
+			excerptCode(fileInfo)
 		}
-		excerptCode(fileInfo)
 	}
 
-	page.WriteString("</code></pre>")
-	return page.Done(w)
+	if err := ds.writePageTemplate(page, "references.html", result, map[string]func(){
+		"selector":             writeSelector,
+		"implementations-link": writeImplementationsLink,
+		"references":           writeReferences,
+	}); err != nil {
+		return nil, err
+	}
+	return page.Done(w), nil
 }
 
 //func ExcerptNearbyCode(page *htmlPage, fileInfo *code.SourceFileInfo, astIdent *ast.Ident, pos token.Position) {
@@ -297,6 +271,25 @@ type ReferencesResult struct {
 	UsesCount  int
 }
 
+// Keyword returns the declaration keyword of the referenced
+// package-level resource. It is blank for fields and methods.
+func (r *ReferencesResult) Keyword() string {
+	if r.Selector != nil {
+		return ""
+	}
+	switch r.Resource.(type) {
+	case *code.Variable:
+		return "var"
+	case *code.Constant:
+		return "const"
+	case *code.Function:
+		return "func"
+	case *code.TypeName:
+		return "type"
+	}
+	return ""
+}
+
 type ObjectReferences struct {
 	Pkg          *code.Package
 	CommonPath   string // relative to the current package
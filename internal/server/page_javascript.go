@@ -50,11 +50,24 @@ function onPageLoad() {
 		e.stopPropagation();
 	});
 
+	if (document.getElementById("theme-chooser") != null) {
+		initThemeChooser();
+	}
+
+	if (document.getElementById("version-switcher") != null) {
+		initVersionSwitcher();
+	}
+
 	if (document.getElementById("overview") != null) {
 		initOverviewPage();
 		return
 	}
 
+	if (document.getElementById("search") != null) {
+		initSearchPage();
+		return
+	}
+
 	document.addEventListener("keydown", function(e){
 		if (e.ctrlKey || e.altKey || e.shiftKey) {
 			return;
@@ -73,6 +86,74 @@ function onPageLoad() {
 	}
 }
 
+function initThemeChooser() {
+	var selector = document.querySelector("#theme-chooser select");
+
+	// In docs generation mode, each option has the href of the CSS file
+	// of its theme, and the chosen theme is remembered in the browser.
+	var applyTheme = function(option) {
+		if (option == null || option.dataset.href == null) {
+			return false;
+		}
+		document.getElementById("theme-css").href = option.dataset.href;
+		document.querySelector("meta[name=color-scheme]").content = option.dataset.scheme;
+		option.selected = true;
+		return true;
+	};
+	try {
+		var stored = window.localStorage.getItem("golds-theme");
+		if (stored != null) {
+			applyTheme(selector.querySelector("option[value='" + CSS.escape(stored) + "']"));
+		}
+	} catch (e) {
+	}
+
+	selector.addEventListener("change", function() {
+		var option = selector.options[selector.selectedIndex];
+		if (applyTheme(option)) {
+			try {
+				window.localStorage.setItem("golds-theme", option.value);
+			} catch (e) {
+			}
+			return;
+		}
+		var params = new URLSearchParams(window.location.search);
+		params.set("theme", option.value);
+		window.location.search = params.toString();
+	});
+}
+
+function initVersionSwitcher() {
+	var selector = document.querySelector("#version-switcher select");
+	var root = selector.dataset.root;
+	var page = selector.dataset.page;
+	var current = selector.dataset.version;
+
+	var request = new XMLHttpRequest();
+	request.onload = function() {
+		if (request.status != 200) {
+			return;
+		}
+		var versions = JSON.parse(request.responseText);
+		selector.innerHTML = "";
+		for (var i = 0; i < versions.length; i++) {
+			var option = document.createElement("option");
+			option.value = versions[i];
+			option.text = versions[i];
+			option.selected = versions[i] == current;
+			selector.appendChild(option);
+		}
+	};
+	request.open("GET", root + "versions.json");
+	request.send();
+
+	selector.addEventListener("change", function() {
+		if (selector.value != current) {
+			window.location.href = root + encodeURIComponent(selector.value) + "/" + page;
+		}
+	});
+}
+
 function initOverviewPage() {
 	document.addEventListener("keydown", function(e){
 		if (e.ctrlKey || e.altKey || e.shiftKey) {
@@ -96,6 +177,9 @@ function initOverviewPage() {
 	var pkgsByImportedby = new Array(nodesPkg.length);
 	var pkgsByCodeLines = new Array(nodesPkg.length);
 	var pkgsByDepDepth = new Array(nodesPkg.length);
+	var pkgsByInstability = new Array(nodesPkg.length);
+	var pkgsByAbstractness = new Array(nodesPkg.length);
+	var pkgsByDistance = new Array(nodesPkg.length);
 	//var pkgsByDepHeight = new Array(nodesPkg.length);
 	for (var i = 0; i < nodesPkg.length; i++) {
 		var n = nodesPkg[i];
@@ -106,11 +190,17 @@ function initOverviewPage() {
 			codelines: parseInt(n.dataset.loc),
 			depdepth: parseInt(n.dataset.depdepth),
 			depheight: parseInt(n.dataset.depheight),
+			instability: parseFloat(n.dataset.instability),
+			abstractness: parseFloat(n.dataset.abstractness),
+			distance: parseFloat(n.dataset.distance),
 		};
 		pkgsByAlphabet[i] = t;
 		pkgsByImportedby[i] = t;
 		pkgsByCodeLines[i] = t;
 		pkgsByDepDepth[i] = t;
+		pkgsByInstability[i] = t;
+		pkgsByAbstractness[i] = t;
+		pkgsByDistance[i] = t;
 	}
 	pkgsByImportedby.sort(function(a, b) {
 		if (a.importedbys == b.importedbys) {
@@ -142,6 +232,20 @@ function initOverviewPage() {
 		}
 		return 1;
 	});
+	var byMetricDesc = function(metric) {
+		return function(a, b) {
+			if (a[metric] == b[metric]) {
+				if (a.node.id < b.node.id) {
+					return -1;
+				}
+				return 1;
+			}
+			return b[metric] - a[metric];
+		};
+	};
+	pkgsByInstability.sort(byMetricDesc("instability"));
+	pkgsByAbstractness.sort(byMetricDesc("abstractness"));
+	pkgsByDistance.sort(byMetricDesc("distance"));
 
 	var showSortByImportBysButton = true;
 	var showSortByCodeLinesButton = true;
@@ -198,6 +302,9 @@ function initOverviewPage() {
 	var sortByImportedbys = content.querySelector("#btn-importedbys");
 	var sortByCodeLines = content.querySelector("#btn-codelines");
 	var sortByDepdepth = content.querySelector("#btn-depdepth");
+	var sortByInstability = content.querySelector("#btn-instability");
+	var sortByAbstractness = content.querySelector("#btn-abstractness");
+	var sortByDistance = content.querySelector("#btn-distance");
 
 	sortByAlphabet.classList.add("chosen");
 	var currentSortBy = "alphabet";
@@ -282,6 +389,170 @@ function initOverviewPage() {
 		currentButton = sortByDepdepth;
 		currentButton.classList.add("chosen");
 	});
+
+	var listenSortByMetric = function(button, metric, pkgs) {
+		button.addEventListener('click', function(event) {
+			if (currentSortBy == metric) {
+				return;
+			}
+
+			pkgContainer.innerHTML = "";
+			pkgs.forEach(function (x, i) {
+				pkgContainer.appendChild(x.node);
+				var o = (i+pkgStartOrderId).toString();
+				x.order.innerText = SPACES.substr(0, maxDigitCount-o.length) + o;
+			});
+
+			pkgContainer.classList.remove(currentSortBy);
+			currentSortBy = metric;
+			pkgContainer.classList.add(currentSortBy);
+
+			currentButton.classList.remove("chosen");
+			currentButton = button;
+			currentButton.classList.add("chosen");
+		});
+	};
+	listenSortByMetric(sortByInstability, "instability", pkgsByInstability);
+	listenSortByMetric(sortByAbstractness, "abstractness", pkgsByAbstractness);
+	listenSortByMetric(sortByDistance, "distance", pkgsByDistance);
+}
+
+// Only used in docs generation mode.
+// In server mode, the search result is built on server side.
+function initSearchPage() {
+	var search = document.getElementById("search");
+	var indexURL = search.dataset.index;
+	if (indexURL == null) {
+		return;
+	}
+
+	var query = new URLSearchParams(window.location.search).get("q");
+	if (query == null) {
+		return;
+	}
+	search.querySelector("input[name='q']").value = query;
+	var words = query.toLowerCase().split(/\s+/).filter(function(w) {
+		return w.length > 0;
+	});
+	if (words.length == 0) {
+		return;
+	}
+
+	var isSubsequence = function(s, sub) {
+		var k = 0;
+		for (var i = 0; i < s.length && k < sub.length; i++) {
+			if (s[i] == sub[k]) {
+				k++;
+			}
+		}
+		return k == sub.length;
+	};
+	var nameMatchScore = function(name, word) {
+		if (name == word) {
+			return 1000;
+		}
+		if (name.indexOf(word) == 0) {
+			return 600;
+		}
+		if (name.indexOf(word) > 0) {
+			return 300;
+		}
+		if (word.length >= 4 && isSubsequence(name, word)) { // minFuzzyMatchWordLength
+			return 100;
+		}
+		return 0;
+	};
+	var itemScore = function(item) {
+		var pkgName = item.Package.substr(item.Package.lastIndexOf("/")+1);
+		var keys;
+		if (item.Kind == "package") {
+			keys = [item.Package, pkgName];
+		} else {
+			keys = [item.Name, item.Name.substr(item.Name.lastIndexOf(".")+1), pkgName + "." + item.Name];
+		}
+		keys = keys.map(function(k) {
+			return k.toLowerCase();
+		});
+		var doc = item.Summary.toLowerCase();
+		var score = 0;
+		for (var i = 0; i < words.length; i++) {
+			var best = 0;
+			for (var k = 0; k < keys.length; k++) {
+				best = Math.max(best, nameMatchScore(keys[k], words[i]));
+			}
+			if (doc.indexOf(words[i]) >= 0) {
+				best += 50;
+			}
+			if (best == 0) {
+				return 0;
+			}
+			score += best;
+		}
+		score += Math.min(Math.floor(item.Popularity / 10), 200);
+		if (item.Exported) {
+			score += 10;
+		}
+		return score;
+	};
+
+	var render = function(items) {
+		var groups = {};
+		items.forEach(function(item) {
+			var score = itemScore(item);
+			if (score == 0) {
+				return;
+			}
+			item.Score = score;
+			if (groups[item.Kind] == null) {
+				groups[item.Kind] = [];
+			}
+			groups[item.Kind].push(item);
+		});
+
+		var results = document.getElementById("search-results");
+		["package", "type", "function", "method", "field", "variable", "constant"].forEach(function(kind) {
+			var group = groups[kind];
+			if (group == null) {
+				return;
+			}
+			group.sort(function(a, b) {
+				if (a.Score != b.Score) {
+					return b.Score - a.Score;
+				}
+				if (a.Name != b.Name) {
+					return a.Name < b.Name ? -1 : 1;
+				}
+				return a.Package < b.Package ? -1 : 1;
+			});
+			group = group.slice(0, 100);
+
+			var pre = document.createElement("pre");
+			var code = document.createElement("code");
+			var title = document.createElement("span");
+			title.className = "title";
+			title.textContent = document.getElementById("search-kind-" + kind).textContent + " (" + group.length + ")";
+			code.appendChild(title);
+			group.forEach(function(item) {
+				code.appendChild(document.createTextNode("\n\t" + (kind == "package" ? "" : item.Package + ".")));
+				var a = document.createElement("a");
+				a.href = item.Href;
+				a.textContent = item.Name;
+				code.appendChild(a);
+				if (item.Summary != "") {
+					var summary = document.createElement("span");
+					summary.className = "search-summary";
+					summary.textContent = " - " + item.Summary;
+					code.appendChild(summary);
+				}
+			});
+			pre.appendChild(code);
+			results.appendChild(pre);
+		});
+	};
+
+	fetch(indexURL).then(function(response) {
+		return response.json();
+	}).then(render);
 }
 
 function initPackageDetailsPage() {
@@ -447,6 +718,17 @@ function autoExpandForPackageDetailsPageByPageAnchor() {
 		if (newHash.indexOf(prefix) != 0) {
 			return;
 		}
+		// Expand the folding blocks containing the anchor,
+		// such as the field and method lists of a type.
+		for (var e = div.parentElement; e != null; e = e.parentElement) {
+			var label = e.previousElementSibling;
+			if (label != null && label.tagName == "LABEL" && e.className.indexOf("fold-") == 0) {
+				var fold = document.getElementById(label.htmlFor);
+				if (fold != null) {
+					fold.checked = true;
+				}
+			}
+		}
 		var checkbox = document.getElementById(newHash.substr(prefix.length)+"-fold-content");
 		if (checkbox == null) {
 			return;
@@ -22,6 +22,7 @@ func (ds *docServer) methodImplementationPage(w http.ResponseWriter, r *http.Req
 
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
 
 	if ds.phase < Phase_Analyzed {
 		w.WriteHeader(http.StatusTooEarly)
@@ -49,6 +50,7 @@ func (ds *docServer) methodImplementationPage(w http.ResponseWriter, r *http.Req
 	pageKey := pageCacheKey{
 		resType: ResTypeImplementation,
 		res:     [...]string{pkgPath, typeName},
+		options: ds.pageCacheOptions(),
 	}
 	data, ok := ds.cachedPage(pageKey)
 	if !ok {
@@ -59,84 +61,71 @@ func (ds *docServer) methodImplementationPage(w http.ResponseWriter, r *http.Req
 			return
 		}
 
-		data = ds.buildImplementationPage(w, result)
+		data, err = ds.buildImplementationPage(w, result)
+		if err != nil {
+			writePageBuildError(w, err)
+			return
+		}
 		ds.cachePage(pageKey, data)
 	}
 	w.Write(data)
 }
 
-func (ds *docServer) buildImplementationPage(w http.ResponseWriter, result *MethodImplementationResult) []byte {
+func (ds *docServer) buildImplementationPage(w http.ResponseWriter, result *MethodImplementationResult) ([]byte, error) {
 	// some methods are born by embedding other types.
 	// Use the same design for local id: click such methods to highlight all same-origin ones.
 
 	title := ds.currentTranslation.Text_MethodImplementations() + ds.currentTranslation.Text_Colon(false) + result.Package.Path + "." + result.TypeName.Name()
 	page := NewHtmlPage(goldsVersion, title, ds.currentTheme, ds.currentTranslation, createPagePathInfo2(ResTypeImplementation, result.Package.Path, ".", result.TypeName.Name()))
 
-	fmt.Fprintf(page, `<pre><code><span style="font-size:x-large;">type <a href="%s">%s</a>.`,
-		buildPageHref(page.PathInfo, createPagePathInfo1(ResTypePackage, result.Package.Path), nil, ""),
-		result.Package.Path,
-	)
-	page.WriteString("<b>")
-	//writeSrouceCodeLineLink(page, result.TypeName.Package(), result.TypeName.Position(), result.TypeName.Name(), "")
-	ds.writeResourceIndexHTML(page, result.TypeName.Package(), result.TypeName, false, false, false)
-	page.WriteString(`</b></span><span style="font-size:large;">`)
-	writeKindText(page, result.TypeName.Denoting.TT)
-	page.WriteString("</span>\n")
-
-	nonImplementingMethodCountText := ""
-	if !result.IsInterface {
-		nonImplementingMethodCountText = page.Translation().Text_NumMethodsImplementingNothing(int(result.NonImplementingMethodCount))
-	}
-
-	fmt.Fprintf(page, `
-<code><span class="title">%s<span class="title-stat"><i>%s</i></span></span>
-`,
-		page.Translation().Text_MethodImplementations(),
-		nonImplementingMethodCountText,
-	)
-
-	for _, method := range result.Methods {
-		methodName := method.Method.Name()
-		dotMStyle := DotMStyle_Unexported
-		if token.IsExported(methodName) {
-			dotMStyle = DotMStyle_Exported
-		}
-		page.WriteString("\n")
-		anchorName := methodName
-		isExported := !token.IsExported(methodName)
-		if isExported {
-			anchorName = method.Method.Package().Path + "." + methodName
-		}
-		fmt.Fprintf(page, `<div class="anchor" id="name-%s">`, anchorName)
-		page.WriteByte('\t')
-		// ToDo: need to record which type the method is declared for.
-		//       For some rare cases, two same unexported methods from two different packages ...
-		//
-		if buildIdUsesPages {
-			buildPageHref(page.PathInfo, createPagePathInfo3(ResTypeReference, result.Package.Path, "..", result.TypeName.Name(), method.Method.Name()), page, method.Method.Name())
-			ds.writeMethodType(page, result.Package, method.Method, nil)
-		} else {
-			ds.writeMethodForListing(page, result.Package, method.Method, nil, false, false)
-		}
-		for _, imp := range method.Implementations {
-			page.WriteString("\n\t\t")
-			if result.IsInterface {
-				ds.writeTypeForListing(page, imp.Receiver, result.Package, "", dotMStyle, nil)
+	writeMethods := func() {
+		for _, method := range result.Methods {
+			methodName := method.Method.Name()
+			dotMStyle := DotMStyle_Unexported
+			if token.IsExported(methodName) {
+				dotMStyle = DotMStyle_Exported
+			}
+			page.WriteString("\n")
+			anchorName := methodName
+			isExported := !token.IsExported(methodName)
+			if isExported {
+				anchorName = method.Method.Package().Path + "." + methodName
+			}
+			fmt.Fprintf(page, `<div class="anchor" id="name-%s">`, anchorName)
+			page.WriteByte('\t')
+			// ToDo: need to record which type the method is declared for.
+			//       For some rare cases, two same unexported methods from two different packages ...
+			//
+			if buildIdUsesPages {
+				buildPageHref(page.PathInfo, createPagePathInfo3(ResTypeReference, result.Package.Path, "..", result.TypeName.Name(), method.Method.Name()), page, method.Method.Name())
+				ds.writeMethodType(page, result.Package, method.Method, nil)
 			} else {
-				ds.writeTypeForListing(page, imp.Receiver, result.Package, result.TypeName.Name(), dotMStyle, nil)
+				ds.writeMethodForListing(page, result.Package, method.Method, nil, false, false)
 			}
-			page.WriteByte('.')
-			ds.WriteEmbeddingChain(page, imp.Method.EmbeddingChain)
-			//writeSrouceCodeLineLink(page, imp.Method.Package(), imp.Method.Position(), methodName, "b")
-			page.WriteString("<b>")
-			ds.writeMethodForListing(page, result.Package, imp.Method, nil, false, true)
-			page.WriteString("</b>")
+			for _, imp := range method.Implementations {
+				page.WriteString("\n\t\t")
+				if result.IsInterface {
+					ds.writeTypeForListing(page, imp.Receiver, result.Package, "", dotMStyle, nil)
+				} else {
+					ds.writeTypeForListing(page, imp.Receiver, result.Package, result.TypeName.Name(), dotMStyle, nil)
+				}
+				page.WriteByte('.')
+				ds.WriteEmbeddingChain(page, imp.Method.EmbeddingChain)
+				//writeSrouceCodeLineLink(page, imp.Method.Package(), imp.Method.Position(), methodName, "b")
+				page.WriteString("<b>")
+				ds.writeMethodForListing(page, result.Package, imp.Method, nil, false, true)
+				page.WriteString("</b>")
+			}
+			page.WriteString("</div>")
 		}
-		page.WriteString("</div>")
 	}
 
-	page.WriteString("</code></pre>")
-	return page.Done(w)
+	if err := ds.writePageTemplate(page, "method-implementations.html", result, map[string]func(){
+		"methods": writeMethods,
+	}); err != nil {
+		return nil, err
+	}
+	return page.Done(w), nil
 }
 
 type MethodImplementationResult struct {
@@ -17,6 +17,7 @@ func (ds *docServer) overviewPage(w http.ResponseWriter, r *http.Request) {
 
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
 
 	//if ds.phase < Phase_Parsed {
 	if ds.phase < Phase_Analyzed {
@@ -33,18 +34,19 @@ func (ds *docServer) overviewPage(w http.ResponseWriter, r *http.Request) {
 			ds.cachedUpdateTip = ds.updateTip
 			//ds.theOverviewPage = nil
 
-			// clear possible cached pages
-			pageKey := pageCacheKey{
-				resType: ResTypeNone,
-				res:     "",
+			// clear possible cached pages (for all settings)
+			for pageKey := range ds.cachedPages {
+				if pageKey.resType == ResTypeNone && pageKey.res == "" {
+					ds.cachePage(pageKey, nil)
+				}
 			}
-			ds.cachePage(pageKey, nil)
 		}
 	}
 
 	pageKey := pageCacheKey{
 		resType: ResTypeNone,
 		res:     "",
+		options: ds.pageCacheOptions(),
 	}
 
 	data, ok := ds.cachedPage(pageKey)
@@ -68,6 +70,8 @@ func (ds *docServer) buildOverviewPage(w http.ResponseWriter, overview *Overview
 		ds.writeUpdateGoldBlock(page)
 	}
 
+	ds.writeSearchBlock(page)
+
 	if showStatistics {
 		ds.writeSimpleStatsBlock(page, &overview.Stats)
 	}
@@ -96,6 +100,18 @@ func (ds *docServer) buildOverviewPage(w http.ResponseWriter, overview *Overview
 	page.WriteString(`<label id="btn-depdepth" class="button">`)
 	page.WriteString(page.Translation().Text_SortByItem("depdepth"))
 	page.WriteString(`</label></span>`)
+	page.WriteString(`<span id="instability"> | `)
+	page.WriteString(`<label id="btn-instability" class="button">`)
+	page.WriteString(page.Translation().Text_SortByItem("instability"))
+	page.WriteString(`</label></span>`)
+	page.WriteString(`<span id="abstractness"> | `)
+	page.WriteString(`<label id="btn-abstractness" class="button">`)
+	page.WriteString(page.Translation().Text_SortByItem("abstractness"))
+	page.WriteString(`</label></span>`)
+	page.WriteString(`<span id="distance"> | `)
+	page.WriteString(`<label id="btn-distance" class="button">`)
+	page.WriteString(page.Translation().Text_SortByItem("distance"))
+	page.WriteString(`</label></span>`)
 	page.WriteString(`</span>`)
 	page.WriteString(page.Translation().Text_Parenthesis(true))
 	page.WriteString("</span>")
@@ -144,7 +160,8 @@ func (ds *docServer) writePackagesForListing(page *htmlPage, packages []*Package
 			}
 			fmt.Fprintf(page, `<div class="anchor pkg alphabet%s" id="pkg-%s"`, extraClass, pkg.Path)
 			if writeDataAttrs {
-				fmt.Fprintf(page, ` data-module="%s" data-loc="%d" data-importedbys="%d" data-depheight="%d" data-depdepth="%d"%s`, pkg.Module, pkg.LOC, pkg.NumImportedBys, pkg.DepHeight, pkg.DepDepth, main)
+				fmt.Fprintf(page, ` data-module="%s" data-loc="%d" data-importedbys="%d" data-depheight="%d" data-depdepth="%d"`, pkg.Module, pkg.LOC, pkg.NumImportedBys, pkg.DepHeight, pkg.DepDepth)
+				fmt.Fprintf(page, ` data-instability="%.2f" data-abstractness="%.2f" data-distance="%.2f"%s`, pkg.Instability, pkg.Abstractness, pkg.Distance, main)
 			}
 			page.WriteString(`>`)
 			defer page.WriteString(`</div>`)
@@ -214,6 +231,9 @@ func (ds *docServer) writePackagesForListing(page *htmlPage, packages []*Package
 			fmt.Fprintf(page, `<i class="codelines"> (%d)</i>`, pkg.LOC)
 			fmt.Fprintf(page, `<i class="depheight"> (%d)</i>`, pkg.DepHeight)
 			fmt.Fprintf(page, `<i class="depdepth"> (%d)</i>`, pkg.DepDepth)
+			fmt.Fprintf(page, `<i class="instability"> (%.2f = %d / %d)</i>`, pkg.Instability, pkg.Efferent, pkg.Afferent+pkg.Efferent)
+			fmt.Fprintf(page, `<i class="abstractness"> (%.2f)</i>`, pkg.Abstractness)
+			fmt.Fprintf(page, `<i class="distance"> (%.2f)</i>`, pkg.Distance)
 		}
 
 		const PackageSpace = "Package "
@@ -339,6 +359,13 @@ type PackageForListing struct {
 	DepDepth       int32 // The value mains how close to main pacakges.
 	LOC            int32
 
+	// Coupling metrics.
+	Afferent     int32   // Ca, the number of packages depending on this package
+	Efferent     int32   // Ce, the number of packages this package depends on
+	Instability  float64 // Ce / (Ca + Ce)
+	Abstractness float64 // ratio of exported interface types
+	Distance     float64 // |Abstractness + Instability - 1|
+
 	//IsStandard         bool
 	InWorkingDirectory bool
 }
@@ -372,6 +399,12 @@ func (ds *docServer) buildOverviewData() *Overview {
 			pkg.NumImportedBys = int32(numPkgs) - 1
 		}
 
+		pkg.Afferent = int32(p.AfferentCouplings())
+		pkg.Efferent = int32(p.EfferentCouplings())
+		pkg.Instability = p.Instability()
+		pkg.Abstractness = p.Abstractness()
+		pkg.Distance = p.DistanceFromMainSequence()
+
 		pkg.InWorkingDirectory = strings.HasPrefix(p.Directory, ds.initialWorkingDirectory)
 	}
 
@@ -11,6 +11,7 @@ func (ds *docServer) packageDependenciesPage(w http.ResponseWriter, r *http.Requ
 
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
 
 	if ds.phase < Phase_Analyzed {
 		w.WriteHeader(http.StatusTooEarly)
@@ -39,6 +40,7 @@ func (ds *docServer) packageDependenciesPage(w http.ResponseWriter, r *http.Requ
 	pageKey := pageCacheKey{
 		resType: ResTypeDependency,
 		res:     pkgPath,
+		options: ds.pageCacheOptions(),
 	}
 	data, ok := ds.cachedPage(pageKey)
 	if !ok {
@@ -50,7 +52,12 @@ func (ds *docServer) packageDependenciesPage(w http.ResponseWriter, r *http.Requ
 			return
 		}
 
-		data = ds.buildPackageDependenciesPage(w, depInfo)
+		var err error
+		data, err = ds.buildPackageDependenciesPage(w, depInfo)
+		if err != nil {
+			writePageBuildError(w, err)
+			return
+		}
 		ds.cachePage(pageKey, data)
 	}
 	w.Write(data)
@@ -115,34 +122,12 @@ func (ds *docServer) buildPackageDependenciesData(pkgPath string) *PackageDepend
 	return result
 }
 
-func (ds *docServer) buildPackageDependenciesPage(w http.ResponseWriter, depInfo *PackageDependencyInfo) []byte {
+func (ds *docServer) buildPackageDependenciesPage(w http.ResponseWriter, depInfo *PackageDependencyInfo) ([]byte, error) {
 	page := NewHtmlPage(goldsVersion, ds.currentTranslation.Text_DependencyRelations(depInfo.ImportPath), ds.currentTheme, ds.currentTranslation, createPagePathInfo1(ResTypeDependency, depInfo.ImportPath))
 
-	fmt.Fprintf(page, `
-<pre><code><span style="font-size:xx-large;">package <b>%s</b></span>
-`,
-		depInfo.Name,
-	)
-
-	fmt.Fprintf(page, `
-<span class="title">%s</span>
-	<a href="%s">%s</a>`,
-		page.Translation().Text_ImportPath(),
-		buildPageHref(page.PathInfo, createPagePathInfo1(ResTypePackage, depInfo.ImportPath), nil, ""),
-		depInfo.ImportPath,
-	)
-
-	page.WriteString("\n")
-
-	if len(depInfo.Imports) > 0 {
-		fmt.Fprint(page, "\n", `<span class="title">`, page.Translation().Text_Imports(), `</span>`)
-		ds.writePackagesForListing(page, depInfo.Imports, false)
-	}
-
-	if len(depInfo.ImportedBys) > 0 {
-		fmt.Fprint(page, "\n", `<span class="title" id="imported-by">`, page.Translation().Text_ImportedBy(), `</span>`)
-		ds.writePackagesForListing(page, depInfo.ImportedBys, false)
+	if err := ds.writePageTemplate(page, "package-dependencies.html", depInfo, nil); err != nil {
+		return nil, err
 	}
 
-	return page.Done(w)
+	return page.Done(w), nil
 }
@@ -27,6 +27,7 @@ func (ds *docServer) packageDetailsPage(w http.ResponseWriter, r *http.Request,
 
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
 
 	if ds.phase < Phase_Analyzed {
 		w.WriteHeader(http.StatusTooEarly)
@@ -36,11 +37,28 @@ func (ds *docServer) packageDetailsPage(w http.ResponseWriter, r *http.Request,
 
 	if genDocsMode {
 		pkgPath = deHashScope(pkgPath)
+
+		if !isGeneratedPackage(pkgPath) {
+			pkg := ds.analyzer.PackageByPath(pkgPath)
+			if pkg == nil {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, "Package (%s) not found", pkgPath)
+				return
+			}
+			data, err := ds.buildPackageStubPage(w, pkg)
+			if err != nil {
+				writePageBuildError(w, err)
+				return
+			}
+			w.Write(data)
+			return
+		}
 	}
 
 	pageKey := pageCacheKey{
 		resType: ResTypePackage,
 		res:     pkgPath,
+		options: ds.pageCacheOptions(),
 	}
 
 	data, ok := ds.cachedPage(pageKey)
@@ -53,299 +71,253 @@ func (ds *docServer) packageDetailsPage(w http.ResponseWriter, r *http.Request,
 			return
 		}
 
-		data = ds.buildPackageDetailsPage(w, details)
+		var err error
+		data, err = ds.buildPackageDetailsPage(w, details)
+		if err != nil {
+			writePageBuildError(w, err)
+			return
+		}
 		ds.cachePage(pageKey, data)
 	}
 	w.Write(data)
 }
 
-func (ds *docServer) buildPackageDetailsPage(w http.ResponseWriter, pkg *PackageDetails) []byte {
-	page := NewHtmlPage(goldsVersion, ds.currentTranslation.Text_Package(pkg.ImportPath), ds.currentTheme, ds.currentTranslation, createPagePathInfo1(ResTypePackage, pkg.ImportPath))
-
-	fmt.Fprintf(page, `
-<pre id="package-details"><code><span style="font-size:xx-large;">package <b>%s</b></span>
-`,
-		pkg.Name,
-	)
+// buildPackageStubPage builds a minimal page for a package
+// whose full docs are not generated (in docs generation mode).
+func (ds *docServer) buildPackageStubPage(w http.ResponseWriter, pkg *code.Package) ([]byte, error) {
+	page := NewHtmlPage(goldsVersion, ds.currentTranslation.Text_Package(pkg.Path), ds.currentTheme, ds.currentTranslation, createPagePathInfo1(ResTypePackage, pkg.Path))
 
-	godevLink := pkg.ImportPath
-	//if pkg.IsStandard {
-	godevLink = strings.TrimPrefix(pkg.ImportPath, "vendor/")
-	//}
-	fmt.Fprintf(page, `
-<span class="title">%s</span>
-	<a href="%s#pkg-%s">%s</a>%s`,
-		page.Translation().Text_ImportPath(),
-		buildPageHref(page.PathInfo, createPagePathInfo(ResTypeNone, ""), nil, ""),
-		pkg.ImportPath,
-		pkg.ImportPath,
-		page.Translation().Text_PackageDocsLinksOnOtherWebsites(godevLink, pkg.IsStandard),
-	)
+	details := &PackageDetails{
+		Package:    pkg,
+		IsStandard: ds.analyzer.IsStandardPackage(pkg),
+		Index:      pkg.Index,
+		Name:       pkg.PPkg.Name,
+		ImportPath: pkg.Path,
+	}
+	if err := ds.writePageTemplate(page, "package-stub.html", details, nil); err != nil {
+		return nil, err
+	}
 
-	isBuiltin := pkg.ImportPath == "builtin"
-	if !isBuiltin {
-		fmt.Fprintf(page, `
+	return page.Done(w), nil
+}
 
-<span class="title">%s</span>
-	%s`,
-			page.Translation().Text_DependencyRelations(""),
-			//page.Translation().Text_ImportStat(int(pkg.NumDeps), int(pkg.NumDepedBys), "/dep:"+pkg.ImportPath),
-			page.Translation().Text_ImportStat(int(pkg.NumDeps), int(pkg.NumDepedBys), buildPageHref(page.PathInfo, createPagePathInfo1(ResTypeDependency, pkg.ImportPath), nil, "")),
-		)
-	}
-	page.WriteString("\n")
+func (ds *docServer) buildPackageDetailsPage(w http.ResponseWriter, pkg *PackageDetails) ([]byte, error) {
+	page := NewHtmlPage(goldsVersion, ds.currentTranslation.Text_Package(pkg.ImportPath), ds.currentTheme, ds.currentTranslation, createPagePathInfo1(ResTypePackage, pkg.ImportPath))
 
+	isBuiltin := pkg.ImportPath == "builtin"
 	var isMainPackage = pkg.Package.PPkg.Name == "main"
 
 	const classHiddenItem = "hidden"
 
-	if len(pkg.Files) > 0 {
-
-		writeFileTitle := func(info FileInfo) {
-			if info.MainPosition != nil && info.DocText != "" {
-				writeMainFunctionArrow(page, pkg.Package, *info.MainPosition)
-				writeSourceCodeDocLink(page, pkg.Package, info.Filename, info.DocStartLine, info.DocEndLine)
-			} else if info.MainPosition != nil {
-				writeMainFunctionArrow(page, pkg.Package, *info.MainPosition)
-				page.WriteString("  ")
-			} else if info.DocText != "" {
-				page.WriteString("  ")
-				writeSourceCodeDocLink(page, pkg.Package, info.Filename, info.DocStartLine, info.DocEndLine)
-			} else {
-				// ToDo: use MainPkgArrowCharCount and PkgDocArrowCharCount
-				page.WriteString("  ")
-				page.WriteString("  ")
-			}
-			writeSrouceCodeFileLink(page, pkg.Package, info.Filename)
+	writeFileTitle := func(info FileInfo) {
+		if info.MainPosition != nil && info.DocText != "" {
+			writeMainFunctionArrow(page, pkg.Package, *info.MainPosition)
+			writeSourceCodeDocLink(page, pkg.Package, info.Filename, info.DocStartLine, info.DocEndLine)
+		} else if info.MainPosition != nil {
+			writeMainFunctionArrow(page, pkg.Package, *info.MainPosition)
+			page.WriteString("  ")
+		} else if info.DocText != "" {
+			page.WriteString("  ")
+			writeSourceCodeDocLink(page, pkg.Package, info.Filename, info.DocStartLine, info.DocEndLine)
+		} else {
+			// ToDo: use MainPkgArrowCharCount and PkgDocArrowCharCount
+			page.WriteString("  ")
+			page.WriteString("  ")
 		}
+		writeSrouceCodeFileLink(page, pkg.Package, info.Filename)
+	}
 
-		func() {
-			page.WriteString("\n")
-			page.WriteString(`<div id="files">`)
-			defer page.WriteString("</div>")
-			fmt.Fprint(page, `<span class="title">`, page.Translation().Text_InvolvedFiles(len(pkg.Files)), `</span>`)
-
-			page.WriteString("\n")
+	writeFiles := func() {
+		//writeLeadingSpaces := func() {
+		//	page.WriteString("\n\t")
+		//	page.WriteString("  ")
+		//	page.WriteString("   ")
+		//	page.WriteString("   ")
+		//	page.WriteString("\t")
+		//}
+		//checked := ""
+		//if isMainPackage {
+		//	checked = " checked"
+		//}
+		for i, info := range pkg.Files {
+			page.WriteString("\n\t")
+			//if len(info.Resources) == 0 {
+			if info.DocText == "" {
+				page.WriteString(`<span class="nodocs">`)
+				writeFileTitle(info)
+				page.WriteString(`</span>`)
+				continue
+			}
 
-			//writeLeadingSpaces := func() {
-			//	page.WriteString("\n\t")
-			//	page.WriteString("  ")
-			//	page.WriteString("   ")
-			//	page.WriteString("   ")
-			//	page.WriteString("\t")
-			//}
-			//checked := ""
-			//if isMainPackage {
-			//	checked = " checked"
-			//}
-			for i, info := range pkg.Files {
-				page.WriteString("\n\t")
-				//if len(info.Resources) == 0 {
-				if info.DocText == "" {
-					page.WriteString(`<span class="nodocs">`)
+			fid := fmt.Sprintf("file-%d", i)
+			writeFoldingBlock(page, fid, "content", "items", true,
+				func() {
 					writeFileTitle(info)
-					page.WriteString(`</span>`)
-					continue
-				}
+				},
+				func() {
+					page.WriteString("\n")
+					ds.renderDocComment(page, pkg.Package, "\t\t", info.DocText)
 
-				fid := fmt.Sprintf("file-%d", i)
-				writeFoldingBlock(page, fid, "content", "items", true,
-					func() {
-						writeFileTitle(info)
-					},
-					func() {
+					if i < len(pkg.Files)-1 {
 						page.WriteString("\n")
-						ds.renderDocComment(page, pkg.Package, "\t\t", info.DocText)
+					}
+				},
+				//func() {
+				//	if info.HasHiddenRes {
+				//		writeLeadingSpaces()
+				//		fmt.Fprintf(page, `<input%[1]s type='checkbox' class="showhide2" id='%[2]s'><i><label for='%[2]s'>%[3]s</label></i>`,
+				//			checked, fid, page.Translation().Text_ListUnexportes())
+				//	}
+				//	for _, res := range info.Resources {
+				//		func() {
+				//			hidden := true
+				//			if res.Type != nil {
+				//				if res.Type.TypeName.Exported() {
+				//					hidden = false
+				//				}
+				//			} else if res.Value.Exported() {
+				//				hidden = false
+				//			}
+				//			hiddenClass := ""
+				//			if hidden {
+				//				hiddenClass = ` class="` + classHiddenItem + `"`
+				//			}
+				//			fmt.Fprintf(page, `<span%s>`, hiddenClass)
+				//			defer page.WriteString(`</span>`)
+				//			if hidden {
+				//				page.WriteString(`<i>`)
+				//				defer page.WriteString(`</i>`)
+				//			}
+				//			writeLeadingSpaces()
+				//			if res.Type != nil {
+				//				page.WriteString(" type ")
+				//				fmt.Fprintf(page, `<a href="#name-%s">%s</a>`, res.Type.TypeName.Name(), res.Type.TypeName.Name())
+				//				return
+				//			}
+				//
+				//			switch res.Value.(type) {
+				//			default:
+				//				log.Println("impossible")
+				//				return
+				//			case *code.Variable:
+				//				page.WriteString("  var ")
+				//			case *code.Constant:
+				//				page.WriteString("const ")
+				//			case *code.Function:
+				//				page.WriteString(" func ")
+				//			}
+				//
+				//			fmt.Fprintf(page, `<a href="#name-%s">%s</a>`, res.Value.Name(), res.Value.Name())
+				//		}()
+				//	}
+				//},
+			)
+		}
+	}
+
+	writeExamples := func() {
+		for i, ex := range pkg.Examples {
+			page.WriteString("\n\t")
+
+			fid := fmt.Sprintf("example-%d", i)
+			writeFoldingBlock(page, fid, "content", "items", false,
+				func() {
+					page.AsHTMLEscapeWriter().WriteString(ex.Name)
+				},
+				func() {
+					page.WriteString("\n")
+
+					// ToDo: need syntax hightlight writer.
+					//       It is best to merge the example code with main code
+					//       so that the exapmle code can be rendered as normal source code.
+					if ex.Play != nil {
+						format.Node(util.NewIndentWriter(
+							page.AsHTMLEscapeWriter(),
+							[]byte{'\t', '\t'}), pkg.ExampleFileSet, ex.Play)
+					} else {
+						format.Node(util.NewIndentWriter(
+							page.AsHTMLEscapeWriter(),
+							[]byte{'\t', ' ', ' '}), pkg.ExampleFileSet, ex.Code)
+					}
+					//if i < len(pkg.Examples)-1 {
+					//	page.WriteString("\n")
+					//}
+				},
+			)
+		}
+	}
 
-						if i < len(pkg.Files)-1 {
-							page.WriteString("\n")
-						}
-					},
-					//func() {
-					//	if info.HasHiddenRes {
-					//		writeLeadingSpaces()
-					//		fmt.Fprintf(page, `<input%[1]s type='checkbox' class="showhide2" id='%[2]s'><i><label for='%[2]s'>%[3]s</label></i>`,
-					//			checked, fid, page.Translation().Text_ListUnexportes())
-					//	}
-					//	for _, res := range info.Resources {
-					//		func() {
-					//			hidden := true
-					//			if res.Type != nil {
-					//				if res.Type.TypeName.Exported() {
-					//					hidden = false
-					//				}
-					//			} else if res.Value.Exported() {
-					//				hidden = false
-					//			}
-					//			hiddenClass := ""
-					//			if hidden {
-					//				hiddenClass = ` class="` + classHiddenItem + `"`
-					//			}
-					//			fmt.Fprintf(page, `<span%s>`, hiddenClass)
-					//			defer page.WriteString(`</span>`)
-					//			if hidden {
-					//				page.WriteString(`<i>`)
-					//				defer page.WriteString(`</i>`)
-					//			}
-					//			writeLeadingSpaces()
-					//			if res.Type != nil {
-					//				page.WriteString(" type ")
-					//				fmt.Fprintf(page, `<a href="#name-%s">%s</a>`, res.Type.TypeName.Name(), res.Type.TypeName.Name())
-					//				return
-					//			}
-					//
-					//			switch res.Value.(type) {
-					//			default:
-					//				log.Println("impossible")
-					//				return
-					//			case *code.Variable:
-					//				page.WriteString("  var ")
-					//			case *code.Constant:
-					//				page.WriteString("const ")
-					//			case *code.Function:
-					//				page.WriteString(" func ")
-					//			}
-					//
-					//			fmt.Fprintf(page, `<a href="#name-%s">%s</a>`, res.Value.Name(), res.Value.Name())
-					//		}()
-					//	}
-					//},
-				)
+	//var writePackageLevelValues = func(title, name string, values []code.ValueResource, numExporteds int) {
+	var writePackageLevelValues = func(name string, values []ResourceWithPosition, numExporteds int) {
+		for i, vwp := range values {
+			v := vwp.Value
+			if i == numExporteds {
+				page.WriteString("\t")
+				writeUnexportedResourcesHeader(page,
+					name, !isMainPackage, len(values)-numExporteds)
 			}
-		}()
-	}
 
-	if len(pkg.Examples) > 0 {
-		func() {
-			page.WriteString("\n")
-			page.WriteString(`<div id="examples">`)
-			defer page.WriteString("</div>")
-			fmt.Fprint(page, `<span class="title">`, page.Translation().Text_Examples(len(pkg.Examples)), `</span>`)
+			unexported := i >= numExporteds
 
-			page.WriteString("\n")
+			extraClass := ""
+			if unexported { // !v.Exported() {
+				extraClass = " " + classHiddenItem
+			}
 
-			for i, ex := range pkg.Examples {
-				page.WriteString("\n\t")
+			fmt.Fprintf(page, `<div class="anchor value-res%s" id="name-%s">`, extraClass, v.Name())
+			if writeDashAnchors {
+				writeDashAnchor(page, v)
+			}
+			if unexported {
+				page.WriteString("<i>")
+			}
+			page.WriteString("\t")
+
+			var writeFuncTypeParameters func()
+			//>> 1.18
+			if fv, ok := v.(*code.Function); ok {
+				writeFuncTypeParameters = ds.writeTypeParameterListCallbackForFunction(page, pkg.Package, fv)
+			}
+			//<<
 
-				fid := fmt.Sprintf("example-%d", i)
-				writeFoldingBlock(page, fid, "content", "items", false,
+			if doc := v.Documentation(); doc == "" && writeFuncTypeParameters == nil {
+				page.WriteString(`<span class="nodocs">`)
+				ds.writeResourceIndexHTML(page, pkg.Package, v, true, true, true)
+				page.WriteString(`</span>`)
+			} else {
+				writeFoldingBlock(page, v.Name(), "content", "docs", false,
 					func() {
-						page.AsHTMLEscapeWriter().WriteString(ex.Name)
+						ds.writeResourceIndexHTML(page, pkg.Package, v, true, true, true)
 					},
 					func() {
-						page.WriteString("\n")
+						if writeFuncTypeParameters != nil {
+							writeFuncTypeParameters()
+							page.WriteString("\n")
+						}
 
-						// ToDo: need syntax hightlight writer.
-						//       It is best to merge the example code with main code
-						//       so that the exapmle code can be rendered as normal source code.
-						if ex.Play != nil {
-							format.Node(util.NewIndentWriter(
-								page.AsHTMLEscapeWriter(),
-								[]byte{'\t', '\t'}), pkg.ExampleFileSet, ex.Play)
-						} else {
-							format.Node(util.NewIndentWriter(
-								page.AsHTMLEscapeWriter(),
-								[]byte{'\t', ' ', ' '}), pkg.ExampleFileSet, ex.Code)
+						if doc != "" {
+							page.WriteString("\n")
+							ds.renderDocComment(page, pkg.Package, "\t\t", doc)
+							page.WriteString("\n")
 						}
-						//if i < len(pkg.Examples)-1 {
-						//	page.WriteString("\n")
-						//}
+
+						page.WriteString("\n")
 					},
 				)
 			}
-			page.WriteString("\n")
-		}()
-	}
 
-	//var writePackageLevelValues = func(title, name string, values []code.ValueResource, numExporteds int) {
-	var writePackageLevelValues = func(title, name string, values []ResourceWithPosition, numExporteds int) {
-
-		page.WriteString("\n")
-
-		func() {
-			fmt.Fprintf(page, `<div id="exported-%s">`, name)
-			defer page.WriteString("</div>")
-
-			func() {
-				page.WriteString(`<span class="title">`)
-				defer page.WriteString(`</span>`)
-				page.WriteString(title)
-				page.WriteString(`<span class="title-stat"><i>`)
-				defer page.WriteString(`</i></span>`)
-				page.WriteString(page.Translation().Text_Parenthesis(false))
-				defer page.WriteString(page.Translation().Text_Parenthesis(true))
-				page.WriteString(page.Translation().Text_PackageLevelResourceSimpleStat(true, len(values), numExporteds, collectUnexporteds))
-			}()
-
-			page.WriteString("\n\n")
-
-			for i, vwp := range values {
-				v := vwp.Value
-				if i == numExporteds {
-					page.WriteString("\t")
-					writeUnexportedResourcesHeader(page,
-						name, !isMainPackage, len(values)-numExporteds)
-				}
-
-				unexported := i >= numExporteds
-
-				extraClass := ""
-				if unexported { // !v.Exported() {
-					extraClass = " " + classHiddenItem
-				}
-
-				fmt.Fprintf(page, `<div class="anchor value-res%s" id="name-%s">`, extraClass, v.Name())
-				if unexported {
-					page.WriteString("<i>")
-				}
-				page.WriteString("\t")
-
-				var writeFuncTypeParameters func()
-				//>> 1.18
-				if fv, ok := v.(*code.Function); ok {
-					writeFuncTypeParameters = ds.writeTypeParameterListCallbackForFunction(page, pkg.Package, fv)
-				}
-				//<<
-
-				if doc := v.Documentation(); doc == "" && writeFuncTypeParameters == nil {
-					page.WriteString(`<span class="nodocs">`)
-					ds.writeResourceIndexHTML(page, pkg.Package, v, true, true, true)
-					page.WriteString(`</span>`)
-				} else {
-					writeFoldingBlock(page, v.Name(), "content", "docs", false,
-						func() {
-							ds.writeResourceIndexHTML(page, pkg.Package, v, true, true, true)
-						},
-						func() {
-							if writeFuncTypeParameters != nil {
-								writeFuncTypeParameters()
-								page.WriteString("\n")
-							}
-
-							if doc != "" {
-								page.WriteString("\n")
-								ds.renderDocComment(page, pkg.Package, "\t\t", doc)
-								page.WriteString("\n")
-							}
-
-							page.WriteString("\n")
-						},
-					)
-				}
-
-				if unexported {
-					page.WriteString("</i>")
-				}
-				page.WriteString("</div>")
+			if unexported {
+				page.WriteString("</i>")
 			}
+			page.WriteString("</div>")
+		}
 
-			//if pkg.NumExportedValues == 0 {
-			//	page.WriteString(`<div id="novalues">`)
-			//	page.WriteString("\t")
-			//	page.WriteString(page.Translation().Text_NoExportedValues())
-			//	page.WriteString(`</div>`)
-			//}
-		}()
+		//if pkg.NumExportedValues == 0 {
+		//	page.WriteString(`<div id="novalues">`)
+		//	page.WriteString("\t")
+		//	page.WriteString(page.Translation().Text_NoExportedValues())
+		//	page.WriteString(`</div>`)
+		//}
 	}
 
 	var writeItemWrapper = func(exported bool) (f func()) {
@@ -374,393 +346,366 @@ func (ds *docServer) buildPackageDetailsPage(w http.ResponseWriter, pkg *Package
 		page.WriteString(stat)
 	}
 
-	if len(pkg.TypeNames) == 0 {
-		goto WriteFunctions
-	}
-
-	page.WriteString("\n")
-
-	page.WriteString(`<div id="exported-types">`)
-
-	func() {
-		page.WriteString(`<span class="title">`)
-		defer page.WriteString(`</span>`)
-		page.WriteString(page.Translation().Text_PackageLevelTypeNames())
-		page.WriteString(`<span class="title-stat"><i>`)
-		defer page.WriteString(`</i></span>`)
-		page.WriteString(page.Translation().Text_Parenthesis(false))
-		defer page.WriteString(page.Translation().Text_Parenthesis(true))
-		page.WriteString(page.Translation().Text_PackageLevelResourceSimpleStat(true, len(pkg.TypeNames), int(pkg.NumExportedTypeNames), collectUnexporteds))
-	}()
-
-	page.WriteString("\n\n")
+	writeTypeNames := func() {
+		for i, tdwp := range pkg.TypeNames {
+			td := tdwp.Type
+			if i == int(pkg.NumExportedTypeNames) {
+				page.WriteString("</div><div>")
+				page.WriteString("\t")
+				writeUnexportedResourcesHeader(page,
+					"typenames", !isMainPackage, len(pkg.TypeNames)-int(pkg.NumExportedTypeNames))
+			}
 
-	page.WriteString(`<div id="exported-types-buttons" class="js-on">`)
-	page.WriteString("\t/* ")
-	if collectUnexporteds {
-		page.WriteString(page.Translation().Text_SortBy("exporteds-types"))
-	} else {
-		page.WriteString(page.Translation().Text_SortBy(""))
-	}
-	page.WriteString(page.Translation().Text_Colon(false))
-	page.WriteString(`<label id="sort-types-by-alphabet" class="button">`)
-	page.WriteString(page.Translation().Text_SortByItem("alphabet"))
-	page.WriteString(`</label>`)
-	page.WriteString(" | ")
-	page.WriteString(`<label id="sort-types-by-popularity" class="button">`)
-	page.WriteString(page.Translation().Text_SortByItem("popularity"))
-	page.WriteString(`</label>`)
-	page.WriteString(" */</div>")
-
-	for i, tdwp := range pkg.TypeNames {
-		td := tdwp.Type
-		if i == int(pkg.NumExportedTypeNames) {
-			page.WriteString("</div><div>")
+			extraClass, typeIsExported := "", td.TypeName.Exported()
+			if !typeIsExported {
+				extraClass = " " + classHiddenItem
+			}
+			fmt.Fprintf(page, `<div class="anchor type-res%s" id="name-%s" data-popularity="%d">`, extraClass, td.TypeName.Name(), td.Popularity)
+			if writeDashAnchors {
+				writeDashAnchor(page, td.TypeName)
+			}
 			page.WriteString("\t")
-			writeUnexportedResourcesHeader(page,
-				"typenames", !isMainPackage, len(pkg.TypeNames)-int(pkg.NumExportedTypeNames))
-		}
 
-		extraClass, typeIsExported := "", td.TypeName.Exported()
-		if !typeIsExported {
-			extraClass = " " + classHiddenItem
-		}
-		fmt.Fprintf(page, `<div class="anchor type-res%s" id="name-%s" data-popularity="%d">`, extraClass, td.TypeName.Name(), td.Popularity)
-		page.WriteString("\t")
+			//>> 1.18
+			var writeTypeTypeParameters = ds.writeTypeParameterListCallbackForTypeName(page, pkg.Package, td.TypeName)
+			//<<
 
-		//>> 1.18
-		var writeTypeTypeParameters = ds.writeTypeParameterListCallbackForTypeName(page, pkg.Package, td.TypeName)
-		//<<
+			if doc := td.TypeName.Documentation(); doc == "" && writeTypeTypeParameters == nil && td.AllListsAreBlank {
+				page.WriteString(`<span class="nodocs">`)
+				ds.writeResourceIndexHTML(page, pkg.Package, td.TypeName, true, true, false)
+				page.WriteString(`</span>`)
+			} else {
+				writeFoldingBlock(page, td.TypeName.Name(), "content", "docs", false,
+					func() {
+						ds.writeResourceIndexHTML(page, pkg.Package, td.TypeName, true, true, false)
+					},
+					func() {
+						if writeTypeTypeParameters != nil {
+							writeTypeTypeParameters()
+							if doc != "" {
+								page.WriteString("\n")
+							}
+						}
 
-		if doc := td.TypeName.Documentation(); doc == "" && writeTypeTypeParameters == nil && td.AllListsAreBlank {
-			page.WriteString(`<span class="nodocs">`)
-			ds.writeResourceIndexHTML(page, pkg.Package, td.TypeName, true, true, false)
-			page.WriteString(`</span>`)
-		} else {
-			writeFoldingBlock(page, td.TypeName.Name(), "content", "docs", false,
-				func() {
-					ds.writeResourceIndexHTML(page, pkg.Package, td.TypeName, true, true, false)
-				},
-				func() {
-					if writeTypeTypeParameters != nil {
-						writeTypeTypeParameters()
 						if doc != "" {
 							page.WriteString("\n")
+							ds.renderDocComment(page, pkg.Package, "\t\t", doc)
 						}
-					}
 
-					if doc != "" {
-						page.WriteString("\n")
-						ds.renderDocComment(page, pkg.Package, "\t\t", doc)
-					}
+						// ToDo: for alias, if its denoting type is an exported named type, then stop here.
+						//       (might be not a good idea. 1. such cases are rare. 2. if they happen, it does need to list ...)
 
-					// ToDo: for alias, if its denoting type is an exported named type, then stop here.
-					//       (might be not a good idea. 1. such cases are rare. 2. if they happen, it does need to list ...)
-
-					page.WriteByte('\n')
-					hasLists := false
-					if count, numExporteds := len(td.Fields), int(td.NumExportedFields); count > 0 {
-						hasLists = true
-						page.WriteString("\n\t\t")
-						writeFoldingBlock(page, td.TypeName.Name(), "fields", "items", false,
-							func() {
-								writeItemHeader(
-									page.Translation().Text_Fields(),
-									page.Translation().Text_PackageLevelResourceSimpleStat(true, count, numExporteds, collectUnexporteds),
-								)
-							},
-							func() {
-								exported := true
-							ListFields:
-								for _, fld := range td.Fields {
-									if token.IsExported(fld.Name()) != exported {
-										continue
-									}
-									func() {
-										defer writeItemWrapper(exported)()
-
-										if fldDoc, fldComment := fld.Field.Documentation(), fld.Field.Comment(); fldDoc == "" && fldComment == "" {
-											page.WriteString(`<span class="nodocs">`)
-											ds.writeFieldForListing(page, pkg.Package, fld, td.TypeName)
-											page.WriteString(`</span>`)
-										} else {
-											writeFoldingBlock(page, td.TypeName.Name(), "field-"+fld.Name(), "docs", false,
-												func() {
-													ds.writeFieldForListing(page, pkg.Package, fld, td.TypeName)
-												},
-												func() {
-													if fldDoc != "" {
-														page.WriteString("\n")
-														ds.renderDocComment(page, pkg.Package, "\t\t\t\t", fldDoc)
-													}
-													if fldComment != "" {
-														page.WriteString("\n")
-														ds.renderDocComment(page, pkg.Package, "\t\t\t\t// ", fldComment)
-													}
-													page.WriteString("\n")
-												})
+						page.WriteByte('\n')
+						hasLists := false
+						if count, numExporteds := len(td.Fields), int(td.NumExportedFields); count > 0 {
+							hasLists = true
+							page.WriteString("\n\t\t")
+							writeFoldingBlock(page, td.TypeName.Name(), "fields", "items", false,
+								func() {
+									writeItemHeader(
+										page.Translation().Text_Fields(),
+										page.Translation().Text_PackageLevelResourceSimpleStat(true, count, numExporteds, collectUnexporteds),
+									)
+								},
+								func() {
+									exported := true
+								ListFields:
+									for _, fld := range td.Fields {
+										if token.IsExported(fld.Name()) != exported {
+											continue
 										}
-									}()
-								}
-
-								if exported {
-									if numUnexporteds := count - numExporteds; numUnexporteds > 0 {
-										page.WriteString("\n\t\t\t")
-										writeHiddenItemsHeader(page, td.TypeName.Name(), "fields", typeIsExported, numUnexporteds, true)
-										exported = false
-										goto ListFields
+										func() {
+											defer writeItemWrapper(exported)()
+
+											if writeDashAnchors {
+												writeDashSelectorAnchor(page, td.TypeName, fld.Selector)
+											}
+
+											if fldDoc, fldComment := fld.Field.Documentation(), fld.Field.Comment(); fldDoc == "" && fldComment == "" {
+												page.WriteString(`<span class="nodocs">`)
+												ds.writeFieldForListing(page, pkg.Package, fld, td.TypeName)
+												page.WriteString(`</span>`)
+											} else {
+												writeFoldingBlock(page, td.TypeName.Name(), "field-"+fld.Name(), "docs", false,
+													func() {
+														ds.writeFieldForListing(page, pkg.Package, fld, td.TypeName)
+													},
+													func() {
+														if fldDoc != "" {
+															page.WriteString("\n")
+															ds.renderDocComment(page, pkg.Package, "\t\t\t\t", fldDoc)
+														}
+														if fldComment != "" {
+															page.WriteString("\n")
+															ds.renderDocComment(page, pkg.Package, "\t\t\t\t// ", fldComment)
+														}
+														page.WriteString("\n")
+													})
+											}
+										}()
 									}
-								}
-							},
-						)
-					}
-					if count, numExporteds := len(td.Methods), int(td.NumExportedMethods); count > 0 {
-						hasLists = true
-						page.WriteString("\n\t\t")
-						writeFoldingBlock(page, td.TypeName.Name(), "methods", "items", isBuiltin,
-							func() {
-								writeItemHeader(
-									page.Translation().Text_Methods(),
-									page.Translation().Text_PackageLevelResourceSimpleStat(true, count, numExporteds, collectUnexporteds),
-								)
-							},
-							func() {
-								exported := true
-							ListMethods:
-								for _, mthd := range td.Methods {
-									if token.IsExported(mthd.Name()) != exported {
-										continue
+
+									if exported {
+										if numUnexporteds := count - numExporteds; numUnexporteds > 0 {
+											page.WriteString("\n\t\t\t")
+											writeHiddenItemsHeader(page, td.TypeName.Name(), "fields", typeIsExported, numUnexporteds, true)
+											exported = false
+											goto ListFields
+										}
 									}
-									func() {
-										defer writeItemWrapper(exported)()
-
-										if mthdDoc, mthdComment := mthd.Method.Documentation(), mthd.Method.Comment(); mthdDoc == "" && mthdComment == "" {
-											page.WriteString(`<span class="nodocs">`)
-											ds.writeMethodForListing(page, pkg.Package, mthd, td.TypeName, true, false)
-											page.WriteString(`</span>`)
-										} else {
-											writeFoldingBlock(page, td.TypeName.Name(), "method-"+mthd.Name(), "docs", false,
-												func() {
-													ds.writeMethodForListing(page, pkg.Package, mthd, td.TypeName, true, false)
-												},
-												func() {
-													if mthdDoc != "" {
-														page.WriteString("\n")
-														ds.renderDocComment(page, pkg.Package, "\t\t\t\t", mthdDoc)
-													}
-													if mthdComment != "" {
+								},
+							)
+						}
+						if count, numExporteds := len(td.Methods), int(td.NumExportedMethods); count > 0 {
+							hasLists = true
+							page.WriteString("\n\t\t")
+							writeFoldingBlock(page, td.TypeName.Name(), "methods", "items", isBuiltin,
+								func() {
+									writeItemHeader(
+										page.Translation().Text_Methods(),
+										page.Translation().Text_PackageLevelResourceSimpleStat(true, count, numExporteds, collectUnexporteds),
+									)
+								},
+								func() {
+									exported := true
+								ListMethods:
+									for _, mthd := range td.Methods {
+										if token.IsExported(mthd.Name()) != exported {
+											continue
+										}
+										func() {
+											defer writeItemWrapper(exported)()
+
+											if writeDashAnchors {
+												writeDashSelectorAnchor(page, td.TypeName, mthd)
+											}
+
+											if mthdDoc, mthdComment := mthd.Method.Documentation(), mthd.Method.Comment(); mthdDoc == "" && mthdComment == "" {
+												page.WriteString(`<span class="nodocs">`)
+												ds.writeMethodForListing(page, pkg.Package, mthd, td.TypeName, true, false)
+												page.WriteString(`</span>`)
+											} else {
+												writeFoldingBlock(page, td.TypeName.Name(), "method-"+mthd.Name(), "docs", false,
+													func() {
+														ds.writeMethodForListing(page, pkg.Package, mthd, td.TypeName, true, false)
+													},
+													func() {
+														if mthdDoc != "" {
+															page.WriteString("\n")
+															ds.renderDocComment(page, pkg.Package, "\t\t\t\t", mthdDoc)
+														}
+														if mthdComment != "" {
+															page.WriteString("\n")
+															ds.renderDocComment(page, pkg.Package, "\t\t\t\t// ", mthdComment)
+														}
 														page.WriteString("\n")
-														ds.renderDocComment(page, pkg.Package, "\t\t\t\t// ", mthdComment)
-													}
-													page.WriteString("\n")
-												},
-											)
+													},
+												)
+											}
+										}()
+									}
+
+									if exported {
+										if numUnexporteds := len(td.Methods) - numExporteds; numUnexporteds > 0 {
+											page.WriteString("\n\t\t\t")
+											writeHiddenItemsHeader(page, td.TypeName.Name(), "methods", typeIsExported, numUnexporteds, true)
+											exported = false
+											goto ListMethods
 										}
-									}()
-								}
-
-								if exported {
-									if numUnexporteds := len(td.Methods) - numExporteds; numUnexporteds > 0 {
-										page.WriteString("\n\t\t\t")
-										writeHiddenItemsHeader(page, td.TypeName.Name(), "methods", typeIsExported, numUnexporteds, true)
-										exported = false
-										goto ListMethods
 									}
-								}
-							},
-						)
-					}
-					if count, numExporteds := len(td.ImplementedBys), int(td.NumExportedImpedBys); count > 0 {
-						hasLists = true
-						page.WriteString("\n\t\t")
-						writeFoldingBlock(page, td.TypeName.Name(), "impledby", "items", false,
-							func() {
-								writeItemHeader(
-									page.Translation().Text_ImplementedBy(),
-									page.Translation().Text_PackageLevelResourceSimpleStat(false, count, numExporteds, collectUnexporteds),
-								)
-							},
-							func() {
-								exported := true
-							ListImpedBys:
-								for _, by := range td.ImplementedBys {
-									if by.BaseType.TypeName.Exported() != exported {
-										continue
+								},
+							)
+						}
+						if count, numExporteds := len(td.ImplementedBys), int(td.NumExportedImpedBys); count > 0 {
+							hasLists = true
+							page.WriteString("\n\t\t")
+							writeFoldingBlock(page, td.TypeName.Name(), "impledby", "items", false,
+								func() {
+									writeItemHeader(
+										page.Translation().Text_ImplementedBy(),
+										page.Translation().Text_PackageLevelResourceSimpleStat(false, count, numExporteds, collectUnexporteds),
+									)
+								},
+								func() {
+									exported := true
+								ListImpedBys:
+									for _, by := range td.ImplementedBys {
+										if by.BaseType.TypeName.Exported() != exported {
+											continue
+										}
+										func() {
+											defer writeItemWrapper(exported)()
+
+											ds.writeTypeForListing(page, by, pkg.Package, "", DotMStyle_NotShow, td.TypeName)
+											//if _, ok := by.TypeName.Denoting.TT.Underlying().(*types.Interface); ok {
+											if _, ok := by.BaseType.TT.Underlying().(*types.Interface); ok {
+												page.WriteString(" <i>(interface)</i>")
+											}
+										}()
 									}
-									func() {
-										defer writeItemWrapper(exported)()
 
-										ds.writeTypeForListing(page, by, pkg.Package, "", DotMStyle_NotShow, td.TypeName)
-										//if _, ok := by.TypeName.Denoting.TT.Underlying().(*types.Interface); ok {
-										if _, ok := by.BaseType.TT.Underlying().(*types.Interface); ok {
-											page.WriteString(" <i>(interface)</i>")
+									if exported {
+										if numUnexporteds := len(td.ImplementedBys) - numExporteds; numUnexporteds > 0 {
+											page.WriteString("\n\t\t\t")
+											writeHiddenItemsHeader(page, td.TypeName.Name(), "impedBys", typeIsExported, numUnexporteds, false)
+											exported = false
+											goto ListImpedBys
 										}
-									}()
-								}
-
-								if exported {
-									if numUnexporteds := len(td.ImplementedBys) - numExporteds; numUnexporteds > 0 {
-										page.WriteString("\n\t\t\t")
-										writeHiddenItemsHeader(page, td.TypeName.Name(), "impedBys", typeIsExported, numUnexporteds, false)
-										exported = false
-										goto ListImpedBys
 									}
-								}
-							},
-						)
-					}
-					if count, numExporteds := len(td.Implements), int(td.NumExportedImpls); count > 0 {
-						hasLists = true
-						page.WriteString("\n\t\t")
-						writeFoldingBlock(page, td.TypeName.Name(), "impls", "items", false,
-							func() {
-								writeItemHeader(
-									page.Translation().Text_Implements(),
-									page.Translation().Text_PackageLevelResourceSimpleStat(false, count, numExporteds, collectUnexporteds),
-								)
-							},
-							func() {
-								exported := true
-							ListImpls:
-								for _, impl := range td.Implements {
-									if impl.BaseType.TypeName.Exported() != exported {
-										continue
+								},
+							)
+						}
+						if count, numExporteds := len(td.Implements), int(td.NumExportedImpls); count > 0 {
+							hasLists = true
+							page.WriteString("\n\t\t")
+							writeFoldingBlock(page, td.TypeName.Name(), "impls", "items", false,
+								func() {
+									writeItemHeader(
+										page.Translation().Text_Implements(),
+										page.Translation().Text_PackageLevelResourceSimpleStat(false, count, numExporteds, collectUnexporteds),
+									)
+								},
+								func() {
+									exported := true
+								ListImpls:
+									for _, impl := range td.Implements {
+										if impl.BaseType.TypeName.Exported() != exported {
+											continue
+										}
+										func() {
+											defer writeItemWrapper(exported)()
+
+											ds.writeTypeForListing(page, impl, pkg.Package, td.TypeName.Name(), DotMStyle_NotShow, td.TypeName)
+										}()
 									}
-									func() {
-										defer writeItemWrapper(exported)()
-
-										ds.writeTypeForListing(page, impl, pkg.Package, td.TypeName.Name(), DotMStyle_NotShow, td.TypeName)
-									}()
-								}
-
-								if exported {
-									if numUnexporteds := len(td.Implements) - numExporteds; numUnexporteds > 0 {
-										page.WriteString("\n\t\t\t")
-										writeHiddenItemsHeader(page, td.TypeName.Name(), "impls", typeIsExported, numUnexporteds, false)
-										exported = false
-										goto ListImpls
+
+									if exported {
+										if numUnexporteds := len(td.Implements) - numExporteds; numUnexporteds > 0 {
+											page.WriteString("\n\t\t\t")
+											writeHiddenItemsHeader(page, td.TypeName.Name(), "impls", typeIsExported, numUnexporteds, false)
+											exported = false
+											goto ListImpls
+										}
 									}
-								}
-							},
-						)
-					}
-					if count, numExporteds := len(td.AsOutputsOf), int(td.NumExportedAsOutputsOfs); count > 0 {
-						hasLists = true
-						page.WriteString("\n\t\t")
-						writeFoldingBlock(page, td.TypeName.Name(), "results", "items", false,
-							func() {
-								writeItemHeader(
-									page.Translation().Text_AsOutputsOf(),
-									page.Translation().Text_PackageLevelResourceSimpleStat(false, count, numExporteds, collectUnexporteds),
-								)
-							},
-							func() {
-								exported := true
-							ListAsOutputsOf:
-								for _, v := range td.AsOutputsOf {
-									if v.Exported() != exported {
-										continue
+								},
+							)
+						}
+						if count, numExporteds := len(td.AsOutputsOf), int(td.NumExportedAsOutputsOfs); count > 0 {
+							hasLists = true
+							page.WriteString("\n\t\t")
+							writeFoldingBlock(page, td.TypeName.Name(), "results", "items", false,
+								func() {
+									writeItemHeader(
+										page.Translation().Text_AsOutputsOf(),
+										page.Translation().Text_PackageLevelResourceSimpleStat(false, count, numExporteds, collectUnexporteds),
+									)
+								},
+								func() {
+									exported := true
+								ListAsOutputsOf:
+									for _, v := range td.AsOutputsOf {
+										if v.Exported() != exported {
+											continue
+										}
+										func() {
+											defer writeItemWrapper(exported)()
+
+											ds.writeValueForListing(page, v, pkg.Package, td.TypeName)
+										}()
 									}
-									func() {
-										defer writeItemWrapper(exported)()
-
-										ds.writeValueForListing(page, v, pkg.Package, td.TypeName)
-									}()
-								}
-
-								if exported {
-									if numUnexporteds := len(td.AsOutputsOf) - numExporteds; numUnexporteds > 0 {
-										page.WriteString("\n\t\t\t")
-										writeHiddenItemsHeader(page, td.TypeName.Name(), "inputofs", typeIsExported, numUnexporteds, false)
-										exported = false
-										goto ListAsOutputsOf
+
+									if exported {
+										if numUnexporteds := len(td.AsOutputsOf) - numExporteds; numUnexporteds > 0 {
+											page.WriteString("\n\t\t\t")
+											writeHiddenItemsHeader(page, td.TypeName.Name(), "inputofs", typeIsExported, numUnexporteds, false)
+											exported = false
+											goto ListAsOutputsOf
+										}
 									}
-								}
-							},
-						)
-					}
-					if count, numExporteds := len(td.AsInputsOf), int(td.NumExportedAsInputsOfs); count > 0 {
-						hasLists = true
-						page.WriteString("\n\t\t")
-						writeFoldingBlock(page, td.TypeName.Name(), "params", "items", false,
-							func() {
-								writeItemHeader(
-									page.Translation().Text_AsInputsOf(),
-									page.Translation().Text_PackageLevelResourceSimpleStat(false, count, numExporteds, collectUnexporteds),
-								)
-							},
-							func() {
-								exported := true
-							ListAsInputsOf:
-								for _, v := range td.AsInputsOf {
-									if v.Exported() != exported {
-										continue
+								},
+							)
+						}
+						if count, numExporteds := len(td.AsInputsOf), int(td.NumExportedAsInputsOfs); count > 0 {
+							hasLists = true
+							page.WriteString("\n\t\t")
+							writeFoldingBlock(page, td.TypeName.Name(), "params", "items", false,
+								func() {
+									writeItemHeader(
+										page.Translation().Text_AsInputsOf(),
+										page.Translation().Text_PackageLevelResourceSimpleStat(false, count, numExporteds, collectUnexporteds),
+									)
+								},
+								func() {
+									exported := true
+								ListAsInputsOf:
+									for _, v := range td.AsInputsOf {
+										if v.Exported() != exported {
+											continue
+										}
+										func() {
+											defer writeItemWrapper(exported)()
+
+											ds.writeValueForListing(page, v, pkg.Package, td.TypeName)
+										}()
 									}
-									func() {
-										defer writeItemWrapper(exported)()
-
-										ds.writeValueForListing(page, v, pkg.Package, td.TypeName)
-									}()
-								}
-
-								if exported {
-									if numUnexporteds := len(td.AsInputsOf) - numExporteds; numUnexporteds > 0 {
-										page.WriteString("\n\t\t\t")
-										writeHiddenItemsHeader(page, td.TypeName.Name(), "outputofs", typeIsExported, numUnexporteds, false)
-										exported = false
-										goto ListAsInputsOf
+
+									if exported {
+										if numUnexporteds := len(td.AsInputsOf) - numExporteds; numUnexporteds > 0 {
+											page.WriteString("\n\t\t\t")
+											writeHiddenItemsHeader(page, td.TypeName.Name(), "outputofs", typeIsExported, numUnexporteds, false)
+											exported = false
+											goto ListAsInputsOf
+										}
 									}
-								}
-							},
-						)
-					}
-					if count, numExporteds := len(td.Values), int(td.NumExportedValues); count > 0 {
-						hasLists = true
-						page.WriteString("\n\t\t")
-						writeFoldingBlock(page, td.TypeName.Name(), "values", "items", false,
-							func() {
-								writeItemHeader(
-									page.Translation().Text_AsTypesOf(),
-									page.Translation().Text_PackageLevelResourceSimpleStat(true, count, numExporteds, collectUnexporteds),
-								)
-							},
-							func() {
-								exported := true
-							ListAsTypesOf:
-								for _, v := range td.Values {
-									if v.Exported() != exported {
-										continue
+								},
+							)
+						}
+						if count, numExporteds := len(td.Values), int(td.NumExportedValues); count > 0 {
+							hasLists = true
+							page.WriteString("\n\t\t")
+							writeFoldingBlock(page, td.TypeName.Name(), "values", "items", false,
+								func() {
+									writeItemHeader(
+										page.Translation().Text_AsTypesOf(),
+										page.Translation().Text_PackageLevelResourceSimpleStat(true, count, numExporteds, collectUnexporteds),
+									)
+								},
+								func() {
+									exported := true
+								ListAsTypesOf:
+									for _, v := range td.Values {
+										if v.Exported() != exported {
+											continue
+										}
+										func() {
+											defer writeItemWrapper(exported)()
+
+											ds.writeValueForListing(page, v, pkg.Package, td.TypeName)
+										}()
 									}
-									func() {
-										defer writeItemWrapper(exported)()
-
-										ds.writeValueForListing(page, v, pkg.Package, td.TypeName)
-									}()
-								}
-
-								if exported {
-									if numUnexporteds := len(td.Values) - numExporteds; numUnexporteds > 0 {
-										page.WriteString("\n\t\t\t")
-										writeHiddenItemsHeader(page, td.TypeName.Name(), "values", typeIsExported, numUnexporteds, true)
-										exported = false
-										goto ListAsTypesOf
+
+									if exported {
+										if numUnexporteds := len(td.Values) - numExporteds; numUnexporteds > 0 {
+											page.WriteString("\n\t\t\t")
+											writeHiddenItemsHeader(page, td.TypeName.Name(), "values", typeIsExported, numUnexporteds, true)
+											exported = false
+											goto ListAsTypesOf
+										}
 									}
-								}
-							},
-						)
-					}
-					page.WriteByte('\n')
-					if hasLists {
+								},
+							)
+						}
 						page.WriteByte('\n')
-					}
-				})
-		}
+						if hasLists {
+							page.WriteByte('\n')
+						}
+					})
+			}
 
-		page.WriteString("</div>")
+			page.WriteString("</div>")
+		}
 	}
 
-	page.WriteString("</div>")
-
 	//if pkg.NumExportedTypes == 0 {
 	//	page.WriteString(`<div id="notypesnames">`)
 	//	page.WriteString("\t")
@@ -768,48 +713,17 @@ func (ds *docServer) buildPackageDetailsPage(w http.ResponseWriter, pkg *Package
 	//	page.WriteString(`</div>`)
 	//}
 
-WriteFunctions:
-
-	if len(pkg.Functions) == 0 {
-		goto WriteVariables
-	}
-
-	writePackageLevelValues(
-		page.Translation().Text_PackageLevelFunctions(),
-		"functions",
-		pkg.Functions,
-		int(pkg.NumExportedFunctions),
-	)
-
-WriteVariables:
-
-	if len(pkg.Variables) == 0 {
-		goto WriteConstants
-	}
-
-	writePackageLevelValues(
-		page.Translation().Text_PackageLevelVariables(),
-		"variables",
-		pkg.Variables,
-		int(pkg.NumExportedVariables),
-	)
-
-WriteConstants:
-
-	if len(pkg.Constants) == 0 {
-		goto Done
-	}
-
-	writePackageLevelValues(
-		page.Translation().Text_PackageLevelConstants(),
-		"constants",
-		pkg.Constants,
-		int(pkg.NumExportedConstants),
-	)
-
-Done:
-	page.WriteString("</code></pre>")
-	return page.Done(w)
+	if err := ds.writePageTemplate(page, "package-details.html", pkg, map[string]func(){
+		"files":     writeFiles,
+		"examples":  writeExamples,
+		"types":     writeTypeNames,
+		"functions": func() { writePackageLevelValues("functions", pkg.Functions, int(pkg.NumExportedFunctions)) },
+		"variables": func() { writePackageLevelValues("variables", pkg.Variables, int(pkg.NumExportedVariables)) },
+		"constants": func() { writePackageLevelValues("constants", pkg.Constants, int(pkg.NumExportedConstants)) },
+	}); err != nil {
+		return nil, err
+	}
+	return page.Done(w), nil
 }
 
 type ResourceWithPosition struct {
@@ -3158,178 +3072,181 @@ func init() {
 func (ds *docServer) renderDocComment(page *htmlPage, currentPkg *code.Package, ident, mdDoc string) {
 	var makeURL func(string) string
 	if renderDocLinks {
-		makeURL = func(bracketedText string) (r string) {
-			//defer func() {
-			//	if r != "" {
-			//		println("===", currentPkg.Path, bracketedText, r)
-			//	}
-			//}()
-
-			checkResNameRoughly := func(resName string) bool {
-				if len(resName) == 0 {
-					return false
-				}
-				var k = 0
-				if resName[0] <= 128 {
-					if asciiCharTypes[resName[0]] != 2 {
-						return false
-					} else {
-						k = 1
-					}
-				}
-				for i, r := range resName[k:] {
-					if r >= 128 {
-						continue
-					}
-					if asciiCharTypes[r] == 0 {
-						return false
-					}
-					if i >= 16 {
-						break
-					}
-				}
-				return true
-			}
-
-			bracketedText = strings.TrimLeft(bracketedText, "*")
-
-			tokens := strings.Split(bracketedText, ".")
-			if len(tokens) == 0 {
+		makeURL = func(bracketedText string) string {
+			pkg, res, sel := ds.resolveDocLink(currentPkg, bracketedText)
+			switch {
+			case pkg == nil:
 				return ""
-			}
-
-			buildPkgResLink := func(pkg *code.Package, res code.Resource) string {
+			case sel != nil:
+				return buildSrouceCodeLineLink(page.PathInfo, ds.analyzer, pkg, sel.Position())
+			case res != nil:
 				if res.Exported() || collectUnexporteds {
 					return buildPageHref(page.PathInfo, createPagePathInfo1(ResTypePackage, pkg.Path), nil, "", "name-", res.Name())
 				}
-
 				return buildSrouceCodeLineLink(page.PathInfo, ds.analyzer, pkg, res.Position())
+			default:
+				return buildPageHref(page.PathInfo, createPagePathInfo1(ResTypePackage, pkg.Path), nil, "")
 			}
+		}
+	}
+	page.WriteString(`<span class="md-text">`)
+	ds.docRenderer.Render(page, mdDoc, ident, true, makeURL)
+	page.WriteString(`</span>`)
+}
 
-			if len(tokens) == 1 {
-				if !checkResNameRoughly(tokens[0]) {
-					return ""
-				}
+// resolveDocLink finds what a bracketed text in a doc comment of
+// currentPkg links to. A nil pkg means nothing is found.
+// For a package link, both res and sel are nil.
+// For a selector link, sel is not nil, res is the type name
+// and pkg is the package in which the selector is declared.
+func (ds *docServer) resolveDocLink(currentPkg *code.Package, bracketedText string) (pkg *code.Package, res code.Resource, sel *code.Selector) {
+	checkResNameRoughly := func(resName string) bool {
+		if len(resName) == 0 {
+			return false
+		}
+		var k = 0
+		if resName[0] <= 128 {
+			if asciiCharTypes[resName[0]] != 2 {
+				return false
+			} else {
+				k = 1
+			}
+		}
+		for i, r := range resName[k:] {
+			if r >= 128 {
+				continue
+			}
+			if asciiCharTypes[r] == 0 {
+				return false
+			}
+			if i >= 16 {
+				break
+			}
+		}
+		return true
+	}
 
-				res := currentPkg.SearchResourceByName(tokens[0])
-				if res != nil {
-					return buildPkgResLink(currentPkg, res)
-				}
+	bracketedText = strings.TrimLeft(bracketedText, "*")
 
-				var pkg = ds.analyzer.PackageByPath(tokens[0])
-				if pkg == nil {
-					pkg = currentPkg.Module().PackageByPath(currentPkg.Path + "/" + tokens[0])
-				}
-				if pkg == nil {
-					i := strings.LastIndexByte(currentPkg.Path, '/')
-					if i > 0 {
-						pkg = currentPkg.Module().PackageByPath(currentPkg.Path[:i+1] + tokens[0])
-					}
-				}
-				if pkg == nil {
-					pkg = currentPkg.Module().PackageByPath(currentPkg.ModulePath() + "/" + tokens[0])
-				}
-				if pkg == currentPkg {
-					pkg = nil
-				}
+	tokens := strings.Split(bracketedText, ".")
+	if len(tokens) == 0 {
+		return nil, nil, nil
+	}
 
-				if pkg != nil {
-					return buildPageHref(page.PathInfo, createPagePathInfo1(ResTypePackage, pkg.Path), nil, "")
-				}
+	if len(tokens) == 1 {
+		if !checkResNameRoughly(tokens[0]) {
+			return nil, nil, nil
+		}
 
-				return ""
-			}
+		res := currentPkg.SearchResourceByName(tokens[0])
+		if res != nil {
+			return currentPkg, res, nil
+		}
 
-			if !checkResNameRoughly(tokens[1]) {
-				return ""
+		var pkg = ds.analyzer.PackageByPath(tokens[0])
+		if pkg == nil {
+			pkg = currentPkg.Module().PackageByPath(currentPkg.Path + "/" + tokens[0])
+		}
+		if pkg == nil {
+			i := strings.LastIndexByte(currentPkg.Path, '/')
+			if i > 0 {
+				pkg = currentPkg.Module().PackageByPath(currentPkg.Path[:i+1] + tokens[0])
 			}
+		}
+		if pkg == nil {
+			pkg = currentPkg.Module().PackageByPath(currentPkg.ModulePath() + "/" + tokens[0])
+		}
+		if pkg == currentPkg {
+			pkg = nil
+		}
 
-			var trySearchRes = func(pkgPath string) (*code.Package, code.Resource) {
-				pkg := currentPkg.Module().PackageByPath(pkgPath)
-				if pkg == nil {
-					return nil, nil
-				}
-				res := pkg.SearchResourceByName(tokens[1])
-				if res == nil {
-					return nil, nil
-				}
-				return pkg, res
-			}
+		return pkg, nil, nil
+	}
 
-			var res code.Resource
-			var pkg = ds.analyzer.StandardPackage(tokens[0])
-			if pkg != nil {
-				res = pkg.SearchResourceByName(tokens[1])
-				if res == nil {
-					pkg = nil
-				}
-			}
-			if res == nil {
-				pkg, res = trySearchRes(currentPkg.Path + "/" + tokens[0])
-			}
-			if res == nil {
-				i := strings.LastIndexByte(currentPkg.Path, '/')
-				if i > 0 {
-					pkg, res = trySearchRes(currentPkg.Path[:i+1] + tokens[0])
-				}
-			}
-			if res == nil {
-				pkg, res = trySearchRes(currentPkg.ModulePath() + "/" + tokens[0])
-			}
+	if !checkResNameRoughly(tokens[1]) {
+		return nil, nil, nil
+	}
 
-			if len(tokens) == 2 {
-				if res != nil {
-					return buildPkgResLink(pkg, res)
-				}
+	var trySearchRes = func(pkgPath string) (*code.Package, code.Resource) {
+		pkg := currentPkg.Module().PackageByPath(pkgPath)
+		if pkg == nil {
+			return nil, nil
+		}
+		res := pkg.SearchResourceByName(tokens[1])
+		if res == nil {
+			return nil, nil
+		}
+		return pkg, res
+	}
 
-				tn := currentPkg.TypeNameByName(tokens[0])
-				if tn == nil {
-					return ""
-				}
+	pkg = ds.analyzer.StandardPackage(tokens[0])
+	if pkg != nil {
+		res = pkg.SearchResourceByName(tokens[1])
+		if res == nil {
+			pkg = nil
+		}
+	}
+	if res == nil {
+		pkg, res = trySearchRes(currentPkg.Path + "/" + tokens[0])
+	}
+	if res == nil {
+		i := strings.LastIndexByte(currentPkg.Path, '/')
+		if i > 0 {
+			pkg, res = trySearchRes(currentPkg.Path[:i+1] + tokens[0])
+		}
+	}
+	if res == nil {
+		pkg, res = trySearchRes(currentPkg.ModulePath() + "/" + tokens[0])
+	}
 
-				sel := tn.Denoting.SelectorByName(tokens[1])
-				if sel == nil {
-					return ""
-				}
+	if len(tokens) == 2 {
+		if res != nil {
+			return pkg, res, nil
+		}
 
-				pkg = sel.Package()
-				if pkg == nil {
-					pkg = currentPkg
-				}
+		tn := currentPkg.TypeNameByName(tokens[0])
+		if tn == nil {
+			return nil, nil, nil
+		}
 
-				return buildSrouceCodeLineLink(page.PathInfo, ds.analyzer, pkg, sel.Position())
-			}
+		sel := tn.Denoting.SelectorByName(tokens[1])
+		if sel == nil {
+			return nil, nil, nil
+		}
 
-			// ToDo: more complex patter: StructType.Field.Field, pkg.StructType.Field.Field, ...
-			if len(tokens) > 3 {
-				return ""
-			}
+		pkg = sel.Package()
+		if pkg == nil {
+			pkg = currentPkg
+		}
 
-			// Now, only consider one case: pkg.Type.Selector
+		return pkg, tn, sel
+	}
 
-			if res == nil {
-				return ""
-			}
+	// ToDo: more complex patter: StructType.Field.Field, pkg.StructType.Field.Field, ...
+	if len(tokens) > 3 {
+		return nil, nil, nil
+	}
 
-			tn, ok := res.(*code.TypeName)
-			if !ok {
-				return ""
-			}
+	// Now, only consider one case: pkg.Type.Selector
 
-			sel := tn.Denoting.SelectorByName(tokens[2])
-			if sel == nil {
-				return ""
-			}
+	if res == nil {
+		return nil, nil, nil
+	}
 
-			pkg = sel.Package()
-			if pkg == nil {
-				pkg = currentPkg
-			}
+	tn, ok := res.(*code.TypeName)
+	if !ok {
+		return nil, nil, nil
+	}
 
-			return buildSrouceCodeLineLink(page.PathInfo, ds.analyzer, pkg, sel.Position())
-		}
+	sel = tn.Denoting.SelectorByName(tokens[2])
+	if sel == nil {
+		return nil, nil, nil
 	}
-	page.WriteString(`<span class="md-text">`)
-	ds.docRenderer.Render(page, mdDoc, ident, true, makeURL)
-	page.WriteString(`</span>`)
+
+	pkg = sel.Package()
+	if pkg == nil {
+		pkg = currentPkg
+	}
+
+	return pkg, tn, sel
 }
@@ -24,7 +24,11 @@ func (ds *docServer) pngFile(w http.ResponseWriter, r *http.Request, pngFilename
 	}
 	data, ok := ds.cachedPage(pageKey)
 	if !ok {
-		data = decodeBase64Data(pngFilename)
+		if pngFilename == customLogoFilename && logoResType == ResTypePNG {
+			data = logoData
+		} else {
+			data = decodeBase64Data(pngFilename)
+		}
 		ds.cachePage(pageKey, data)
 
 		// For docs generation.
@@ -0,0 +1,444 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go101.org/golds/code"
+)
+
+// The kinds of search items, in the listing order of result groups.
+var searchItemKinds = []string{
+	"package",
+	"type",
+	"function",
+	"method",
+	"field",
+	"variable",
+	"constant",
+}
+
+// Max number of items listed in a search result group.
+const maxSearchResultsPerKind = 100
+
+func (ds *docServer) searchPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
+
+	if ds.phase < Phase_Analyzed {
+		w.WriteHeader(http.StatusTooEarly)
+		ds.loadingPage(w, r)
+		return
+	}
+
+	// In docs generation mode, the query is handled by JavaScript
+	// with the generated search index file.
+	var query string
+	if !genDocsMode {
+		query = strings.TrimSpace(r.FormValue("q"))
+	}
+
+	if query == "" {
+		pageKey := pageCacheKey{
+			resType: ResTypeNone,
+			res:     "search",
+			options: ds.pageCacheOptions(),
+		}
+		data, ok := ds.cachedPage(pageKey)
+		if !ok {
+			data = ds.buildSearchPage(w, "", nil)
+			ds.cachePage(pageKey, data)
+		}
+		w.Write(data)
+		return
+	}
+
+	result := ds.search(query)
+	w.Write(ds.buildSearchPage(w, query, result))
+}
+
+func (ds *docServer) searchAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if ds.phase < Phase_Analyzed {
+		w.WriteHeader(http.StatusTooEarly)
+		fmt.Fprint(w, `{"error": "not ready"}`)
+		return
+	}
+
+	result := ds.search(strings.TrimSpace(r.FormValue("q")))
+	data, err := json.Marshal(result)
+	if err != nil {
+		fmt.Fprintf(w, `{"error": "%s"}`, err.Error())
+		return
+	}
+
+	w.Write(data)
+}
+
+// The index file used by the search page in docs generation mode.
+func (ds *docServer) searchIndexFile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if ds.phase < Phase_Analyzed {
+		w.WriteHeader(http.StatusTooEarly)
+		fmt.Fprint(w, `{"error": "not ready"}`)
+		return
+	}
+
+	pageKey := pageCacheKey{
+		resType: ResTypeAPI,
+		res:     "search-index",
+	}
+	data, ok := ds.cachedPage(pageKey)
+	if !ok {
+		page := NewHtmlPage(goldsVersion, "", nil, ds.currentTranslation, createPagePathInfo(ResTypeAPI, "search-index"))
+
+		var err error
+		data, err = json.Marshal(ds.searchIndex())
+		if err != nil {
+			panic("marshal search index error: " + err.Error())
+		}
+		ds.cachePage(pageKey, data)
+
+		page.Write(data)
+		_ = page.Done(w)
+	}
+	w.Write(data)
+}
+
+func (ds *docServer) buildSearchPage(w http.ResponseWriter, query string, result *SearchResult) []byte {
+	page := NewHtmlPage(goldsVersion, ds.currentTranslation.Text_Search(), ds.currentTheme, ds.currentTranslation, createPagePathInfo(ResTypeNone, "search"))
+	fmt.Fprintf(page, `
+<pre><code><span style="font-size:xx-large;">%s</span></code></pre>
+`,
+		page.Translation().Text_Search(),
+	)
+
+	page.WriteString(`<pre id="search"`)
+	if genDocsMode {
+		fmt.Fprintf(page, ` data-index="%s"`, buildPageHref(page.PathInfo, createPagePathInfo(ResTypeAPI, "search-index"), nil, ""))
+	}
+	page.WriteString(`>`)
+	fmt.Fprintf(page, `<form action="%s" method="get">`, buildPageHref(page.PathInfo, createPagePathInfo(ResTypeNone, "search"), nil, ""))
+	page.WriteString(`<input type="text" name="q" size="48" value="`)
+	page.AsHTMLEscapeWriter().WriteString(query)
+	fmt.Fprintf(page, `"> <input type="submit" value="%s"></form>`, page.Translation().Text_Search())
+
+	// Used by JavaScript in docs generation mode.
+	page.WriteString(`<span class="hidden">`)
+	for _, kind := range searchItemKinds {
+		fmt.Fprintf(page, `<span id="search-kind-%s">%s</span>`, kind, page.Translation().Text_SearchResultKind(kind))
+	}
+	page.WriteString(`</span>`)
+	page.WriteString("</pre>\n")
+
+	page.WriteString(`<div id="search-results">`)
+	if result != nil {
+		ds.writeSearchResult(page, result)
+	}
+	page.WriteString(`</div>`)
+
+	return page.Done(w)
+}
+
+func (ds *docServer) writeSearchBlock(page *htmlPage) {
+	fmt.Fprintf(page, `<pre><form action="%s" method="get"><input type="text" name="q" size="32"> <input type="submit" value="%s">`,
+		buildPageHref(page.PathInfo, createPagePathInfo(ResTypeNone, "search"), nil, ""),
+		page.Translation().Text_Search(),
+	)
+	if !genDocsMode {
+		page.WriteString(" | ")
+		buildPageHref(page.PathInfo, createPagePathInfo(ResTypeNone, "code-search"), page, page.Translation().Text_CodeSearch())
+		page.WriteString(" | ")
+		buildPageHref(page.PathInfo, createPagePathInfo(ResTypeNone, "signature-search"), page, page.Translation().Text_SignatureSearch())
+	}
+	page.WriteString("</form></pre>\n")
+}
+
+func (ds *docServer) writeSearchResult(page *htmlPage, result *SearchResult) {
+	fmt.Fprintf(page, "<pre><code>%s</code></pre>\n", page.Translation().Text_SearchResultStat(result.NumItems, result.Query))
+
+	for _, group := range result.Groups {
+		fmt.Fprintf(page, `<pre><code><span class="title">%s%s</span>`,
+			page.Translation().Text_SearchResultKind(group.Kind),
+			page.Translation().Text_EnclosedInOarentheses(fmt.Sprint(len(group.Items))),
+		)
+		for _, item := range group.Items {
+			page.WriteString("\n\t")
+			if item.Kind != "package" {
+				page.WriteString(item.Package)
+				page.WriteByte('.')
+			}
+			fmt.Fprintf(page, `<a href="%s">`, item.Href)
+			page.AsHTMLEscapeWriter().WriteString(item.Name)
+			page.WriteString(`</a>`)
+			if item.Summary != "" {
+				page.WriteString(`<span class="search-summary"> - `)
+				page.AsHTMLEscapeWriter().WriteString(item.Summary)
+				page.WriteString(`</span>`)
+			}
+		}
+		page.WriteString("</code></pre>\n")
+	}
+}
+
+// SearchResult is the result of a search query.
+type SearchResult struct {
+	Query    string
+	NumItems int
+	Groups   []*SearchResultGroup
+}
+
+// SearchResultGroup holds the matched items of a kind.
+type SearchResultGroup struct {
+	Kind  string
+	Items []*SearchItem
+}
+
+// SearchItem is an entry of the search index.
+type SearchItem struct {
+	Kind       string // one of searchItemKinds
+	Name       string // for fields and methods, prefixed with the owner type name and a dot.
+	Package    string // package path
+	Href       string // the link to the item (relative to the search page in docs generation mode)
+	Summary    string // the first sentence of the docs
+	Exported   bool
+	Popularity int // the popularity of the item (or its owner type)
+
+	Score int `json:",omitempty"` // only valid in search results
+
+	lowerKeys []string // the lower-case names to match
+	lowerDoc  string   // the lower-case docs to match
+}
+
+// ds should be locked before calling this method.
+func (ds *docServer) searchIndex() []*SearchItem {
+	if ds.searchItems != nil {
+		return ds.searchItems
+	}
+
+	pagePathInfo := createPagePathInfo(ResTypeNone, "search")
+	items := make([]*SearchItem, 0, 1024*64)
+	newItem := func(kind, name string, pkg *code.Package, anchor string, exported bool, doc string, keys ...string) *SearchItem {
+		item := &SearchItem{
+			Kind:     kind,
+			Name:     name,
+			Package:  pkg.Path,
+			Summary:  docSummary(doc),
+			Exported: exported,
+			lowerDoc: strings.ToLower(doc),
+		}
+		if anchor == "" {
+			item.Href = buildPageHref(pagePathInfo, createPagePathInfo1(ResTypePackage, pkg.Path), nil, "")
+		} else {
+			item.Href = buildPageHref(pagePathInfo, createPagePathInfo1(ResTypePackage, pkg.Path), nil, "", "name-", anchor)
+		}
+		item.lowerKeys = make([]string, len(keys))
+		for i, k := range keys {
+			item.lowerKeys[i] = strings.ToLower(k)
+		}
+		items = append(items, item)
+		return item
+	}
+
+	for i, n := 0, ds.analyzer.NumPackages(); i < n; i++ {
+		pkg := ds.analyzer.PackageAt(i)
+		if pkg.PackageAnalyzeResult == nil || pkg.PPkg == nil {
+			continue
+		}
+
+		pkgName := pkg.PPkg.Name
+		pkgItem := newItem("package", pkg.Path, pkg, "", true, pkg.OneLineDoc, pkg.Path, pkgName)
+		pkgItem.Popularity = len(pkg.DepedBys) * 10
+
+		details := buildPackageDetailsData(ds.analyzer, pkg.Path, collectUnexporteds)
+		for _, rwp := range details.TypeNames {
+			td := rwp.Type
+			tn := td.TypeName
+			typeName := tn.Name()
+			newItem("type", typeName, pkg, typeName, tn.Exported(), tn.Documentation(), typeName, pkgName+"."+typeName).Popularity = td.Popularity
+
+			for _, fld := range td.Fields {
+				if fld.EmbeddingChain != nil {
+					continue // promoted ones are indexed with their declaring types.
+				}
+				name := typeName + "." + fld.Name()
+				newItem("field", name, pkg, typeName, tn.Exported() && token.IsExported(fld.Name()), fld.Field.Documentation(), fld.Name(), name).Popularity = td.Popularity / 2
+			}
+			for _, mthd := range td.Methods {
+				if mthd.EmbeddingChain != nil {
+					continue
+				}
+				name := typeName + "." + mthd.Name()
+				newItem("method", name, pkg, typeName, tn.Exported() && token.IsExported(mthd.Name()), mthd.Method.Documentation(), mthd.Name(), name).Popularity = td.Popularity / 2
+			}
+		}
+		for _, lst := range []struct {
+			kind string
+			rwps []ResourceWithPosition
+		}{
+			{"function", details.Functions},
+			{"variable", details.Variables},
+			{"constant", details.Constants},
+		} {
+			for _, rwp := range lst.rwps {
+				v := rwp.Value
+				newItem(lst.kind, v.Name(), pkg, v.Name(), v.Exported(), v.Documentation(), v.Name(), pkgName+"."+v.Name())
+			}
+		}
+	}
+
+	ds.searchItems = items
+	return items
+}
+
+// ds should be locked before calling this method.
+func (ds *docServer) search(query string) *SearchResult {
+	result := &SearchResult{Query: query}
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return result
+	}
+
+	groups := make(map[string]*SearchResultGroup, len(searchItemKinds))
+	for _, kind := range searchItemKinds {
+		groups[kind] = &SearchResultGroup{Kind: kind}
+	}
+
+	for _, item := range ds.searchIndex() {
+		score := searchItemScore(item, words)
+		if score <= 0 {
+			continue
+		}
+		matched := *item
+		matched.Score = score
+		g := groups[item.Kind]
+		g.Items = append(g.Items, &matched)
+	}
+
+	for _, kind := range searchItemKinds {
+		g := groups[kind]
+		if len(g.Items) == 0 {
+			continue
+		}
+		sort.Slice(g.Items, func(a, b int) bool {
+			if sa, sb := g.Items[a].Score, g.Items[b].Score; sa != sb {
+				return sa > sb
+			}
+			if na, nb := g.Items[a].Name, g.Items[b].Name; na != nb {
+				return na < nb
+			}
+			return g.Items[a].Package < g.Items[b].Package
+		})
+		if len(g.Items) > maxSearchResultsPerKind {
+			g.Items = g.Items[:maxSearchResultsPerKind]
+		}
+		result.NumItems += len(g.Items)
+		result.Groups = append(result.Groups, g)
+	}
+
+	return result
+}
+
+// searchItemScore returns the ranking score of an item for the specified query words,
+// which should be all in lower case. Zero means not matched.
+// An item is matched only if all words are matched.
+func searchItemScore(item *SearchItem, words []string) int {
+	var score = 0
+	for _, w := range words {
+		best := 0
+		for _, k := range item.lowerKeys {
+			if s := nameMatchScore(k, w); s > best {
+				best = s
+			}
+		}
+		if strings.Contains(item.lowerDoc, w) {
+			best += 50
+		}
+		if best == 0 {
+			return 0
+		}
+		score += best
+	}
+
+	popularity := item.Popularity / 10
+	if popularity > 200 {
+		popularity = 200
+	}
+	score += popularity
+	if item.Exported {
+		score += 10
+	}
+	return score
+}
+
+// Shorter words are not fuzzy matched, for they are subsequences
+// of too many names. (Keep it in sync with the one in the JS code.)
+const minFuzzyMatchWordLength = 4
+
+// Both name and word should be in lower case.
+func nameMatchScore(name, word string) int {
+	switch {
+	case name == word:
+		return 1000
+	case strings.HasPrefix(name, word):
+		return 600
+	case strings.Contains(name, word):
+		return 300
+	case len(word) >= minFuzzyMatchWordLength && isSubsequence(name, word):
+		return 100
+	}
+	return 0
+}
+
+// Whether or not all the bytes in sub appear in s in order.
+func isSubsequence(s, sub string) bool {
+	if len(sub) == 0 {
+		return true
+	}
+	k := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == sub[k] {
+			k++
+			if k == len(sub) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// docSummary returns the first sentence of a doc comment.
+func docSummary(doc string) string {
+	doc = strings.TrimSpace(doc)
+	if i := strings.Index(doc, "\n\n"); i >= 0 {
+		doc = doc[:i]
+	}
+	doc = strings.Join(strings.Fields(doc), " ")
+	if i := strings.Index(doc, ". "); i >= 0 {
+		doc = doc[:i+1]
+	}
+	const maxLen = 160
+	if len(doc) > maxLen {
+		i := strings.LastIndexByte(doc[:maxLen], ' ')
+		if i < 0 {
+			i = maxLen
+		}
+		doc = doc[:i] + " ..."
+	}
+	return doc
+}
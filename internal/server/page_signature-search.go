@@ -0,0 +1,493 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go101.org/golds/code"
+)
+
+const maxSignatureSearchResults = 200
+
+// Signature search is only available in server mode.
+func (ds *docServer) signatureSearchPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	if genDocsMode {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "Signature search is not supported in docs generation mode")
+		return
+	}
+
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
+
+	if ds.phase < Phase_Analyzed {
+		w.WriteHeader(http.StatusTooEarly)
+		ds.loadingPage(w, r)
+		return
+	}
+
+	query := strings.TrimSpace(r.FormValue("q"))
+
+	var result *SignatureSearchResult
+	var err error
+	if query != "" {
+		result, err = ds.searchSignature(query)
+	}
+
+	// Results are not cached, for they depend on the query.
+	w.Write(ds.buildSignatureSearchPage(w, query, result, err))
+}
+
+func (ds *docServer) buildSignatureSearchPage(w http.ResponseWriter, query string, result *SignatureSearchResult, err error) []byte {
+	page := NewHtmlPage(goldsVersion, ds.currentTranslation.Text_SignatureSearch(), ds.currentTheme, ds.currentTranslation, createPagePathInfo(ResTypeNone, "signature-search"))
+	fmt.Fprintf(page, `
+<pre><code><span style="font-size:xx-large;">%s</span></code></pre>
+`,
+		page.Translation().Text_SignatureSearch(),
+	)
+
+	fmt.Fprintf(page, `<pre><form action="%s" method="get">`, buildPageHref(page.PathInfo, createPagePathInfo(ResTypeNone, "signature-search"), nil, ""))
+	page.WriteString(`<input type="text" name="q" size="48" value="`)
+	page.AsHTMLEscapeWriter().WriteString(query)
+	fmt.Fprintf(page, `"> <input type="submit" value="%s"></form>`, page.Translation().Text_Search())
+	page.WriteString(page.Translation().Text_SignatureSearchTip())
+	page.WriteString("</pre>\n")
+
+	if err != nil {
+		page.WriteString(`<pre><code>`)
+		page.AsHTMLEscapeWriter().WriteString(err.Error())
+		page.WriteString("</code></pre>\n")
+	} else if result != nil {
+		fmt.Fprintf(page, "<pre><code>%s\n", page.Translation().Text_SignatureSearchResultStat(len(result.Functions), result.Truncated))
+		for _, f := range result.Functions {
+			page.WriteString("\n\t")
+			ds.writeSignatureSearchItem(page, f)
+		}
+		page.WriteString("</code></pre>\n")
+	}
+
+	return page.Done(w)
+}
+
+func (ds *docServer) writeSignatureSearchItem(page *htmlPage, f *code.Function) {
+	pkg := f.Package()
+	page.WriteString(pkg.Path)
+	page.WriteByte('.')
+	if f.IsMethod() {
+		_, tn, isStar := f.ReceiverTypeName()
+		if isStar {
+			page.WriteString("(*")
+		}
+		buildPageHref(page.PathInfo, createPagePathInfo1(ResTypePackage, pkg.Path), page, tn.Name(), "name-", tn.Name())
+		if isStar {
+			page.WriteByte(')')
+		}
+		page.WriteByte('.')
+		page.WriteString(f.Name())
+	} else {
+		buildPageHref(page.PathInfo, createPagePathInfo1(ResTypePackage, pkg.Path), page, f.Name(), "name-", f.Name())
+	}
+	page.WriteString(": ")
+	page.AsHTMLEscapeWriter().WriteString(types.TypeString(f.Func.Type(), func(p *types.Package) string {
+		return p.Name()
+	}))
+}
+
+// SignatureSearchResult holds the ranked functions matching a signature query.
+type SignatureSearchResult struct {
+	Functions []*code.Function
+	Truncated bool
+}
+
+// A signatureQuery is a parsed partial function signature.
+// Each element of params and results holds the candidate types
+// of a parameter or result. A nil element means any type.
+type signatureQuery struct {
+	params  [][]types.Type
+	results [][]types.Type
+
+	// Whether or not the types may be either parameters or results.
+	either bool
+}
+
+// parseSignatureQuery parses queries in the following forms:
+//
+//	func(io.Reader) ([]byte, error)
+//	returns *http.Request
+//	takes io.Reader, int
+//	*http.Request
+//
+// "_" means any type. It may only be used as a whole parameter or result.
+func (ds *docServer) parseSignatureQuery(query string) (*signatureQuery, error) {
+	var expr string
+	var either bool
+	switch {
+	case strings.HasPrefix(query, "func"):
+		expr = query
+	case strings.HasPrefix(query, "returns "):
+		expr = "func() " + strings.TrimPrefix(query, "returns ")
+	case strings.HasPrefix(query, "takes "):
+		expr = "func(" + strings.TrimPrefix(query, "takes ") + ")"
+	default:
+		expr = "func(" + query + ")"
+		either = true
+	}
+
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature query: %s", query)
+	}
+	ft, ok := e.(*ast.FuncType)
+	if !ok {
+		return nil, fmt.Errorf("invalid signature query: %s", query)
+	}
+
+	pkgsByName := make(map[string][]*code.Package, ds.analyzer.NumPackages())
+	for i, n := 0, ds.analyzer.NumPackages(); i < n; i++ {
+		pkg := ds.analyzer.PackageAt(i)
+		if pkg.PPkg != nil && pkg.PPkg.Types != nil {
+			pkgsByName[pkg.PPkg.Name] = append(pkgsByName[pkg.PPkg.Name], pkg)
+		}
+	}
+
+	resolveFields := func(fl *ast.FieldList) ([][]types.Type, error) {
+		if fl == nil {
+			return nil, nil
+		}
+		var list [][]types.Type
+		for _, fld := range fl.List {
+			n := len(fld.Names)
+			if n == 0 {
+				n = 1
+			}
+			var ts []types.Type
+			if id, ok := fld.Type.(*ast.Ident); !ok || id.Name != "_" {
+				ts, err = resolveQueryType(fld.Type, pkgsByName)
+				if err != nil {
+					return nil, err
+				}
+			}
+			for ; n > 0; n-- {
+				list = append(list, ts)
+			}
+		}
+		return list, nil
+	}
+
+	sq := &signatureQuery{either: either}
+	if sq.params, err = resolveFields(ft.Params); err != nil {
+		return nil, err
+	}
+	if sq.results, err = resolveFields(ft.Results); err != nil {
+		return nil, err
+	}
+	if len(sq.params) == 0 && len(sq.results) == 0 {
+		return nil, errors.New("no parameter or result types are specified")
+	}
+	return sq, nil
+}
+
+// resolveQueryType returns all the types the type expression may denote.
+// A qualified identifier might denote several types, for package names are not unique.
+func resolveQueryType(expr ast.Expr, pkgsByName map[string][]*code.Package) ([]types.Type, error) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return resolveQueryType(e.X, pkgsByName)
+	case *ast.Ident:
+		if obj, ok := types.Universe.Lookup(e.Name).(*types.TypeName); ok {
+			return []types.Type{obj.Type()}, nil
+		}
+		return nil, fmt.Errorf("unknown type: %s", e.Name)
+	case *ast.SelectorExpr:
+		id, ok := e.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		var ts []types.Type
+		for _, pkg := range pkgsByName[id.Name] {
+			if obj, ok := pkg.PPkg.Types.Scope().Lookup(e.Sel.Name).(*types.TypeName); ok {
+				ts = append(ts, obj.Type())
+			}
+		}
+		if len(ts) == 0 {
+			return nil, fmt.Errorf("unknown type: %s.%s", id.Name, e.Sel.Name)
+		}
+		return ts, nil
+	case *ast.StarExpr:
+		return mapQueryTypes(e.X, pkgsByName, func(t types.Type) types.Type {
+			return types.NewPointer(t)
+		})
+	case *ast.Ellipsis:
+		return mapQueryTypes(e.Elt, pkgsByName, func(t types.Type) types.Type {
+			return types.NewSlice(t)
+		})
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return mapQueryTypes(e.Elt, pkgsByName, func(t types.Type) types.Type {
+				return types.NewSlice(t)
+			})
+		}
+		lit, ok := e.Len.(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			break
+		}
+		n, ok := constant.Int64Val(constant.MakeFromLiteral(lit.Value, lit.Kind, 0))
+		if !ok {
+			break
+		}
+		return mapQueryTypes(e.Elt, pkgsByName, func(t types.Type) types.Type {
+			return types.NewArray(t, n)
+		})
+	case *ast.ChanType:
+		dir := types.SendRecv
+		switch e.Dir {
+		case ast.SEND:
+			dir = types.SendOnly
+		case ast.RECV:
+			dir = types.RecvOnly
+		}
+		return mapQueryTypes(e.Value, pkgsByName, func(t types.Type) types.Type {
+			return types.NewChan(dir, t)
+		})
+	case *ast.MapType:
+		keys, err := resolveQueryType(e.Key, pkgsByName)
+		if err != nil {
+			return nil, err
+		}
+		values, err := resolveQueryType(e.Value, pkgsByName)
+		if err != nil {
+			return nil, err
+		}
+		ts := make([]types.Type, 0, len(keys)*len(values))
+		for _, k := range keys {
+			for _, v := range values {
+				ts = append(ts, types.NewMap(k, v))
+			}
+		}
+		return ts, nil
+	case *ast.InterfaceType:
+		if len(e.Methods.List) == 0 {
+			return []types.Type{types.NewInterfaceType(nil, nil)}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type expression: %s", types.ExprString(expr))
+}
+
+func mapQueryTypes(expr ast.Expr, pkgsByName map[string][]*code.Package, f func(types.Type) types.Type) ([]types.Type, error) {
+	ts, err := resolveQueryType(expr, pkgsByName)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range ts {
+		ts[i] = f(t)
+	}
+	return ts, nil
+}
+
+// ds should be locked before calling this method.
+func (ds *docServer) searchSignature(query string) (*SignatureSearchResult, error) {
+	sq, err := ds.parseSignatureQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	type scoredFunction struct {
+		*code.Function
+		score int
+	}
+	var matcheds []scoredFunction
+
+	for i, n := 0, ds.analyzer.NumPackages(); i < n; i++ {
+		pkg := ds.analyzer.PackageAt(i)
+		if pkg.PackageAnalyzeResult == nil {
+			continue
+		}
+		for _, f := range pkg.AllFunctions {
+			if f.Func == nil { // builtin functions
+				continue
+			}
+			if !collectUnexporteds && !f.Exported() {
+				continue
+			}
+			if f.IsMethod() {
+				if _, tn, _ := f.ReceiverTypeName(); tn == nil || !collectUnexporteds && !tn.Exported() {
+					continue
+				}
+			}
+			sig, ok := f.Func.Type().(*types.Signature)
+			if !ok {
+				continue
+			}
+			if score := sq.matchScore(sig); score > 0 {
+				matcheds = append(matcheds, scoredFunction{f, score})
+			}
+		}
+	}
+
+	sort.Slice(matcheds, func(a, b int) bool {
+		fa, fb := matcheds[a], matcheds[b]
+		if fa.score != fb.score {
+			return fa.score > fb.score
+		}
+		if ea, eb := fa.Exported(), fb.Exported(); ea != eb {
+			return ea
+		}
+		if pa, pb := fa.Package().Path, fb.Package().Path; pa != pb {
+			return code.ComparePackagePaths(pa, pb, '/')
+		}
+		return fa.Name() < fb.Name()
+	})
+
+	result := &SignatureSearchResult{}
+	if len(matcheds) > maxSignatureSearchResults {
+		matcheds = matcheds[:maxSignatureSearchResults]
+		result.Truncated = true
+	}
+	result.Functions = make([]*code.Function, len(matcheds))
+	for i, f := range matcheds {
+		result.Functions[i] = f.Function
+	}
+	return result, nil
+}
+
+// matchScore returns the ranking score of a function signature.
+// Zero means not matched.
+func (sq *signatureQuery) matchScore(sig *types.Signature) int {
+	var inputs, outputs []types.Type
+	var numOptionalInputs int
+	if recv := sig.Recv(); recv != nil {
+		inputs = append(inputs, recv.Type())
+		numOptionalInputs = 1
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		inputs = append(inputs, sig.Params().At(i).Type())
+	}
+	for i := 0; i < sig.Results().Len(); i++ {
+		outputs = append(outputs, sig.Results().At(i).Type())
+	}
+
+	if sq.either {
+		s1 := matchTypeList(sq.params, inputs, numOptionalInputs, sig.Variadic(), true)
+		s2 := matchTypeList(sq.params, outputs, 0, false, false)
+		if s1 > s2 {
+			return s1
+		}
+		return s2
+	}
+
+	var score = 0
+	if len(sq.params) > 0 {
+		s := matchTypeList(sq.params, inputs, numOptionalInputs, sig.Variadic(), true)
+		if s == 0 {
+			return 0
+		}
+		score += s
+	}
+	if len(sq.results) > 0 {
+		s := matchTypeList(sq.results, outputs, 0, false, false)
+		if s == 0 {
+			return 0
+		}
+		score += s
+	}
+	return score
+}
+
+// matchTypeList matches the query types against the types of a parameter or result list
+// in a relaxed order. The first numOptionals types in the list are not required to be matched.
+// Zero means not matched.
+func matchTypeList(queries [][]types.Type, list []types.Type, numOptionals int, variadic, asInputs bool) int {
+	if len(queries) > len(list) {
+		return 0
+	}
+
+	var used = make([]bool, len(list))
+	var score, lastIndex = 0, -1
+	var inOrder = true
+	for _, candidates := range queries {
+		best, bestIndex := 0, -1
+		for i, t := range list {
+			if used[i] {
+				continue
+			}
+			var s int
+			if candidates == nil {
+				s = 1
+			} else {
+				for _, q := range candidates {
+					if x := typeMatchScore(q, t, asInputs); x > s {
+						s = x
+					}
+					if variadic && i == len(list)-1 {
+						if x := typeMatchScore(q, t.(*types.Slice).Elem(), asInputs); x > s {
+							s = x
+						}
+					}
+				}
+			}
+			if s > best {
+				best, bestIndex = s, i
+			}
+		}
+		if bestIndex < 0 {
+			return 0
+		}
+		used[bestIndex] = true
+		if bestIndex < lastIndex {
+			inOrder = false
+		}
+		lastIndex = bestIndex
+		score += best * 100
+	}
+
+	if inOrder {
+		score += 20
+	}
+	var numUnmatcheds = 0
+	for i := numOptionals; i < len(list); i++ {
+		if !used[i] {
+			numUnmatcheds++
+		}
+	}
+	if numUnmatcheds == 0 {
+		score += 50
+	}
+	score -= numUnmatcheds * 10
+	if score <= 0 {
+		score = 1
+	}
+	return score
+}
+
+// typeMatchScore returns how well the query type q matches the type t
+// of a parameter (asInput) or a result. Zero means not matched.
+func typeMatchScore(q, t types.Type, asInput bool) int {
+	if types.Identical(q, t) {
+		return 3
+	}
+	var to types.Type
+	var assignable bool
+	if asInput {
+		to, assignable = t, types.AssignableTo(q, t)
+	} else {
+		to, assignable = q, types.AssignableTo(t, q)
+	}
+	if !assignable {
+		return 0
+	}
+	if itf, ok := to.Underlying().(*types.Interface); ok && itf.Empty() {
+		return 1
+	}
+	return 2
+}
@@ -28,6 +28,7 @@ func (ds *docServer) sourceCodePage(w http.ResponseWriter, r *http.Request, pkgP
 
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
 
 	if ds.phase < Phase_Analyzed {
 		w.WriteHeader(http.StatusTooEarly)
@@ -69,6 +70,7 @@ func (ds *docServer) sourceCodePage(w http.ResponseWriter, r *http.Request, pkgP
 	pageKey := pageCacheKey{
 		resType: ResTypeSource,
 		res:     [...]string{pkgPath, bareFilename},
+		options: ds.pageCacheOptions(),
 	}
 	data, ok := ds.cachedPage(pageKey)
 	if !ok {
@@ -79,58 +81,24 @@ func (ds *docServer) sourceCodePage(w http.ResponseWriter, r *http.Request, pkgP
 			return
 		}
 
-		data = ds.buildSourceCodePage(w, result)
+		data, err = ds.buildSourceCodePage(w, result)
+		if err != nil {
+			writePageBuildError(w, err)
+			return
+		}
 		ds.cachePage(pageKey, data)
 	}
 	w.Write(data)
 }
 
-func (ds *docServer) buildSourceCodePage(w http.ResponseWriter, result *SourceFileAnalyzeResult) []byte {
+func (ds *docServer) buildSourceCodePage(w http.ResponseWriter, result *SourceFileAnalyzeResult) ([]byte, error) {
 	page := NewHtmlPage(goldsVersion, ds.currentTranslation.Text_SourceCode(result.PkgPath, result.BareFilename), ds.currentTheme, ds.currentTranslation, createPagePathInfo2b(ResTypeSource, result.PkgPath, "/", result.BareFilename))
 
-	realFilePath := result.OriginalPath
-	if result.GeneratedPath != "" {
-		realFilePath = result.GeneratedPath
-	}
-
-	if genDocsMode {
-		fmt.Fprintf(page, `
-<pre id="header"><code><span class="title">%s</span>
-	%s`,
-			page.Translation().Text_SourceFilePath(),
-			result.BareFilename,
-		)
-	} else {
-		fmt.Fprintf(page, `
-<pre id="header"><code><span class="title">%s</span>
-	%s`,
-			page.Translation().Text_SourceFilePath(),
-			realFilePath,
-		)
-
-		if result.OriginalPath != "" && result.OriginalPath != realFilePath {
-			fmt.Fprintf(page, `
-
-<span class="title">%s</span>
-	%s`,
-				page.Translation().Text_GeneratedFrom(),
-				result.OriginalPath,
-			)
+	writeSelectors := func() {
+		if result.NumRatios == 0 && result.NumImportRatios == 0 {
+			return
 		}
-	}
-
-	fmt.Fprintf(page, `
 
-<span class="title">%s</span>
-	<a href="%s">%s</a>
-</code></pre>
-`,
-		page.Translation().Text_BelongingPackage(),
-		buildPageHref(page.PathInfo, createPagePathInfo1(ResTypePackage, result.PkgPath), nil, ""),
-		result.PkgPath,
-	)
-
-	if result.NumRatios > 0 || result.NumImportRatios > 0 {
 		page.WriteString("<style>")
 		page.WriteString("input[type=radio] {display: none;}\n")
 		for i := int32(0); i < result.NumRatios; i++ {
@@ -163,34 +131,36 @@ func (ds *docServer) buildSourceCodePage(w http.ResponseWriter, result *SourceFi
 		}
 	}
 
-	page.WriteString(`
-<pre class="line-numbers">`)
-
-	var outputNewLine = true
-	for i, line := range result.Lines {
-		//		fmt.Fprintf(page, `
-		//<span class="anchor" id="line-%d"><code>%s</code></span>`,
-		//			i+1, line)
-		lineNumber := i + 1
-		if outputNewLine {
-			page.WriteByte('\n')
-		}
-		if lineNumber == result.DocStartLine {
-			page.WriteString(`<div class="anchor" id="doc">`)
-		}
-		fmt.Fprintf(page, `<span class="codeline" id="line-%d"><code>%s</code></span>`, lineNumber, line)
-		if lineNumber == result.DocEndLine {
-			page.WriteString(`</div>`)
-			outputNewLine = false
-		} else {
-			outputNewLine = true
+	writeLines := func() {
+		var outputNewLine = true
+		for i, line := range result.Lines {
+			//		fmt.Fprintf(page, `
+			//<span class="anchor" id="line-%d"><code>%s</code></span>`,
+			//			i+1, line)
+			lineNumber := i + 1
+			if outputNewLine {
+				page.WriteByte('\n')
+			}
+			if lineNumber == result.DocStartLine {
+				page.WriteString(`<div class="anchor" id="doc">`)
+			}
+			fmt.Fprintf(page, `<span class="codeline" id="line-%d"><code>%s</code></span>`, lineNumber, line)
+			if lineNumber == result.DocEndLine {
+				page.WriteString(`</div>`)
+				outputNewLine = false
+			} else {
+				outputNewLine = true
+			}
 		}
 	}
 
-	page.WriteString(`
-</pre>`)
-
-	return page.Done(w)
+	if err := ds.writePageTemplate(page, "source-code.html", result, map[string]func(){
+		"selectors": writeSelectors,
+		"lines":     writeLines,
+	}); err != nil {
+		return nil, err
+	}
+	return page.Done(w), nil
 }
 
 type SourceFileAnalyzeResult struct {
@@ -205,6 +175,15 @@ type SourceFileAnalyzeResult struct {
 	DocEndLine      int
 }
 
+// RealPath returns the path of the file shown in the page,
+// which is the generated file if the file is generated.
+func (r *SourceFileAnalyzeResult) RealPath() string {
+	if r.GeneratedPath != "" {
+		return r.GeneratedPath
+	}
+	return r.OriginalPath
+}
+
 /*
 var (
 	blankID          = []byte("_")
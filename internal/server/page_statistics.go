@@ -14,6 +14,7 @@ func (ds *docServer) statisticsPage(w http.ResponseWriter, r *http.Request) {
 
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
 
 	if ds.phase < Phase_Analyzed {
 		w.WriteHeader(http.StatusTooEarly)
@@ -29,6 +30,7 @@ func (ds *docServer) statisticsPage(w http.ResponseWriter, r *http.Request) {
 	pageKey := pageCacheKey{
 		resType: ResTypeNone,
 		res:     "statistics",
+		options: ds.pageCacheOptions(),
 	}
 	data, ok := ds.cachedPage(pageKey)
 	if !ok {
@@ -116,7 +118,8 @@ func (ds *docServer) buildStatisticsPage(w http.ResponseWriter) []byte {
 		}
 	})
 
-	writeSVGwithFolding("packages-by-dependencies", stats.PackagesDepsTopList.Items, func(items []interface{}) {
+	// All are linked to package dependencies page.
+	writePackages := func(items []interface{}) {
 		defer page.WriteString("\n")
 		for _, t := range items {
 			pkgPath, ok := t.(*string)
@@ -133,6 +136,17 @@ func (ds *docServer) buildStatisticsPage(w http.ResponseWriter) []byte {
 				)
 			}()
 		}
+	}
+
+	writeSVGwithFolding("packages-by-dependencies", stats.PackagesDepsTopList.Items, func(items []interface{}) {
+		writePackages(items)
+	})
+
+	writeSVG("packages-by-instability")
+	writeSVG("packages-by-abstractness")
+
+	writeSVGwithFolding("packages-by-distance", stats.PackagesDistanceTopList.Items, func(items []interface{}) {
+		writePackages(items)
 	})
 
 	fmt.Fprintf(page, `<pre><code><span class="title">%s</span></code>`, page.Translation().Text_StatisticsTitle("types"))
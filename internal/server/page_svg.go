@@ -14,6 +14,7 @@ import (
 func (ds *docServer) svgFile(w http.ResponseWriter, r *http.Request, svgFile string) {
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
+	ds.useRequestSettings(r)
 
 	if ds.phase < Phase_Analyzed {
 		w.Header().Set("Content-Type", "text/html")
@@ -30,6 +31,7 @@ func (ds *docServer) svgFile(w http.ResponseWriter, r *http.Request, svgFile str
 	pageKey := pageCacheKey{
 		resType: ResTypeSVG,
 		res:     svgFile,
+		options: ds.currentTranslation, // chart titles are translated
 	}
 	data, ok := ds.cachedPage(pageKey)
 	if !ok {
@@ -37,7 +39,11 @@ func (ds *docServer) svgFile(w http.ResponseWriter, r *http.Request, svgFile str
 		// For docs generation.
 		page := NewHtmlPage(goldsVersion, "", nil, ds.currentTranslation, createPagePathInfo(ResTypeSVG, svgFile))
 
-		data = ds.buildSVG(svgFile, page.Translation().Text_ChartTitle(svgFile))
+		if svgFile == customLogoFilename && logoResType == ResTypeSVG {
+			data = logoData
+		} else {
+			data = ds.buildSVG(svgFile, page.Translation().Text_ChartTitle(svgFile))
+		}
 		ds.cachePage(pageKey, data)
 
 		page.Write(data)
@@ -91,6 +97,12 @@ func (ds *docServer) buildSVG(svgFile string, chartTitle string) (svgData []byte
 		}
 	}
 
+	tenthName := func(max int) func(int, bool) string {
+		return func(i int, noPlus bool) string {
+			return fmt.Sprintf("%.1f", float64(i)/10)
+		}
+	}
+
 	stats := ds.analyzer.Statistics()
 	switch svgFile {
 	default:
@@ -99,6 +111,12 @@ func (ds *docServer) buildSVG(svgFile string, chartTitle string) (svgData []byte
 		svgData = createSourcefileImportsSVG(chartTitle, stats.FilesByImportCount[:], xName, 0, &stats.FilesImportCountTopList) // xName(len(stats.FilesByImportCount)-1))
 	case "packages-by-dependencies":
 		svgData = createSourcefileImportsSVG(chartTitle, stats.PackagesByDeps[:], xName, 0, &stats.PackagesDepsTopList) // xName(len(stats.PackagesByDeps)-1))
+	case "packages-by-instability":
+		svgData = createSourcefileImportsSVG(chartTitle, stats.PackagesByInstability[:], tenthName, 0, nil)
+	case "packages-by-abstractness":
+		svgData = createSourcefileImportsSVG(chartTitle, stats.PackagesByAbstractness[:], tenthName, 0, nil)
+	case "packages-by-distance":
+		svgData = createSourcefileImportsSVG(chartTitle, stats.PackagesByDistance[:], tenthName, 0, &stats.PackagesDistanceTopList)
 	case "exportedtypenames-by-kinds":
 		svgData = createSourcefileImportsSVG(chartTitle, stats.ExportedTypeNamesByKind[:], kindName, 1, nil) // [1:]
 	case "exportedstructtypes-by-embeddingfields":
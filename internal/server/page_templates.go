@@ -0,0 +1,167 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"go101.org/golds/code"
+	"go101.org/golds/internal/server/templates"
+)
+
+// The page templates. The default ones are in the templates package,
+// and each of them could be overridden by a file with the same name in
+// the -templates directory. The directory may also contain other
+// templates, which are used by the overriding ones.
+var pageTemplates *template.Template
+
+var pageTemplateFuncs = template.FuncMap{
+	// Translation texts and some values written by Go code are
+	// HTML, so they must not be escaped again.
+	"raw": func(s string) template.HTML { return template.HTML(s) },
+	"int": func(v interface{}) (int, error) {
+		switch v := v.(type) {
+		case int:
+			return v, nil
+		case int32:
+			return int(v), nil
+		case uint32:
+			return int(v), nil
+		case int64:
+			return int(v), nil
+		}
+		return 0, fmt.Errorf("not an integer: %v", v)
+	},
+	"trimPrefix": strings.TrimPrefix,
+}
+
+// loadPageTemplates parses the default page templates and the
+// templates in dir (if it is not blank). Errors are fatal, for
+// dir is from the program options.
+func loadPageTemplates(dir string) {
+	t := template.New("").Funcs(pageTemplateFuncs)
+	for name, text := range templates.Defaults {
+		template.Must(t.New(name).Parse(text))
+	}
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.html"))
+		if err != nil {
+			log.Fatalln("-templates: list template files error:", err)
+		}
+		if len(files) == 0 {
+			log.Fatalln("-templates: no .html template files are found in", dir)
+		}
+		if _, err := t.ParseFiles(files...); err != nil {
+			log.Fatalln("-templates: parse templates error:", err)
+		}
+	}
+	pageTemplates = t
+}
+
+// pageTemplateContext is the data (dot) of a page template.
+// Besides the page data, it provides methods to build hrefs
+// and to write code fragments and sections with Go code.
+//
+// Templates are executed into the pages directly, so the methods
+// writing fragments and sections write into the pages directly too
+// (and return blank HTML), to avoid copying large page sections.
+type pageTemplateContext struct {
+	ds   *docServer
+	page *htmlPage
+
+	// The page data, such as *PackageDetails.
+	Data interface{}
+
+	sections map[string]func()
+}
+
+// writePageTemplate writes a page template into the page. The sections
+// are the parts of the page which are still built by Go code.
+// On errors, the half-written page is discarded and must not be used.
+func (ds *docServer) writePageTemplate(page *htmlPage, name string, data interface{}, sections map[string]func()) error {
+	tc := &pageTemplateContext{ds: ds, page: page, Data: data, sections: sections}
+	if err := pageTemplates.ExecuteTemplate(page, name, tc); err != nil {
+		contentPool.collect(page.content)
+		page.content = nil
+		return fmt.Errorf("execute template %s error: %w", name, err)
+	}
+	return nil
+}
+
+// writePageBuildError makes a request fail for a page build error,
+// such as an error in a user-supplied template. In docs generation
+// mode, the non-ok status makes the generation fail.
+func writePageBuildError(w http.ResponseWriter, err error) {
+	log.Println(err)
+	w.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprint(w, err)
+}
+
+// T returns the translation used in the page.
+func (tc *pageTemplateContext) T() Translation {
+	return tc.page.Translation()
+}
+
+// Section writes a section of the page, which is built by Go code.
+func (tc *pageTemplateContext) Section(name string) (template.HTML, error) {
+	write, ok := tc.sections[name]
+	if !ok {
+		return "", fmt.Errorf("unknown section: %s", name)
+	}
+	write()
+	return "", nil
+}
+
+// CollectUnexporteds reports whether or not unexported
+// resources are listed in pages.
+func (tc *pageTemplateContext) CollectUnexporteds() bool {
+	return collectUnexporteds
+}
+
+// GeneratingDocs reports whether or not the page is
+// built in docs generation mode.
+func (tc *pageTemplateContext) GeneratingDocs() bool {
+	return genDocsMode
+}
+
+// OverviewHref returns the href of the overview page.
+func (tc *pageTemplateContext) OverviewHref() string {
+	return buildPageHref(tc.page.PathInfo, createPagePathInfo(ResTypeNone, ""), nil, "")
+}
+
+// OverviewPackageHref returns the href of a package item in the overview page.
+func (tc *pageTemplateContext) OverviewPackageHref(pkgPath string) string {
+	return buildPageHref(tc.page.PathInfo, createPagePathInfo(ResTypeNone, ""), nil, "", "pkg-", pkgPath)
+}
+
+// PackageHref returns the href of the details page of a package.
+func (tc *pageTemplateContext) PackageHref(pkgPath string) string {
+	return buildPageHref(tc.page.PathInfo, createPagePathInfo1(ResTypePackage, pkgPath), nil, "")
+}
+
+// DependencyHref returns the href of the dependencies page of a package.
+func (tc *pageTemplateContext) DependencyHref(pkgPath string) string {
+	return buildPageHref(tc.page.PathInfo, createPagePathInfo1(ResTypeDependency, pkgPath), nil, "")
+}
+
+// PackageList writes a package list.
+func (tc *pageTemplateContext) PackageList(packages []*PackageForListing) template.HTML {
+	tc.ds.writePackagesForListing(tc.page, packages, false)
+	return ""
+}
+
+// ResourceIndex writes the name of a package-level resource,
+// which links to its declaration.
+func (tc *pageTemplateContext) ResourceIndex(res code.Resource) template.HTML {
+	tc.ds.writeResourceIndexHTML(tc.page, res.Package(), res, false, false, false)
+	return ""
+}
+
+// KindText writes the kind of the type denoted by a type name.
+func (tc *pageTemplateContext) KindText(tn *code.TypeName) template.HTML {
+	writeKindText(tc.page, tn.Denoting.TT)
+	return ""
+}
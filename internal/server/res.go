@@ -2,6 +2,11 @@ package server
 
 import (
 	"bufio"
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -14,6 +19,7 @@ import (
 
 type Theme interface {
 	Name() string
+	Dark() bool // whether or not it is a dark color scheme
 	CSS() string
 }
 
@@ -75,6 +81,8 @@ type Translation interface {
 	Text_BelongingPackage() string // also used in source code page
 	Text_PackageDocsLinksOnOtherWebsites(pkgPath string, isStdPkg bool) string
 	Text_ImportPath() string
+	Text_PageNotFound() string
+	Text_PackageDocsNotGenerated() string
 	Text_ImportStat(numImports, numImportedBys int, depPageURL string) string
 	Text_InvolvedFiles(num int) string
 	Text_Examples(num int) string
@@ -127,6 +135,17 @@ type Translation interface {
 	Text_ValueStatistics(values map[string]interface{}) []string
 	Text_Othertatistics(values map[string]interface{}) []string
 
+	// search
+	Text_Search() string
+	Text_SearchResultKind(kind string) string
+	Text_SearchResultStat(numResults int, query string) string
+	Text_CodeSearch() string
+	Text_CodeSearchOption(option string) string // options: "regexp", "scope"
+	Text_CodeSearchResultStat(numMatches, numFiles int, truncated bool) string
+	Text_SignatureSearch() string
+	Text_SignatureSearchTip() string
+	Text_SignatureSearchResultStat(numFunctions int, truncated bool) string
+
 	// Footer
 	Text_GeneratedPageFooter(goldsVersion, qrCodeLink, goOS, goArch string) string
 	Text_GeneratedPageFooterSimple(goldsVersion, goOS, goArch string) string
@@ -139,23 +158,133 @@ func (ds *docServer) currentSettings() (Theme, Translation) {
 	return ds.currentTheme, ds.currentTranslation
 }
 
+// changeSettings changes the default settings,
+// which are used if requests don't specify settings.
 func (ds *docServer) changeSettings(themeName string, langTags ...string) {
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
 
 	if themeName != "" {
-		ds.currentTheme = ds.themeByName(themeName)
+		ds.defaultSettings.theme = ds.themeByName(themeName)
 	}
 	if len(langTags) > 0 {
-		ds.currentTranslation = ds.translationByLangs(langTags...)
+		ds.defaultSettings.translation = ds.translationByLangs(langTags...)
 	}
+	ds.useSettings(ds.defaultSettings)
 }
 
-func (ds *docServer) changeTranslationByAcceptLanguage(acceptedLanguage string) {
-	langTags, _, _ := language.ParseAcceptLanguage(acceptedLanguage)
+// pageSettings are the theme and translation used to build pages.
+// In serving mode, they are resolved for each request, and they are
+// used as the options of the cache keys of the pages depending on them.
+type pageSettings struct {
+	theme       Theme
+	translation Translation
+}
+
+// The cookies which persist the settings chosen by visitors.
+const (
+	themeCookieName = "golds-theme"
+	langCookieName  = "golds-lang"
+)
+
+type pageSettingsContextKey struct{}
+
+// changeSettingsByQuery persists the settings in the query string of r,
+// such as "?theme=dark&lang=fr", in cookies, then redirects to the url
+// without the setting parameters. A blank value resets a setting.
+// It returns false (and does nothing) if there are no setting parameters.
+func (ds *docServer) changeSettingsByQuery(w http.ResponseWriter, r *http.Request) bool {
+	query := r.URL.Query()
+	if !query.Has("theme") && !query.Has("lang") {
+		return false
+	}
+
+	setCookie := func(param, cookieName string, valid func(string) bool) {
+		if !query.Has(param) {
+			return
+		}
+		value := strings.TrimSpace(query.Get(param))
+		query.Del(param)
+		if !valid(value) {
+			return
+		}
+		cookie := &http.Cookie{
+			Name:     cookieName,
+			Value:    value,
+			Path:     "/",
+			MaxAge:   365 * 24 * 3600,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		}
+		if value == "" {
+			cookie.MaxAge = -1
+		}
+		http.SetCookie(w, cookie)
+	}
+	setCookie("theme", themeCookieName, func(name string) bool {
+		return name == "" || isThemeName(name)
+	})
+	setCookie("lang", langCookieName, func(lang string) bool {
+		_, err := language.Parse(lang)
+		return lang == "" || err == nil
+	})
+
+	url := *r.URL
+	url.RawQuery = query.Encode()
+	http.Redirect(w, r, url.String(), http.StatusTemporaryRedirect)
+	return true
+}
+
+// withRequestSettings returns a shallow copy of r, whose context
+// carries the settings resolved from the cookies and Accept-Language
+// header of r. The default settings are used for the unspecified ones.
+func (ds *docServer) withRequestSettings(r *http.Request) *http.Request {
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
-	ds.currentTranslation = ds.translationByLangTags(langTags...)
+
+	settings := ds.defaultSettings
+	if c, err := r.Cookie(themeCookieName); err == nil && isThemeName(c.Value) {
+		settings.theme = ds.themeByName(c.Value)
+	}
+	if c, err := r.Cookie(langCookieName); err == nil && c.Value != "" {
+		settings.translation = ds.translationByLangs(c.Value)
+	} else if !ds.langSpecified {
+		if tr := ds.translationByAcceptLanguage(r.Header.Get("Accept-Language")); tr != nil {
+			settings.translation = tr
+		}
+	}
+	return r.WithContext(context.WithValue(r.Context(), pageSettingsContextKey{}, settings))
+}
+
+// useRequestSettings makes the settings carried by r (see withRequestSettings)
+// current. It must be called with ds.mutex locked, before building pages.
+func (ds *docServer) useRequestSettings(r *http.Request) {
+	if settings, ok := r.Context().Value(pageSettingsContextKey{}).(pageSettings); ok {
+		ds.useSettings(settings)
+	}
+}
+
+func (ds *docServer) useSettings(settings pageSettings) {
+	ds.currentTranslation = settings.translation
+	if settings.theme != ds.currentTheme {
+		ds.currentTheme = settings.theme
+		ds.onThemeChanged()
+	}
+}
+
+// pageCacheOptions returns the options of the cache keys of the pages
+// depending on the current settings.
+func (ds *docServer) pageCacheOptions() interface{} {
+	return pageSettings{theme: ds.currentTheme, translation: ds.currentTranslation}
+}
+
+// translationByAcceptLanguage returns nil if no languages are accepted.
+func (ds *docServer) translationByAcceptLanguage(acceptedLanguage string) Translation {
+	langTags, _, _ := language.ParseAcceptLanguage(acceptedLanguage)
+	if len(langTags) == 0 {
+		return nil
+	}
+	return ds.translationByLangTags(langTags...)
 }
 
 // All themes and translations must be registered at init phase,
@@ -178,12 +307,15 @@ func (ds *docServer) initSettings(lang string) {
 		translations2 = append(translations2, tr)
 	}
 
-	registerTheme(theme.Auto{})
-	registerTheme(theme.Light{})
-	registerTheme(theme.Dark{})
+	for _, t := range themeChoices() {
+		registerTheme(t)
+	}
 
 	registerTranslation(&translation.English{})
 	registerTranslation(&translation.Chinese{})
+	for _, tr := range catalogTranslations {
+		registerTranslation(tr)
+	}
 
 	ds.mutex.Lock()
 	defer ds.mutex.Unlock()
@@ -197,6 +329,7 @@ func (ds *docServer) initSettings(lang string) {
 	ds.onThemeChanged()
 	ds.currentTranslation = ds.allTranslations[0]
 	ds.currentTranslation = ds.translationByLangs(lang)
+	ds.defaultSettings = pageSettings{theme: ds.currentTheme, translation: ds.currentTranslation}
 }
 
 func (ds *docServer) currentTranslationSafely() Translation {
@@ -214,7 +347,10 @@ func (ds *docServer) themeByName(name string) Theme {
 	return theme
 }
 
+// onThemeChanged must be called when the current theme is changed.
+// It updates the theme styles used in code.
 func (ds *docServer) onThemeChanged() {
+	ds.css.chosenIdent, ds.css.chosenImport = "", ""
 	css := ds.currentTheme.CSS()
 	scanner := bufio.NewScanner(strings.NewReader(css))
 	for scanner.Scan() {
@@ -235,6 +371,93 @@ func (ds *docServer) onThemeChanged() {
 	}
 }
 
+// User themes are loaded from files (see themes.User).
+// They are listed after the built-in themes in theme choosers.
+var userThemes []Theme
+
+// loadUserThemes loads the themes in the $UserConfigDir/golds/themes
+// directory. Invalid themes are ignored (with warnings).
+func loadUserThemes() {
+	userThemes = nil
+	dir, err := theme.UserThemesDir()
+	if err != nil {
+		return
+	}
+	themes, errs := theme.LoadUserThemes(dir)
+	for _, err := range errs {
+		log.Println("Load user theme error:", err)
+	}
+	for _, t := range themes {
+		if isBuiltinThemeName(t.Name()) {
+			log.Printf("User theme %s is ignored, for it has the same name as a built-in one.", t.Name())
+			continue
+		}
+		userThemes = append(userThemes, t)
+	}
+}
+
+func builtinThemes() []Theme {
+	return []Theme{theme.Auto{}, theme.Light{}, theme.Dark{}}
+}
+
+func isBuiltinThemeName(name string) bool {
+	for _, t := range builtinThemes() {
+		if t.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isThemeName(name string) bool {
+	for _, t := range themeChoices() {
+		if t.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// themeChoices returns all the themes, built-in ones first.
+func themeChoices() []Theme {
+	return append(builtinThemes(), userThemes...)
+}
+
+// Translations loaded from catalog files (see translations.Catalog).
+// They are registered after the built-in translations.
+var catalogTranslations []Translation
+
+// loadTranslationCatalogs loads the translation catalogs in dir. If dir is
+// blank, the catalogs in the $UserConfigDir/golds/translations directory
+// (if it exists) are loaded. Errors are fatal only if dir is not blank,
+// for it is from the program options.
+func loadTranslationCatalogs(dir string) {
+	catalogTranslations = nil
+	logError := func(err error) {
+		log.Fatalln("-translations:", err)
+	}
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return
+		}
+		dir = filepath.Join(configDir, "golds", "translations")
+		if _, err := os.Stat(dir); err != nil {
+			return
+		}
+		logError = func(err error) {
+			log.Println("Load translation catalog error:", err)
+		}
+	}
+	catalogs, errs := translation.LoadCatalogs(dir)
+	for _, err := range errs {
+		logError(err)
+	}
+	for _, c := range catalogs {
+		catalogTranslations = append(catalogTranslations, c)
+	}
+}
+
 func (ds *docServer) translationByName(name string) Translation {
 	trans := ds.allTranslations[0]
 	for _, tr := range ds.allTranslations[1:] {
@@ -12,7 +12,6 @@ import (
 	"os/signal"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -68,15 +67,20 @@ type docServer struct {
 	cachedPages map[pageCacheKey][]byte
 	//cachedPagesOptions map[pageCacheKey]interface{} // key.options must be nil in this map
 
+	// Lazily built on the first search.
+	searchItems []*SearchItem
+
 	docRenderer util.MarkdownRenderer
 
-	//
+	// The settings used to build the current page (see useRequestSettings).
 	currentTranslation Translation
 	currentTheme       Theme
 	css                struct {
 		chosenIdent  string
 		chosenImport string
 	}
+	// The settings used if requests don't specify them.
+	defaultSettings pageSettings
 
 	//
 	updateLogger          *log.Logger
@@ -87,12 +91,50 @@ type docServer struct {
 
 	//
 	generalLogger *log.Logger
-	visited       int32
+	langSpecified bool // not to use the Accept-Language headers
 
 	// ToDo: show which packages are dirty in overview page.
 	localRepositoryWarnings []string // not committed, not pushed, etc. (useful for docs generation mode)
 }
 
+// newPageBuilder returns a docServer which shares the analysis results
+// and settings with ds but has its own mutex and page building states,
+// so that it could build pages concurrently with ds.
+// It is used in docs generation mode, after the analysis is done.
+func (ds *docServer) newPageBuilder() *docServer {
+	return &docServer{
+		appPkgPath:              ds.appPkgPath,
+		initialWorkingDirectory: ds.initialWorkingDirectory,
+
+		moduleBuildSourceLinkFuncs: ds.moduleBuildSourceLinkFuncs,
+
+		allThemes:                  ds.allThemes,
+		allTranslations:            ds.allTranslations,
+		langMatcher:                ds.langMatcher,
+		translationsByLangTagIndex: ds.translationsByLangTagIndex,
+
+		phase:           ds.phase,
+		analyzer:        ds.analyzer,
+		analyzingLogger: ds.analyzingLogger,
+
+		currentTranslation: ds.currentTranslation,
+		currentTheme:       ds.currentTheme,
+		defaultSettings:    ds.defaultSettings,
+		css:                ds.css,
+
+		updateLogger:          ds.updateLogger,
+		roughBuildTime:        ds.roughBuildTime,
+		updateTip:             ds.updateTip,
+		cachedUpdateTip:       ds.cachedUpdateTip,
+		newerVersionInstalled: ds.newerVersionInstalled,
+
+		generalLogger: ds.generalLogger,
+		langSpecified: ds.langSpecified,
+
+		localRepositoryWarnings: ds.localRepositoryWarnings,
+	}
+}
+
 func Run(options PageOutputOptions, args []string, recommendedPort string, silentMode bool, printUsage func(io.Writer), appPkgPath string, roughBuildTime func() time.Time) {
 	ds := &docServer{
 		appPkgPath: appPkgPath,
@@ -105,7 +147,7 @@ func Run(options PageOutputOptions, args []string, recommendedPort string, silen
 	}
 
 	if options.PreferredLang != "" {
-		ds.visited = 1 // to avoid auto adjusted
+		ds.langSpecified = true
 	} else {
 		options.PreferredLang = os.Getenv("LANG")
 	}
@@ -175,17 +217,22 @@ var sem = make(chan struct{}, 10)
 func (ds *docServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// To avoid too hight peak memory use cause by DDOS attack.
 	// Rate Limiting is not very essential, for page content are cached.
-	sem <- struct{}{}
-	defer func() { <-sem }()
-
-	if atomic.SwapInt32(&ds.visited, 1) == 0 {
-		ds.changeTranslationByAcceptLanguage(r.Header.Get("Accept-Language"))
+	// In docs generation mode, the concurrency is controlled by GenDocs.
+	if !genDocsMode {
+		sem <- struct{}{}
+		defer func() { <-sem }()
 	}
 
-	// Query strings might contain setting change parameters,
-	// such as "?theme=dark&lang=fr".
-	// ToDo, if query string is not blank, change settings,
-	//       then redirect to the url without query string.
+	// Each visitor has its own settings. Query strings might contain
+	// setting change parameters, such as "?theme=dark&lang=fr", which
+	// are persisted in cookies (then the request is redirected).
+	// In docs generation mode, the default settings are always used.
+	if !genDocsMode {
+		if ds.changeSettingsByQuery(w, r) {
+			return
+		}
+		r = ds.withRequestSettings(r)
+	}
 
 	var path = r.URL.Path[1:]
 	if path == "" {
@@ -203,6 +250,12 @@ func (ds *docServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 		case "statistics":
 			ds.statisticsPage(w, r)
+		case "search":
+			ds.searchPage(w, r)
+		case "code-search":
+			ds.codeSearchPage(w, r)
+		case "signature-search":
+			ds.signatureSearchPage(w, r)
 		}
 		return
 	}
@@ -214,12 +267,15 @@ func (ds *docServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case ResTypeAPI: // "api"
 		switch resPath {
 		default:
-			w.WriteHeader(http.StatusNotFound)
-			fmt.Fprint(w, "Invalid url")
+			ds.dataAPI(w, r, resPath)
 		case "update":
 			ds.updateAPI(w, r)
 		case "load":
 			ds.loadAPI(w, r)
+		case "search":
+			ds.searchAPI(w, r)
+		case "search-index":
+			ds.searchIndexFile(w, r)
 		}
 	case ResTypeCSS: // "css"
 		ds.cssFile(w, r, removeVersionFromFilename(resPath, goldsVersion))
@@ -271,6 +327,7 @@ func (ds *docServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 func (ds *docServer) analyze(args []string, options PageOutputOptions, toolchain code.ToolchainInfo, forTesting bool, printUsage func(io.Writer)) {
 	setPageOutputOptions(options, forTesting)
+	goToolchainVersion = toolchain.Version
 	ds.initSettings(options.PreferredLang)
 
 	// ...
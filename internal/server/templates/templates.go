@@ -0,0 +1,147 @@
+// Package templates contains the default html/template templates of
+// some pages. Each of them could be overridden by a file with the same
+// name in the directory specified by the -templates option.
+//
+// The data (dot) of a page template is a page template context, whose
+// Data field is the page data. The context also provides some methods
+// to build hrefs and to write code fragments, and sections which are
+// still built by Go code (see pageTemplateContext in the server package).
+package templates
+
+// Defaults maps the template names to the default templates.
+var Defaults = map[string]string{
+	"package-header.html":         PackageHeader,
+	"package-details.html":        PackageDetails,
+	"package-stub.html":           PackageStub,
+	"package-dependencies.html":   PackageDependencies,
+	"method-implementations.html": MethodImplementations,
+	"source-code.html":            SourceCode,
+	"references.html":             References,
+	"not-found.html":              NotFound,
+}
+
+// PackageHeader is used in the package details and package stub pages.
+// Its Data is a *PackageDetails.
+const PackageHeader = `<pre id="package-details"><code><span style="font-size:xx-large;">package <b>{{.Data.Name}}</b></span>
+
+<span class="title">{{.T.Text_ImportPath}}</span>
+	<a href="{{.OverviewPackageHref .Data.ImportPath}}">{{.Data.ImportPath}}</a>{{raw (.T.Text_PackageDocsLinksOnOtherWebsites (trimPrefix .Data.ImportPath "vendor/") .Data.IsStandard)}}`
+
+// PackageDetails is the template of package details pages.
+// Its Data is a *PackageDetails. The sections are the item lists
+// of files, examples, types, functions, variables and constants.
+const PackageDetails = `{{- /* .Data is a *PackageDetails. */}}
+{{template "package-header.html" .}}
+{{- if ne .Data.ImportPath "builtin"}}
+
+<span class="title">{{.T.Text_DependencyRelations ""}}</span>
+	{{raw (.T.Text_ImportStat (int .Data.NumDeps) (int .Data.NumDepedBys) (.DependencyHref .Data.ImportPath))}}
+{{- end}}
+{{if .Data.Files}}
+<div id="files"><span class="title">{{.T.Text_InvolvedFiles (len .Data.Files)}}</span>
+{{.Section "files"}}</div>
+{{- end}}
+{{- if .Data.Examples}}
+<div id="examples"><span class="title">{{.T.Text_Examples (len .Data.Examples)}}</span>
+{{.Section "examples"}}
+</div>
+{{- end}}
+{{- if .Data.TypeNames}}
+<div id="exported-types"><span class="title">{{.T.Text_PackageLevelTypeNames}}<span class="title-stat"><i>{{.T.Text_Parenthesis false}}{{.T.Text_PackageLevelResourceSimpleStat true (len .Data.TypeNames) (int .Data.NumExportedTypeNames) .CollectUnexporteds}}{{.T.Text_Parenthesis true}}</i></span></span>
+
+<div id="exported-types-buttons" class="js-on">	/* {{if .CollectUnexporteds}}{{.T.Text_SortBy "exporteds-types"}}{{else}}{{.T.Text_SortBy ""}}{{end}}{{.T.Text_Colon false}}<label id="sort-types-by-alphabet" class="button">{{.T.Text_SortByItem "alphabet"}}</label> | <label id="sort-types-by-popularity" class="button">{{.T.Text_SortByItem "popularity"}}</label> */</div>{{.Section "types"}}</div>
+{{- end}}
+{{- if .Data.Functions}}
+<div id="exported-functions"><span class="title">{{.T.Text_PackageLevelFunctions}}<span class="title-stat"><i>{{.T.Text_Parenthesis false}}{{.T.Text_PackageLevelResourceSimpleStat true (len .Data.Functions) (int .Data.NumExportedFunctions) .CollectUnexporteds}}{{.T.Text_Parenthesis true}}</i></span></span>
+
+{{.Section "functions"}}</div>
+{{- end}}
+{{- if .Data.Variables}}
+<div id="exported-variables"><span class="title">{{.T.Text_PackageLevelVariables}}<span class="title-stat"><i>{{.T.Text_Parenthesis false}}{{.T.Text_PackageLevelResourceSimpleStat true (len .Data.Variables) (int .Data.NumExportedVariables) .CollectUnexporteds}}{{.T.Text_Parenthesis true}}</i></span></span>
+
+{{.Section "variables"}}</div>
+{{- end}}
+{{- if .Data.Constants}}
+<div id="exported-constants"><span class="title">{{.T.Text_PackageLevelConstants}}<span class="title-stat"><i>{{.T.Text_Parenthesis false}}{{.T.Text_PackageLevelResourceSimpleStat true (len .Data.Constants) (int .Data.NumExportedConstants) .CollectUnexporteds}}{{.T.Text_Parenthesis true}}</i></span></span>
+
+{{.Section "constants"}}</div>
+{{- end -}}
+</code></pre>`
+
+// PackageStub is the template of the pages of the packages whose
+// full docs are not generated. Its Data is a *PackageDetails,
+// in which only the basic fields are set.
+const PackageStub = `{{- /* .Data is a *PackageDetails. */}}
+{{template "package-header.html" .}}
+
+	<i>{{.T.Text_PackageDocsNotGenerated}}</i>
+</code></pre>`
+
+// PackageDependencies is the template of package dependencies pages.
+// Its Data is a *PackageDependencyInfo.
+const PackageDependencies = `{{- /* .Data is a *PackageDependencyInfo. */}}
+<pre><code><span style="font-size:xx-large;">package <b>{{.Data.Name}}</b></span>
+
+<span class="title">{{.T.Text_ImportPath}}</span>
+	<a href="{{.PackageHref .Data.ImportPath}}">{{.Data.ImportPath}}</a>
+{{if .Data.Imports}}
+<span class="title">{{.T.Text_Imports}}</span>{{.PackageList .Data.Imports}}{{end}}
+{{- if .Data.ImportedBys}}
+<span class="title" id="imported-by">{{.T.Text_ImportedBy}}</span>{{.PackageList .Data.ImportedBys}}{{end}}`
+
+// MethodImplementations is the template of method implementation pages.
+// Its Data is a *MethodImplementationResult. The section is methods.
+const MethodImplementations = `{{- /* .Data is a *MethodImplementationResult. */ -}}
+<pre><code><span style="font-size:x-large;">type <a href="{{.PackageHref .Data.Package.Path}}">{{.Data.Package.Path}}</a>.<b>{{.ResourceIndex .Data.TypeName}}</b></span><span style="font-size:large;">{{.KindText .Data.TypeName}}</span>
+
+<code><span class="title">{{.T.Text_MethodImplementations}}<span class="title-stat"><i>{{if not .Data.IsInterface}}{{.T.Text_NumMethodsImplementingNothing (int .Data.NonImplementingMethodCount)}}{{end}}</i></span></span>
+{{.Section "methods"}}</code></pre>`
+
+// SourceCode is the template of source code pages. Its Data is a
+// *SourceFileAnalyzeResult. The sections are the selectors (the
+// styles and inputs used to highlight identifiers) and the lines
+// of the highlighted code.
+const SourceCode = `{{- /* .Data is a *SourceFileAnalyzeResult. */}}
+<pre id="header"><code><span class="title">{{.T.Text_SourceFilePath}}</span>
+{{- if .GeneratingDocs}}
+	{{.Data.BareFilename}}
+{{- else}}
+	{{.Data.RealPath}}
+{{- if and .Data.OriginalPath (ne .Data.OriginalPath .Data.RealPath)}}
+
+<span class="title">{{.T.Text_GeneratedFrom}}</span>
+	{{.Data.OriginalPath}}
+{{- end}}
+{{- end}}
+
+<span class="title">{{.T.Text_BelongingPackage}}</span>
+	<a href="{{.PackageHref .Data.PkgPath}}">{{.Data.PkgPath}}</a>
+</code></pre>
+{{.Section "selectors"}}
+<pre class="line-numbers">{{.Section "lines"}}
+</pre>`
+
+// References is the template of identifier references pages.
+// Its Data is a *ReferencesResult. The sections are the selector
+// (for fields and methods), the link to the method implementations
+// page (for methods, might write nothing) and the references.
+const References = `{{- /* .Data is a *ReferencesResult. */}}
+<pre><code><span style="font-size:x-large;">{{with .Data.Keyword}}{{.}} {{end}}<b><a href="{{.PackageHref .Data.Package.Path}}">{{.Data.Package.Path}}</a>.{{.ResourceIndex .Data.Resource}}
+{{- if .Data.Selector}}.{{.Section "selector"}}{{end}}</b></span>
+{{- with .Data.Selector}}<span style="font-size: large;"><i>{{$.T.Text_Parenthesis false}}
+{{- if .Field}}{{$.T.Text_ObjectKind "field"}}{{else}}{{$.T.Text_ObjectKind "method"}}{{$.Section "implementations-link"}}{{end}}
+{{- $.T.Text_Parenthesis true}}</i></span>{{end}}
+
+<span class="title">{{.T.Text_ObjectUses .Data.UsesCount}}</span>
+{{.Section "references"}}</code></pre>`
+
+// NotFound is the template of the 404 page (in docs generation mode).
+// Its Data is nil.
+const NotFound = `{{- /* .Data is nil. */}}
+<pre><code><span style="font-size:xx-large;">404</span>
+
+{{.T.Text_PageNotFound}}
+
+<a href="{{.OverviewHref}}">{{.T.Text_Overview}}</a>
+</code></pre>
+`
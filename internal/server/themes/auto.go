@@ -9,6 +9,8 @@ type Auto struct{}
 
 func (Auto) Name() string { return "auto" }
 
+func (Auto) Dark() bool { return false }
+
 func (Auto) CSS() string { return auto_css }
 
 var auto_css = light_css // default to light_css
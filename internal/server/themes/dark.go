@@ -4,6 +4,8 @@ type Dark struct{}
 
 func (Dark) Name() string { return "dark" }
 
+func (Dark) Dark() bool { return true }
+
 func (Dark) CSS() string { return dark_css }
 
 const dark_css = `
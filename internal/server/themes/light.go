@@ -4,6 +4,8 @@ type Light struct{}
 
 func (Light) Name() string { return "light" }
 
+func (Light) Dark() bool { return false }
+
 func (Light) CSS() string { return light_css }
 
 const light_css = `
@@ -0,0 +1,192 @@
+package themes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// User is a theme defined by the user in a theme directory,
+// which contains a theme.css file and/or a theme.json file.
+//
+// The theme.json file contains the metadata of the theme:
+//
+//	{
+//		"name": "solarized",
+//		"dark": true,
+//		"tokens": {
+//			"keyword": "#859900",
+//			"comment": "color: #586e75; font-style: italic;",
+//			...
+//		}
+//	}
+//
+// The name defaults to the name of the theme directory. The CSS of
+// the theme is the CSS of the dark (if dark is true) or light theme,
+// followed by the content of the theme.css file and the rules built
+// from the syntax-highlight tokens. A token value is either a color
+// or a CSS declaration list. Like the built-in themes, the theme.css
+// file may use {{ .Fonts }} and {{ .Colon }}.
+type User struct {
+	name string
+	dark bool
+	css  string
+}
+
+func (t *User) Name() string { return t.name }
+
+func (t *User) Dark() bool { return t.dark }
+
+func (t *User) CSS() string { return t.css }
+
+// The syntax-highlight tokens and their CSS selectors.
+var userThemeTokens = map[string]string{
+	"ident":            "code .ident",
+	"id-type":          "code .id-type",
+	"id-value":         "code .id-value",
+	"id-function":      "code .id-function",
+	"lit-number":       "code .lit-number",
+	"lit-string":       "code .lit-string",
+	"keyword":          "code .keyword",
+	"comment":          "code .comment",
+	"chosen-ident":     "code.chosen-ident",
+	"chosen-id-import": "code.chosen-id-import",
+}
+
+// Theme names are used in URLs and filenames.
+var validUserThemeName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// UserThemesDir returns the directory of the user themes,
+// which is $UserConfigDir/golds/themes.
+func UserThemesDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "golds", "themes"), nil
+}
+
+// LoadUserThemes loads the themes in the subdirectories of dir.
+// Invalid themes are skipped and the errors for them are returned.
+// It is not an error if dir doesn't exist.
+func LoadUserThemes(dir string) (themes []*User, errs []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, []error{err}
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		t, err := loadUserTheme(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		themes = append(themes, t)
+	}
+	return themes, errs
+}
+
+func loadUserTheme(dir string) (*User, error) {
+	var meta struct {
+		Name   string            `json:"name"`
+		Dark   bool              `json:"dark"`
+		Tokens map[string]string `json:"tokens"`
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "theme.json"))
+	hasMeta := err == nil
+	if hasMeta {
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, fmt.Errorf("theme %s: parse theme.json error: %w", dir, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("theme %s: %w", dir, err)
+	}
+
+	userCSS, err := os.ReadFile(filepath.Join(dir, "theme.css"))
+	if err != nil {
+		if !os.IsNotExist(err) || !hasMeta {
+			return nil, fmt.Errorf("theme %s: %w", dir, err)
+		}
+	}
+
+	if meta.Name == "" {
+		meta.Name = filepath.Base(dir)
+	}
+	if !validUserThemeName.MatchString(meta.Name) {
+		return nil, fmt.Errorf("theme %s: invalid theme name: %q", dir, meta.Name)
+	}
+
+	var css strings.Builder
+	if meta.Dark {
+		css.WriteString(dark_css)
+	} else {
+		css.WriteString(light_css)
+	}
+	css.Write(userCSS)
+	css.WriteByte('\n')
+
+	tokens := make([]string, 0, len(meta.Tokens))
+	for token := range meta.Tokens {
+		if _, ok := userThemeTokens[token]; !ok {
+			return nil, fmt.Errorf("theme %s: unknown syntax-highlight token: %s", dir, token)
+		}
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	for _, token := range tokens {
+		// Each rule is in a separated line, so that the chosen-xxx
+		// ones could be parsed as the ones in the built-in themes.
+		css.WriteString(userThemeTokens[token])
+		css.WriteString(" {")
+		css.WriteString(tokenDeclarations(meta.Tokens[token]))
+		css.WriteString("}\n")
+	}
+
+	if err := checkCSSTemplate(css.String()); err != nil {
+		return nil, fmt.Errorf("theme %s: %w", dir, err)
+	}
+
+	return &User{name: meta.Name, dark: meta.Dark, css: css.String()}, nil
+}
+
+// checkCSSTemplate makes sure that the CSS of a theme could be executed
+// as a template with the data used when serving the CSS file, so that
+// bad theme files are rejected when loading instead of when serving.
+func checkCSSTemplate(css string) error {
+	t, err := template.New("css").Parse(css)
+	if err != nil {
+		return fmt.Errorf("parse css template error: %w", err)
+	}
+	data := struct {
+		Colon string
+		Fonts string
+	}{}
+	if err := t.Execute(ioutil.Discard, data); err != nil {
+		return fmt.Errorf("execute css template error: %w", err)
+	}
+	return nil
+}
+
+// tokenDeclarations converts a token value to a CSS declaration list.
+func tokenDeclarations(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, ":") {
+		value = "color: " + value
+	}
+	if !strings.HasSuffix(value, ";") {
+		value += ";"
+	}
+	return value
+}
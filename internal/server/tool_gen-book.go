@@ -0,0 +1,488 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"go/doc/comment"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go101.org/golds/code"
+	"go101.org/golds/internal/util"
+)
+
+// GenBook generates the docs of the packages in a module as a single
+// self-contained HTML file (book.html), or as an EPUB file (book.epub)
+// if epub is true. The table of contents is ordered by the package
+// hierarchy of the module, and the links between packages in the book
+// are internal anchors.
+//
+// If modulePath is blank, the working directory module is used.
+// If there is not a working directory module, the standard module is used.
+func GenBook(options PageOutputOptions, args []string, outputDir, modulePath string, epub, silentMode bool, printUsage func(io.Writer)) {
+	toolchain, err := findToolchainInfo()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	forTesting := outputDir == ""
+
+	ds := &docServer{}
+	ds.analyze(args, options, toolchain, forTesting, printUsage)
+
+	var module *code.Module
+	if modulePath != "" {
+		module = ds.analyzer.ModuleByPath(modulePath)
+		if module == nil {
+			log.Fatalln("Module", modulePath, "is not found")
+		}
+	} else if module = ds.analyzer.WorkingDirectoryModule(); module == nil {
+		module = ds.analyzer.BuiltinPackge().Module()
+	}
+
+	var buf bytes.Buffer
+	bw := newBookWriter(ds, module)
+	bw.write(&buf, epub)
+	if forTesting {
+		return
+	}
+
+	if err := os.MkdirAll(outputDir, 0700); err != nil {
+		log.Fatalln("Mkdir error:", err)
+	}
+
+	path := filepath.Join(outputDir, "book.html")
+	data := buf.Bytes()
+	if epub {
+		path = filepath.Join(outputDir, "book.epub")
+		var epubBuf bytes.Buffer
+		if err := writeEPUB(&epubBuf, bw.title, options.PreferredLang, data, bw.navXHTML()); err != nil {
+			log.Fatalln("Create EPUB error:", err)
+		}
+		data = epubBuf.Bytes()
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		log.Fatalln("Write file error:", err)
+	}
+	if !silentMode {
+		log.Printf("Generated %s (size: %d).", path, len(data))
+	}
+
+	log.Printf("The book of %d packages is generated in %s.", len(bw.pkgs), outputDir)
+}
+
+// bookTocItem is an item in the table of contents of a book.
+// pkg is a fake package for a directory without source files.
+type bookTocItem struct {
+	pkg   *code.Package
+	depth int
+}
+
+type bookWriter struct {
+	ds    *docServer
+	title string
+
+	toc  []bookTocItem
+	pkgs []*code.Package
+
+	// The anchor IDs of the packages in the book.
+	pkgIDs map[*code.Package]string
+}
+
+func newBookWriter(ds *docServer, module *code.Module) *bookWriter {
+	bw := &bookWriter{ds: ds, title: module.Path, pkgIDs: make(map[*code.Package]string)}
+	if bw.title == "" {
+		bw.title = "Standard Packages"
+	} else if module.Version != "" {
+		bw.title += "@" + module.Version
+	}
+
+	base := 0
+	module.IteratePackageHierarchy(func(pkg *code.Package, depth int) {
+		if !pkg.IsFake() {
+			bw.pkgIDs[pkg] = fmt.Sprintf("pkg-%d", len(bw.pkgs))
+			bw.pkgs = append(bw.pkgs, pkg)
+		} else if depth == 0 { // the root of a module without root package
+			base = 1
+			return
+		}
+		bw.toc = append(bw.toc, bookTocItem{pkg: pkg, depth: depth - base})
+	})
+	return bw
+}
+
+// bookStyle is simple and printing friendly.
+const bookStyle = `
+body {font-family: serif; line-height: 1.5; max-width: 50em; margin: 0 auto; padding: 1em;}
+pre, code {font-family: monospace; font-size: 90%;}
+pre {white-space: pre-wrap; background: #f6f6f6; padding: 0.5em;}
+nav li {list-style: none;}
+section.package {page-break-before: always; break-before: page;}
+h2 small {font-weight: normal; font-size: 60%;}
+a {color: #2050a0; text-decoration: none;}
+`
+
+// write writes the book as an HTML5 document (also a valid XHTML document,
+// so that it could be used as the content document of an EPUB file).
+func (bw *bookWriter) write(w io.Writer, xhtml bool) {
+	if xhtml {
+		io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml">
+`)
+	} else {
+		io.WriteString(w, "<!DOCTYPE html>\n<html>\n")
+	}
+	io.WriteString(w, `<head>
+<meta charset="utf-8"/>
+<title>`)
+	util.NewHTMLEscapeWriter(w).WriteString(bw.title)
+	fmt.Fprintf(w, "</title>\n<style>%s</style>\n</head>\n<body>\n<h1>", bookStyle)
+	util.NewHTMLEscapeWriter(w).WriteString(bw.title)
+	io.WriteString(w, "</h1>\n")
+
+	io.WriteString(w, `<nav id="toc">`+"\n<h2>Contents</h2>\n")
+	bw.writeToc(w)
+	io.WriteString(w, "</nav>\n")
+
+	for _, pkg := range bw.pkgs {
+		details := buildPackageDetailsData(bw.ds.analyzer, pkg.Path, collectUnexporteds)
+		bp := &bookPackagePrinter{docPrinter{ds: bw.ds, pkg: pkg, details: details, out: w}, bw}
+		bp.printPackage()
+	}
+
+	io.WriteString(w, "</body>\n</html>\n")
+}
+
+// writeToc writes the table of contents as nested lists.
+func (bw *bookWriter) writeToc(w io.Writer) {
+	escaper := util.NewHTMLEscapeWriter(w)
+	numOpenLists := 0
+	for _, item := range bw.toc {
+		if level := item.depth + 1; level > numOpenLists {
+			for ; numOpenLists < level; numOpenLists++ {
+				io.WriteString(w, "<ul>\n<li>")
+			}
+		} else {
+			for ; numOpenLists > level; numOpenLists-- {
+				io.WriteString(w, "</li>\n</ul>\n")
+			}
+			io.WriteString(w, "</li>\n<li>")
+		}
+		if id, ok := bw.pkgIDs[item.pkg]; ok {
+			fmt.Fprintf(w, `<a href="#%s">`, id)
+			escaper.WriteString(item.pkg.Path)
+			io.WriteString(w, "</a>")
+		} else {
+			escaper.WriteString(item.pkg.Path)
+		}
+	}
+	for ; numOpenLists > 0; numOpenLists-- {
+		io.WriteString(w, "</li>\n</ul>\n")
+	}
+}
+
+// navXHTML returns the EPUB navigation document, which only
+// lists the packages in the book.
+func (bw *bookWriter) navXHTML() []byte {
+	var buf bytes.Buffer
+	escaper := util.NewHTMLEscapeWriter(&buf)
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>`)
+	escaper.WriteString(bw.title)
+	buf.WriteString(`</title></head>
+<body>
+<nav epub:type="toc" id="toc">
+<h1>Contents</h1>
+<ol>
+`)
+	for _, pkg := range bw.pkgs {
+		fmt.Fprintf(&buf, `<li><a href="book.xhtml#%s">`, bw.pkgIDs[pkg])
+		escaper.WriteString(pkg.Path)
+		buf.WriteString("</a></li>\n")
+	}
+	buf.WriteString("</ol>\n</nav>\n</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// writeEPUB writes an EPUB 3 file, which contains the content document
+// and the navigation document.
+func writeEPUB(w io.Writer, title, lang string, content, nav []byte) error {
+	if lang == "" {
+		lang = "en"
+	}
+	var escapedTitle strings.Builder
+	util.NewHTMLEscapeWriter(&escapedTitle).WriteString(title)
+
+	zw := zip.NewWriter(w)
+	// The mimetype file must be the first one and must not be compressed.
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	io.WriteString(f, "application/epub+zip")
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{"META-INF/container.xml", []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`)},
+		{"OEBPS/content.opf", []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="book-id">golds:%[1]s</dc:identifier>
+<dc:title>%[1]s</dc:title>
+<dc:language>%[2]s</dc:language>
+<meta property="dcterms:modified">%[3]s</meta>
+</metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="book" href="book.xhtml" media-type="application/xhtml+xml"/>
+</manifest>
+<spine>
+<itemref idref="book"/>
+</spine>
+</package>
+`, escapedTitle.String(), lang, time.Now().UTC().Format("2006-01-02T15:04:05Z")))},
+		{"OEBPS/nav.xhtml", nav},
+		{"OEBPS/book.xhtml", content},
+	}
+	for _, file := range files {
+		f, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(file.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// bookPackagePrinter prints the details of a package in a book.
+type bookPackagePrinter struct {
+	docPrinter
+	bw *bookWriter
+}
+
+func (bp *bookPackagePrinter) escape(s string) {
+	util.NewHTMLEscapeWriter(bp.out).WriteString(s)
+}
+
+// resourceURL returns a blank string if the resource is not in the book.
+func (bp *bookPackagePrinter) resourceURL(p *code.Package, res code.Resource) string {
+	id, ok := bp.bw.pkgIDs[p]
+	if !ok || !res.Exported() && !collectUnexporteds {
+		return ""
+	}
+	return "#" + id + "-" + res.Name()
+}
+
+func (bp *bookPackagePrinter) printCodeLink(text, url string) {
+	if url != "" {
+		fmt.Fprintf(bp.out, `<a href="%s"><code>`, url)
+		bp.escape(text)
+		io.WriteString(bp.out, "</code></a>")
+	} else {
+		io.WriteString(bp.out, "<code>")
+		bp.escape(text)
+		io.WriteString(bp.out, "</code>")
+	}
+}
+
+func (bp *bookPackagePrinter) printCode(code string) {
+	code = strings.TrimRight(code, "\n")
+	if code == "" {
+		return
+	}
+	io.WriteString(bp.out, "<pre>")
+	bp.escape(code)
+	io.WriteString(bp.out, "</pre>\n")
+}
+
+func (bp *bookPackagePrinter) printDoc(doc string) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+	printer := &comment.Printer{
+		HeadingLevel: 5,
+		HeadingID:    func(*comment.Heading) string { return "" },
+		DocLinkURL: func(link *comment.DocLink) string {
+			pkg, res := bp.docLinkTarget(link)
+			switch {
+			case pkg == nil:
+				return ""
+			case res == nil:
+				if id, ok := bp.bw.pkgIDs[pkg]; ok {
+					return "#" + id
+				}
+				return ""
+			default:
+				return bp.resourceURL(pkg, res)
+			}
+		},
+	}
+	bp.out.Write(printer.HTML(bp.parseDocComment(doc)))
+}
+
+func (bp *bookPackagePrinter) printDeclaration(res code.Resource) {
+	var buf bytes.Buffer
+	dp := bp.docPrinter
+	dp.out = &buf
+	dp.printDeclaration(res)
+	bp.printCode(buf.String())
+}
+
+func (bp *bookPackagePrinter) printPackage() {
+	details := bp.details
+	fmt.Fprintf(bp.out, "<section class=\"package\" id=\"%s\">\n<h2>Package ", bp.bw.pkgIDs[bp.pkg])
+	bp.escape(details.Name)
+	io.WriteString(bp.out, " <small>")
+	bp.escape(details.ImportPath)
+	io.WriteString(bp.out, "</small></h2>\n")
+
+	for _, f := range details.Files {
+		bp.printDoc(f.DocText)
+	}
+
+	printValues := func(title string, values []ResourceWithPosition) {
+		if len(values) == 0 {
+			return
+		}
+		fmt.Fprintf(bp.out, "<h3>%s</h3>\n", title)
+		for _, rwp := range values {
+			bp.printValue(rwp.Value)
+		}
+	}
+	printValues("Constants", details.Constants)
+	printValues("Variables", details.Variables)
+	printValues("Functions", details.Functions)
+
+	if len(details.TypeNames) > 0 {
+		io.WriteString(bp.out, "<h3>Types</h3>\n")
+		for _, rwp := range details.TypeNames {
+			bp.printType(rwp.Type)
+		}
+	}
+
+	bp.printExamples()
+	io.WriteString(bp.out, "</section>\n")
+}
+
+func (bp *bookPackagePrinter) printResourceHeading(res code.Resource) {
+	if url := bp.resourceURL(bp.pkg, res); url != "" {
+		fmt.Fprintf(bp.out, "<h4 id=\"%s\">", url[1:])
+	} else {
+		io.WriteString(bp.out, "<h4>")
+	}
+	bp.escape(markdownResourceHeading(res))
+	io.WriteString(bp.out, "</h4>\n")
+}
+
+func (bp *bookPackagePrinter) printValue(v code.ValueResource) {
+	bp.printResourceHeading(v)
+	bp.printDeclaration(v)
+	bp.printDoc(v.Documentation())
+}
+
+func (bp *bookPackagePrinter) printType(td *TypeDetails) {
+	tn := td.TypeName
+	bp.printResourceHeading(tn)
+	bp.printDeclaration(tn)
+	bp.printDoc(tn.Documentation())
+
+	printList := func(title string, n int, printItem func(i int)) {
+		if n == 0 {
+			return
+		}
+		fmt.Fprintf(bp.out, "<p><b>%s (%d):</b></p>\n<ul>\n", title, n)
+		for i := 0; i < n; i++ {
+			io.WriteString(bp.out, "<li>")
+			printItem(i)
+			io.WriteString(bp.out, "</li>\n")
+		}
+		io.WriteString(bp.out, "</ul>\n")
+	}
+	printTypes := func(title string, tfls []*TypeForListing) {
+		printList(title, len(tfls), func(i int) {
+			var url string
+			if tn := tfls[i].BaseType.TypeName; tn != nil {
+				url = bp.resourceURL(tn.Package(), tn)
+			}
+			bp.printCodeLink(bp.typeForListingText(tfls[i]), url)
+		})
+	}
+	printValues := func(title string, vfls []*ValueForListing) {
+		texts := bp.valuesForListingText(vfls)
+		printList(title, len(vfls), func(i int) {
+			var url string
+			if f, ok := vfls[i].ValueResource.(code.FunctionResource); ok && f.IsMethod() {
+				if _, tn, _ := f.ReceiverTypeName(); tn != nil {
+					url = bp.resourceURL(tn.Package(), tn)
+				}
+			} else {
+				url = bp.resourceURL(vfls[i].Package(), vfls[i].ValueResource)
+			}
+			bp.printCodeLink(texts[i], url)
+		})
+	}
+
+	printList("Fields", len(td.Fields), func(i int) {
+		bp.printCodeLink(bp.selectorText(td.Fields[i].Selector), "")
+	})
+	printList("Methods", len(td.Methods), func(i int) {
+		bp.printCodeLink(bp.selectorText(td.Methods[i]), "")
+	})
+	printTypes("Implemented By", td.ImplementedBys)
+	printTypes("Implements", td.Implements)
+	printValues("Values", td.Values)
+	printValues("As Inputs Of", td.AsInputsOf)
+	printValues("As Outputs Of", td.AsOutputsOf)
+}
+
+func (bp *bookPackagePrinter) printExamples() {
+	examples := bp.details.Examples
+	if len(examples) == 0 {
+		return
+	}
+
+	io.WriteString(bp.out, "<h3>Examples</h3>\n")
+	for _, ex := range examples {
+		io.WriteString(bp.out, "<h4>Example")
+		if ex.Name != "" {
+			io.WriteString(bp.out, " ")
+			bp.escape(ex.Name)
+		}
+		io.WriteString(bp.out, "</h4>\n")
+		bp.printDoc(ex.Doc)
+
+		var buf bytes.Buffer
+		if ex.Play != nil {
+			format.Node(&buf, bp.details.ExampleFileSet, ex.Play)
+		} else {
+			format.Node(&buf, bp.details.ExampleFileSet, ex.Code)
+		}
+		bp.printCode(buf.String())
+
+		if ex.Output != "" {
+			io.WriteString(bp.out, "<p>Output:</p>\n")
+			bp.printCode(ex.Output)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The 404 page might be served for any path, so it uses a <base>
+// element instead of a canonical link (see writeDocsBaseURLLink).
+// Its links are relative to the docs directory.
+var notFoundPagePathInfo = pagePathInfo{ResTypeNone, "404"}
+
+// normalizeDocsBaseURL validates the -base-url option value,
+// which must be an absolute URL, and appends a "/" to it if needed.
+func normalizeDocsBaseURL(baseURL string) string {
+	if baseURL == "" {
+		return ""
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		log.Fatalln("-base-url: not an absolute URL:", baseURL)
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return baseURL
+}
+
+// docsPageURL returns the absolute URL of a generated page.
+func docsPageURL(filePath string) string {
+	return docsBaseURL + escapeURLPath(filePath)
+}
+
+// moduleDocsURL returns the absolute URL of a file in the module docs
+// directory, which contains the docs directories of all versions.
+// It is only valid for versioned docs.
+func moduleDocsURL(filePath string) string {
+	base, err := url.Parse(docsBaseURL)
+	if err != nil {
+		panic("should not") // validated in normalizeDocsBaseURL
+	}
+	return base.ResolveReference(&url.URL{Path: "../" + filePath}).String()
+}
+
+func escapeURLPath(filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func writeDocsBaseURLLink(page *htmlPage, currentPageInfo pagePathInfo) {
+	if currentPageInfo == notFoundPagePathInfo {
+		fmt.Fprintf(page, `<base href="%s">
+`, docsBaseURL)
+		return
+	}
+	fmt.Fprintf(page, `<link rel="canonical" href="%s">
+`, docsPageURL(generatedPageFilePath(currentPageInfo)))
+}
+
+func (ds *docServer) notFoundPage(w http.ResponseWriter) ([]byte, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	page := NewHtmlPage(goldsVersion, ds.currentTranslation.Text_PageNotFound(), ds.currentTheme, ds.currentTranslation, notFoundPagePathInfo)
+	if err := ds.writePageTemplate(page, "not-found.html", nil, nil); err != nil {
+		return nil, err
+	}
+	return page.Done(w), nil
+}
+
+// writeStaticHostingFiles writes the files needed when hosting the
+// generated docs on static hosting services, such as GitHub Pages:
+// * sitemap.xml (and sitemap-N.xml if there are too many pages) in docsDir.
+// * robots.txt, .nojekyll and 404.html in the output root.
+// docsDir is relative to the output root, and it is blank
+// except for versioned docs. For versioned docs, versions are
+// all the versions in the module docs directory, and a sitemap
+// index of the sitemaps of all the versions is written in the
+// module docs directory, so that the docs of old versions are
+// still listed after the docs of a new version are generated.
+func writeStaticHostingFiles(output *docsOutput, docsDir string, htmlPages []string, notFoundPage Content, versions []string) error {
+	if err := writeSitemaps(output, docsDir, htmlPages); err != nil {
+		return err
+	}
+
+	sitemapURL := docsPageURL("sitemap.xml")
+	if len(versions) > 0 {
+		if err := writeVersionsSitemapIndex(output, path.Dir(docsDir), versions); err != nil {
+			return err
+		}
+		sitemapURL = moduleDocsURL("sitemap.xml")
+	}
+
+	robots := "User-agent: *\nAllow: /\nSitemap: " + sitemapURL + "\n"
+	if _, err := output.writeFile("robots.txt", Content{[]byte(robots)}); err != nil {
+		return err
+	}
+
+	// Disable Jekyll processing on GitHub Pages, which ignores
+	// the files and directories starting with "_".
+	if _, err := output.writeFile(".nojekyll", nil); err != nil {
+		return err
+	}
+
+	_, err := output.writeFile("404.html", notFoundPage)
+	return err
+}
+
+// A sitemap file may contain at most 50000 URLs.
+const maxSitemapURLs = 50000
+
+// writeSitemaps writes sitemap.xml in dir. If there are more than
+// maxSitemapURLs pages, the pages are listed in several sitemap-N.xml
+// files and sitemap.xml is a sitemap index of them.
+func writeSitemaps(output *docsOutput, dir string, htmlPages []string) error {
+	sort.Strings(htmlPages)
+
+	if len(htmlPages) <= maxSitemapURLs {
+		var buf bytes.Buffer
+		writeSitemap(&buf, htmlPages)
+		_, err := output.writeFile(path.Join(dir, "sitemap.xml"), Content{buf.Bytes()})
+		return err
+	}
+
+	var sitemaps []string
+	for start := 0; start < len(htmlPages); start += maxSitemapURLs {
+		end := start + maxSitemapURLs
+		if end > len(htmlPages) {
+			end = len(htmlPages)
+		}
+		sitemap := fmt.Sprintf("sitemap-%d.xml", len(sitemaps)+1)
+		var buf bytes.Buffer
+		writeSitemap(&buf, htmlPages[start:end])
+		if _, err := output.writeFile(path.Join(dir, sitemap), Content{buf.Bytes()}); err != nil {
+			return err
+		}
+		sitemaps = append(sitemaps, sitemap)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+	for _, sitemap := range sitemaps {
+		writeSitemapLoc(&buf, "sitemap", docsPageURL(sitemap))
+	}
+	buf.WriteString("</sitemapindex>\n")
+	_, err := output.writeFile(path.Join(dir, "sitemap.xml"), Content{buf.Bytes()})
+	return err
+}
+
+// writeVersionsSitemapIndex writes sitemap.xml in the module docs
+// directory moduleDir, which is a sitemap index of the sitemaps of
+// all the versions. A sitemap index may not list other sitemap indexes,
+// so the sitemaps listed in the sitemap index of a version are listed
+// instead. The versions generated without -base-url are skipped.
+func writeVersionsSitemapIndex(output *docsOutput, moduleDir string, versions []string) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+	for _, v := range versions {
+		data, err := ioutil.ReadFile(filepath.Join(output.dir, filepath.FromSlash(moduleDir), v, "sitemap.xml"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		var sitemap struct {
+			XMLName  xml.Name
+			Sitemaps []struct {
+				Loc string `xml:"loc"`
+			} `xml:"sitemap"`
+		}
+		if err := xml.Unmarshal(data, &sitemap); err != nil {
+			return fmt.Errorf("parse sitemap of version %s error: %w", v, err)
+		}
+		if sitemap.XMLName.Local != "sitemapindex" {
+			writeSitemapLoc(&buf, "sitemap", moduleDocsURL(v+"/sitemap.xml"))
+			continue
+		}
+		for _, s := range sitemap.Sitemaps {
+			writeSitemapLoc(&buf, "sitemap", s.Loc)
+		}
+	}
+	buf.WriteString("</sitemapindex>\n")
+	_, err := output.writeFile(path.Join(moduleDir, "sitemap.xml"), Content{buf.Bytes()})
+	return err
+}
+
+func writeSitemap(w io.Writer, htmlPages []string) {
+	io.WriteString(w, xml.Header)
+	io.WriteString(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+"\n")
+	for _, page := range htmlPages {
+		writeSitemapLoc(w, "url", docsPageURL(page))
+	}
+	io.WriteString(w, "</urlset>\n")
+}
+
+func writeSitemapLoc(w io.Writer, element, loc string) {
+	fmt.Fprintf(w, "<%s><loc>", element)
+	xml.EscapeText(w, []byte(loc))
+	fmt.Fprintf(w, "</loc></%s>\n", element)
+}
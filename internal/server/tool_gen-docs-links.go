@@ -0,0 +1,202 @@
+package server
+
+import (
+	"bytes"
+	"io/fs"
+	"log"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// brokenLink is a link in a generated page, whose target
+// file doesn't exist or whose "name-..." anchor is not found.
+type brokenLink struct {
+	Page string // the path of the page containing the link
+	Href string
+}
+
+// docsLinkChecker checks the links in the generated pages.
+// The paths used in it are slash-separated and relative to
+// the root of the checked file system.
+type docsLinkChecker struct {
+	fsys fs.FS
+
+	existences map[string]bool
+	anchors    map[string]map[string]bool // the "name-..." ids of pages
+}
+
+// checkDocsLinks checks the links in the HTML pages in docsDir,
+// which is relative to the root of fsys ("." means the root).
+// Links out of the root are viewed as broken.
+func checkDocsLinks(fsys fs.FS, docsDir string) ([]brokenLink, error) {
+	lc := &docsLinkChecker{
+		fsys:       fsys,
+		existences: make(map[string]bool, 1024),
+		anchors:    make(map[string]map[string]bool, 1024),
+	}
+
+	var broken []brokenLink
+	err := fs.WalkDir(fsys, docsDir, func(pagePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(pagePath, ".html") {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, pagePath)
+		if err != nil {
+			return err
+		}
+		hrefs, ids, hasBase := parsePageLinks(data)
+		lc.anchors[pagePath] = ids
+		// The links in a page with a <base> element (the 404 page)
+		// are relative to the deployment URL, so they are not checked.
+		if hasBase {
+			return nil
+		}
+		for _, href := range hrefs {
+			if !lc.linkValid(pagePath, href) {
+				broken = append(broken, brokenLink{Page: pagePath, Href: href})
+			}
+		}
+		return nil
+	})
+	return broken, err
+}
+
+// parsePageLinks returns the href and src attribute values and
+// the "name-..." ids in an HTML page, and whether or not the page
+// contains a <base> element.
+func parsePageLinks(data []byte) (hrefs []string, ids map[string]bool, hasBase bool) {
+	ids = make(map[string]bool, 32)
+	hrefSet := make(map[string]bool, 256)
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			sort.Strings(hrefs)
+			return
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) == "base" {
+				hasBase = true
+			}
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				switch string(key) {
+				case "href", "src":
+					if href := string(val); href != "" && !hrefSet[href] {
+						hrefSet[href] = true
+						hrefs = append(hrefs, href)
+					}
+				case "id":
+					if bytes.HasPrefix(val, []byte("name-")) {
+						ids[string(val)] = true
+					}
+				}
+			}
+		}
+	}
+}
+
+func (lc *docsLinkChecker) linkValid(pagePath, href string) bool {
+	u, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "" || u.Host != "" {
+		return true // external links are not checked
+	}
+
+	target := pagePath
+	if u.Path != "" {
+		target = path.Join(path.Dir(pagePath), u.Path)
+		if target == ".." || strings.HasPrefix(target, "../") {
+			return false
+		}
+		if !lc.fileExists(target) {
+			return false
+		}
+	}
+
+	if !strings.HasPrefix(u.Fragment, "name-") || !strings.HasSuffix(target, ".html") {
+		return true
+	}
+	return lc.anchorsOf(target)[u.Fragment]
+}
+
+func (lc *docsLinkChecker) fileExists(filePath string) bool {
+	exists, ok := lc.existences[filePath]
+	if !ok {
+		info, err := fs.Stat(lc.fsys, filePath)
+		exists = err == nil && !info.IsDir()
+		lc.existences[filePath] = exists
+	}
+	return exists
+}
+
+// anchorsOf returns the "name-..." ids of a page. The page
+// might be out of the checked directory, so it is parsed lazily.
+func (lc *docsLinkChecker) anchorsOf(pagePath string) map[string]bool {
+	ids, ok := lc.anchors[pagePath]
+	if !ok {
+		if data, err := fs.ReadFile(lc.fsys, pagePath); err == nil {
+			_, ids, _ = parsePageLinks(data)
+		}
+		lc.anchors[pagePath] = ids
+	}
+	return ids
+}
+
+var pageBuilderNames = map[pageResType]string{
+	ResTypeNone:           "overview and other",
+	ResTypeModule:         "module",
+	ResTypePackage:        "package details",
+	ResTypeDependency:     "package dependencies",
+	ResTypeImplementation: "implementations",
+	ResTypeSource:         "source",
+	ResTypeReference:      "references",
+}
+
+// pageBuilderName returns the name of the builder of a generated page.
+// pagePath is relative to the docs directory.
+func pageBuilderName(pagePath string) string {
+	resType := ResTypeNone
+	if i := strings.IndexByte(pagePath, '/'); i >= 0 {
+		resType = pageResType(pagePath[:i])
+	}
+	if name, ok := pageBuilderNames[resType]; ok {
+		return name
+	}
+	return string(resType)
+}
+
+// reportBrokenLinks logs the broken links, grouped by
+// the builders of the pages containing them.
+func reportBrokenLinks(broken []brokenLink, docsDir string) {
+	groups := make(map[string][]brokenLink)
+	for _, bl := range broken {
+		if docsDir != "." {
+			bl.Page = strings.TrimPrefix(bl.Page, docsDir+"/")
+		}
+		name := pageBuilderName(bl.Page)
+		groups[name] = append(groups[name], bl)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		log.Printf("Broken links in %s pages (%d):", name, len(groups[name]))
+		for _, bl := range groups[name] {
+			log.Printf("\t%s: %s", bl.Page, bl.Href)
+		}
+	}
+}
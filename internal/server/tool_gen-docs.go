@@ -1,23 +1,35 @@
 package server
 
 import (
+	"bytes"
 	"container/list"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"go/token"
 	"io"
+	"io/fs"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+
+	"golang.org/x/mod/semver"
+
+	"go101.org/golds/code"
+	"go101.org/golds/internal/util"
 )
 
 var _ = runtime.GC
@@ -34,7 +46,12 @@ var (
 	hashedScopes map[string]string
 	hashedTokens map[string]string
 
-	pageHrefsMutex sync.Mutex // in fact, for the current implementation, the lock is not essential
+	// The number of the pages being built. Pages are built concurrently,
+	// and more pages might be registered when building a page.
+	numLoadingPages int
+
+	pageHrefsMutex  sync.Mutex
+	pageLoadingCond = sync.NewCond(&pageHrefsMutex)
 )
 
 func enabledHtmlGenerationMod() {
@@ -42,6 +59,7 @@ func enabledHtmlGenerationMod() {
 	enabledPageCache = false
 
 	pageHrefList = list.New()
+	numLoadingPages = 0
 	resHrefs = make(map[pageResType]map[string]int, 16)
 	pageHrefs = make(map[pagePathInfo]string, 65536)
 
@@ -156,16 +174,64 @@ func registerPageHref(info genPageInfo) {
 	pageHrefsMutex.Lock()
 	defer pageHrefsMutex.Unlock()
 	pageHrefList.PushBack(&info)
+	pageLoadingCond.Signal()
 }
 
+// nextPageToLoad returns the next registered page to build.
+// If there are no registered pages but some pages are being built,
+// it waits, for more pages might be registered when building them.
+// It returns nil if all the registered pages have been built.
+// pageLoaded must be called after the returned page is built.
 func nextPageToLoad() (info *genPageInfo) {
 	pageHrefsMutex.Lock()
 	defer pageHrefsMutex.Unlock()
-	if front := pageHrefList.Front(); front != nil {
-		info = front.Value.(*genPageInfo)
-		pageHrefList.Remove(front)
+	for {
+		if front := pageHrefList.Front(); front != nil {
+			info = front.Value.(*genPageInfo)
+			pageHrefList.Remove(front)
+			numLoadingPages++
+			return
+		}
+		if numLoadingPages == 0 {
+			return nil
+		}
+		pageLoadingCond.Wait()
+	}
+}
+
+func pageLoaded() {
+	pageHrefsMutex.Lock()
+	defer pageHrefsMutex.Unlock()
+	numLoadingPages--
+	if numLoadingPages == 0 {
+		// Wake up the waiting loaders to exit.
+		pageLoadingCond.Broadcast()
+	}
+}
+
+// seedHashedIdentifiers calls hashedIdentifier for the package-level
+// identifiers and the selectors of types in a fixed order, so that
+// which identifiers are hashed in generated file paths doesn't depend
+// on the order in which pages are built concurrently.
+func seedHashedIdentifiers(analyzer *code.CodeAnalyzer) {
+	for i, n := 0, analyzer.NumPackages(); i < n; i++ {
+		pkg := analyzer.PackageAt(i)
+		for _, tn := range pkg.AllTypeNames {
+			hashedIdentifier(tn.Name())
+			for _, sel := range tn.Denoting.DirectSelectors {
+				hashedIdentifier(sel.Name())
+			}
+		}
+		for _, f := range pkg.AllFunctions {
+			hashedIdentifier(f.Name())
+		}
+		for _, v := range pkg.AllVariables {
+			hashedIdentifier(v.Name())
+		}
+		for _, c := range pkg.AllConstants {
+			hashedIdentifier(c.Name())
+		}
 	}
-	return
 }
 
 func cachePageHref(pathInfo pagePathInfo, href string) {
@@ -217,6 +283,24 @@ func resType2ExtTable(res pageResType) string {
 	return ext
 }
 
+// generatedPageFilePath returns the path (relative to the
+// docs generation directory) of the file of a generated page.
+func generatedPageFilePath(pathInfo pagePathInfo) string {
+	switch pathInfo.resType {
+	case ResTypeCSS, ResTypeJS, ResTypePNG:
+		if sharedAssetsPathPrefix != "" {
+			return sharedAssetsPathPrefix + string(pathInfo.resType) + "/" + pathInfo.resPath + resType2ExtTable(pathInfo.resType)
+		}
+	}
+	if pathInfo.resType == ResTypeNone {
+		if pathInfo.resPath == "" {
+			return "index" + resType2ExtTable(pathInfo.resType)
+		}
+		return pathInfo.resPath + resType2ExtTable(pathInfo.resType)
+	}
+	return string(pathInfo.resType) + "/" + pathInfo.resPath + resType2ExtTable(pathInfo.resType)
+}
+
 var dotdotslashes = strings.Repeat("../", 256)
 
 func DotDotSlashes(count int) string {
@@ -416,21 +500,30 @@ Generate:
 		panic("method-implementation page (" + linkedPageInfo.resPath + ") should not be build")
 	}
 
+	if linkedPageInfo.resType == ResTypePackage && externalDocsURL != "" {
+		if pkgPath := deHashScope(linkedPageInfo.resPath); !isGeneratedPackage(pkgPath) {
+			href := externalDocsHref(pkgPath, fragments)
+			writeLink := func(w writer) {
+				writePageLink(func() {
+					w.WriteString(href)
+				}, w, linkText)
+			}
+			if page != nil {
+				writeLink(page)
+			} else {
+				r = buildString(writeLink)
+			}
+			return
+		}
+	}
+
 	var makeHref = func(pathInfo pagePathInfo) (href string) {
 		href = cachedPageHref(pathInfo)
 		if href != "" {
 			return
 		}
 
-		if pathInfo.resType == ResTypeNone {
-			if pathInfo.resPath == "" {
-				href = "index" + resType2ExtTable(pathInfo.resType)
-			} else {
-				href = pathInfo.resPath + resType2ExtTable(pathInfo.resType)
-			}
-		} else {
-			href = string(pathInfo.resType) + "/" + pathInfo.resPath + resType2ExtTable(pathInfo.resType)
-		}
+		href = generatedPageFilePath(pathInfo)
 		cachePageHref(pathInfo, href)
 		return
 	}
@@ -488,7 +581,108 @@ Generate:
 	return
 }
 
+// isGeneratedPackage returns whether or not the full docs pages
+// are generated for the specified package.
+func isGeneratedPackage(pkgPath string) bool {
+	return generatedPackages == nil || generatedPackages[pkgPath]
+}
+
+// externalDocsHref returns the href of a not generated package on the
+// external docs website. Only "name-" fragments are kept, in the form
+// used by pkg.go.dev.
+func externalDocsHref(pkgPath string, fragments []string) string {
+	href := externalDocsURL + strings.TrimPrefix(pkgPath, "vendor/")
+	if len(fragments) > 0 && strings.HasPrefix(fragments[0], "name-") {
+		href += "#" + strings.TrimPrefix(strings.Join(fragments, ""), "name-")
+	}
+	return href
+}
+
+// selectGeneratedPackages returns the set of the packages specified
+// by the -generated-packages option, or nil for all packages.
+// The option value is "all", or a comma-separated list, each item
+// of which is "wd" (the packages in the working directory module)
+// or a package pattern (see packagePatternMatcher).
+func selectGeneratedPackages(analyzer *code.CodeAnalyzer, option string) map[string]bool {
+	if option == "" || option == "all" {
+		return nil
+	}
+
+	var matchers []func(*code.Package) bool
+	for _, item := range strings.Split(option, ",") {
+		switch item = strings.TrimSpace(item); item {
+		case "":
+		case "all":
+			return nil
+		case "wd":
+			wdModule := analyzer.WorkingDirectoryModule()
+			if wdModule == nil {
+				log.Fatalln("-generated-packages: the working directory is not in a module")
+			}
+			matchers = append(matchers, func(pkg *code.Package) bool {
+				return pkg.Module() == wdModule
+			})
+		default:
+			match := packagePatternMatcher(item)
+			matchers = append(matchers, func(pkg *code.Package) bool {
+				return match(pkg.Path)
+			})
+		}
+	}
+
+	selected := make(map[string]bool)
+	for i, n := 0, analyzer.NumPackages(); i < n; i++ {
+		pkg := analyzer.PackageAt(i)
+		for _, match := range matchers {
+			if match(pkg) {
+				selected[pkg.Path] = true
+				break
+			}
+		}
+	}
+	if len(selected) == 0 {
+		log.Fatalln("-generated-packages: no packages match", option)
+	}
+	return selected
+}
+
+// packagePatternMatcher returns a function which reports whether
+// or not a package path matches the pattern, in which "..." matches
+// any string. As the go command, "x/..." also matches "x".
+func packagePatternMatcher(pattern string) func(string) bool {
+	re := regexp.MustCompile("^" + strings.Replace(regexp.QuoteMeta(pattern), `\.\.\.`, ".*", -1) + "$")
+	prefix := strings.TrimSuffix(pattern, "/...")
+	return func(pkgPath string) bool {
+		return pkgPath == prefix || re.MatchString(pkgPath)
+	}
+}
+
 func GenDocs(options PageOutputOptions, args []string, outputDir string, silentMode bool, printUsage func(io.Writer), increaseGCFrequency bool, viewDocsCommand func(string) string) {
+	ds := genDocPages(options, args, outputDir, silentMode, printUsage, increaseGCFrequency)
+	if outputDir == "" { // for testing
+		return
+	}
+
+	if docsVersion != "" {
+		log.Printf("Docs (version %s) are generated in %s.", docsVersion, versionedDocsDir(outputDir, ds.analyzer.WorkingDirectoryModule()))
+	} else {
+		log.Printf("Docs are generated in %s.", outputDir) // genOutputDir)
+	}
+	if sourceReadingStyle == SourceReadingStyle_external {
+		for _, w := range ds.localRepositoryWarnings {
+			log.Println("!!! Warning:", w)
+		}
+		if len(ds.localRepositoryWarnings) > 0 {
+			log.Println()
+		}
+	}
+	log.Println("Run the following command to view the docs:")
+	log.Printf("\t%s", viewDocsCommand(outputDir)) // genOutputDir))
+}
+
+// genDocPages generates the HTML docs pages into outputDir
+// and returns the doc server used to build the pages.
+func genDocPages(options PageOutputOptions, args []string, outputDir string, silentMode bool, printUsage func(io.Writer), increaseGCFrequency bool) *docServer {
 	toolchain, err := findToolchainInfo()
 	if err != nil {
 		log.Fatal(err)
@@ -504,23 +698,57 @@ func GenDocs(options PageOutputOptions, args []string, outputDir string, silentM
 	// ...
 	ds := &docServer{}
 	ds.analyze(args, options, toolchain, forTesting, printUsage)
+	generatedPackages = selectGeneratedPackages(ds.analyzer, options.GeneratedPackages)
 
 	// ...
 	genOutputDir := outputDir
 	if genOutputDir == "." {
 		genOutputDir = ds.initialWorkingDirectory
 	}
+
+	// For versioned docs, the pages are generated into
+	// <genOutputDir>/<module path>/<version>, and the CSS, JS
+	// and image files are shared in <genOutputDir>/assets.
+	docsVersion, sharedAssetsPathPrefix = "", ""
+	docsBaseURL = normalizeDocsBaseURL(options.BaseURL)
+	output := &docsOutput{dir: genOutputDir}
+	docsDir := "" // relative to the output root
+	var moduleDocsDir string
+	if !forTesting && util.IsArchivePath(genOutputDir) {
+		if options.DocsVersion != "" {
+			log.Fatalln("-docs-version: versioned docs can't be generated into an archive file")
+		}
+		if err := os.MkdirAll(filepath.Dir(genOutputDir), 0700); err != nil {
+			log.Fatalln("Mkdir error:", err)
+		}
+		output.archive, err = util.NewArchiveWriter(genOutputDir)
+		if err != nil {
+			log.Fatalln("Create archive error:", err)
+		}
+	}
+	if options.DocsVersion != "" {
+		wdModule := ds.analyzer.WorkingDirectoryModule()
+		if wdModule == nil {
+			log.Fatalln("-docs-version: the current directory is not in a module")
+		}
+		docsVersion = resolveDocsVersion(options.DocsVersion, wdModule)
+		moduleDocsDir = filepath.Dir(versionedDocsDir(genOutputDir, wdModule))
+		genOutputDir = versionedDocsDir(genOutputDir, wdModule)
+		docsDir = wdModule.Path + "/" + docsVersion
+		sharedAssetsPathPrefix = DotDotSlashes(strings.Count(wdModule.Path, "/")+2) + "assets/"
+		if docsBaseURL != "" {
+			docsBaseURL = docsPageURL(wdModule.Path + "/" + docsVersion + "/")
+		}
+	}
 	defer os.Chdir(ds.initialWorkingDirectory)
 	//genOutputDir = filepath.Join(genOutputDir, "generated-"+time.Now().Format("20060102150405"))
 
 	// ...
 	//defer func() { log.Println("============== contentPool.numByteSlices:", contentPool.numByteSlices) }() // 10 for std
-	w := &docGenResponseWriter{}
-	r := &http.Request{URL: &url.URL{}}
-	buildPageContent := func(path string) (Content, error) {
+	buildPageContent := func(builder *docServer, w *docGenResponseWriter, r *http.Request, path string) (Content, error) {
 		w.reset()
 		r.URL.Path = path
-		ds.ServeHTTP(w, r)
+		builder.ServeHTTP(w, r)
 		if w.statusCode != http.StatusOK {
 			contentPool.collect(w.content)
 			return nil, fmt.Errorf("build %s, get non-ok status code: %d", path, w.statusCode)
@@ -530,61 +758,84 @@ func GenDocs(options PageOutputOptions, args []string, outputDir string, silentM
 
 	// ...
 
-	writeFile := func(path string, c Content) (n int, err error) {
-		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
-		if err != nil {
-			return
-		}
-		defer func() {
-			//release(c) // should not put here.
-			err = f.Close()
-		}()
-
-		for _, bs := range c {
-			x, err := f.Write(bs)
-			n += x
-			if err != nil {
-				return n, err
-			}
-		}
-
-		return
-	}
-
 	type Page struct {
 		FilePath string
 		//Content  []byte
 		Content Content
 	}
 
-	var pages = make(chan Page, 8)
+	// Pages are built by numLoaders concurrent page loaders.
+	// Each loader uses its own doc server, all of which share
+	// the analysis results.
+	numLoaders := runtime.GOMAXPROCS(0)
+	var pages = make(chan Page, 2*numLoaders)
 
+	seedHashedIdentifiers(ds.analyzer)
 	buildPageHref(createPagePathInfo(ResTypeNone, ""), createPagePathInfo(ResTypeNone, ""), nil, "") // the overview page
 
-	// page loader
-	go func() {
-		for {
-			info := nextPageToLoad()
-			if info == nil {
-				break
-			}
+	// The 404 page is not registered, for it is not linked by other pages.
+	var notFoundPage Content
+	if docsBaseURL != "" {
+		w := &docGenResponseWriter{}
+		w.reset()
+		if _, err := ds.notFoundPage(w); err != nil {
+			log.Fatalln("Build 404 page error:", err)
+		}
+		notFoundPage = w.content
+		defer contentPool.collect(notFoundPage)
+	}
 
-			content, err := buildPageContent(info.HrefPath)
-			if err != nil {
-				log.Fatalln("Read page data error:", err)
-			}
+	// page loaders
+	var loaders sync.WaitGroup
+	for i := 0; i < numLoaders; i++ {
+		builder := ds
+		if i > 0 {
+			builder = ds.newPageBuilder()
+		}
 
-			//log.Println(count, count&2048, info.FilePath)
-			pages <- Page{
-				FilePath: info.FilePath,
-				Content:  content,
+		loaders.Add(1)
+		go func() {
+			defer loaders.Done()
+
+			w := &docGenResponseWriter{}
+			r := &http.Request{URL: &url.URL{}}
+			for {
+				info := nextPageToLoad()
+				if info == nil {
+					break
+				}
+
+				content, err := buildPageContent(builder, w, r, info.HrefPath)
+				if err != nil {
+					log.Fatalln("Read page data error:", err)
+				}
+				pageLoaded()
+
+				//log.Println(count, count&2048, info.FilePath)
+				pages <- Page{
+					FilePath: info.FilePath,
+					Content:  content,
+				}
 			}
-		}
+		}()
+	}
+	go func() {
+		loaders.Wait()
 		close(pages)
 	}()
 
+	// The manifest of the last generation, used to avoid rewriting unchanged pages
+	// and to remove the pages which are not generated any more.
+	// Archive files are always rewritten entirely, so they have no manifests.
+	var oldManifest, newManifest *docsManifest
+	if !forTesting && output.archive == nil {
+		oldManifest = loadDocsManifest(genOutputDir)
+		newManifest = &docsManifest{Pages: make(map[string]string, len(oldManifest.Pages))}
+	}
+
 	// page saver
-	numPages, numBytes := 0, 0
+	numPages, numBytes, numUnchangedPages := 0, 0, 0
+	var htmlPages []string // for sitemaps
 	for pg := range pages {
 		func(pg Page) {
 			defer contentPool.collect(pg.Content)
@@ -593,18 +844,25 @@ func GenDocs(options PageOutputOptions, args []string, outputDir string, silentM
 				return
 			}
 
-			path := filepath.Join(genOutputDir, pg.FilePath)
-			path = strings.Replace(path, "/", string(filepath.Separator), -1)
-			path = strings.Replace(path, "\\", string(filepath.Separator), -1)
-
-			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
-				log.Fatalln("Mkdir error:", err)
+			// The shared assets of versioned docs are always rewritten
+			// and not recorded, to avoid being removed as stale pages.
+			isSharedAsset := strings.HasPrefix(pg.FilePath, "../")
+			if !isSharedAsset && docsBaseURL != "" && strings.HasSuffix(pg.FilePath, ".html") {
+				htmlPages = append(htmlPages, pg.FilePath)
+			}
+			if !isSharedAsset && newManifest != nil {
+				hash := pageContentHash(pg.Content)
+				newManifest.Pages[pg.FilePath] = hash
+				if oldManifest.Pages[pg.FilePath] == hash {
+					path := filepath.Join(genOutputDir, filepath.FromSlash(pg.FilePath))
+					if _, err := os.Stat(path); err == nil {
+						numUnchangedPages++
+						return
+					}
+				}
 			}
 
-			//if err := ioutil.WriteFile(path, pg.Content, 0644); err != nil {
-			//	log.Fatalln("Write file error:", err)
-			//}
-			if n, err := writeFile(path, pg.Content); err != nil {
+			if n, err := output.writeFile(path.Join(docsDir, pg.FilePath), pg.Content); err != nil {
 				log.Fatalln("Write file error:", err)
 			} else {
 				numPages++
@@ -619,23 +877,299 @@ func GenDocs(options PageOutputOptions, args []string, outputDir string, silentM
 	}
 
 	if forTesting {
-		return
+		return ds
+	}
+
+	numRemovedPages := 0
+	if newManifest != nil {
+		numRemovedPages = removeStalePages(genOutputDir, oldManifest, newManifest)
+		if err := newManifest.save(genOutputDir); err != nil {
+			log.Fatalln("Write manifest error:", err)
+		}
+	}
+	var docsVersions []string
+	if moduleDocsDir != "" {
+		docsVersions, err = updateDocsVersions(moduleDocsDir, docsVersion)
+		if err != nil {
+			log.Fatalln("Update docs versions error:", err)
+		}
+	}
+	if docsBaseURL != "" {
+		if err := writeStaticHostingFiles(output, docsDir, htmlPages, notFoundPage, docsVersions); err != nil {
+			log.Fatalln("Write static hosting files error:", err)
+		}
+	}
+	if output.archive != nil {
+		if err := output.archive.Close(); err != nil {
+			log.Fatalln("Write archive error:", err)
+		}
+	}
+
+	if options.CheckLinks {
+		checkGeneratedLinks(output, docsDir)
 	}
 
 	//if verboseLogs || !silent {
 	if !silent {
 		log.Printf("Done (%d pages are generated and %d bytes are written).", numPages, numBytes)
+		log.Printf("%d pages are unchanged and %d stale pages are removed.", numUnchangedPages, numRemovedPages)
 	}
 
-	log.Printf("Docs are generated in %s.", outputDir) // genOutputDir)
-	if sourceReadingStyle == SourceReadingStyle_external {
-		for _, w := range ds.localRepositoryWarnings {
-			log.Println("!!! Warning:", w)
+	return ds
+}
+
+// docsOutput is where the generated files are written into,
+// either a directory or an archive file (a .zip or .tar.gz file).
+type docsOutput struct {
+	dir     string
+	archive *util.ArchiveWriter
+}
+
+// writeFile writes a file. filePath is slash-separated and
+// relative to the output root. The content chunks are
+// streamed into the file directly.
+func (o *docsOutput) writeFile(filePath string, c Content) (n int, err error) {
+	if o.archive != nil {
+		return o.archive.WriteFile(filePath, c...)
+	}
+
+	path := filepath.Join(o.dir, filepath.FromSlash(filePath))
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if e := f.Close(); err == nil {
+			err = e
 		}
-		if len(ds.localRepositoryWarnings) > 0 {
-			log.Println()
+	}()
+
+	for _, bs := range c {
+		x, err := f.Write(bs)
+		n += x
+		if err != nil {
+			return n, err
 		}
 	}
-	log.Println("Run the following command to view the docs:")
-	log.Printf("\t%s", viewDocsCommand(outputDir)) // genOutputDir))
+
+	return
+}
+
+// checkGeneratedLinks checks the links in the generated pages
+// and exits with a non-zero code if there are broken links.
+func checkGeneratedLinks(output *docsOutput, docsDir string) {
+	var fsys fs.FS
+	if output.archive != nil {
+		archiveFS, err := util.OpenArchiveFS(output.dir)
+		if err != nil {
+			log.Fatalln("Open archive error:", err)
+		}
+		fsys = archiveFS
+	} else {
+		fsys = os.DirFS(output.dir)
+	}
+	if docsDir == "" {
+		docsDir = "."
+	}
+
+	broken, err := checkDocsLinks(fsys, docsDir)
+	if err != nil {
+		log.Fatalln("Check links error:", err)
+	}
+	if len(broken) > 0 {
+		reportBrokenLinks(broken, docsDir)
+		log.Fatalf("%d broken links are found.", len(broken))
+	}
+	log.Println("No broken links are found.")
+}
+
+// versionedDocsDir returns the directory of the docs
+// of the current version of a module.
+func versionedDocsDir(outputDir string, module *code.Module) string {
+	return filepath.Join(outputDir, filepath.FromSlash(module.Path), docsVersion)
+}
+
+// resolveDocsVersion returns the version used in versioned docs
+// generation. For "auto", the version of the module is used. If it is
+// unknown, the "git describe" command is used to find the version.
+func resolveDocsVersion(option string, module *code.Module) string {
+	version := option
+	if version == "auto" {
+		version = module.Version
+		if version == "" {
+			output, err := util.RunShellCommand(time.Second*5, module.Dir, nil, "git", "describe", "--tags", "--always")
+			if err != nil {
+				log.Fatalln("-docs-version: find module version error:", err)
+			}
+			version = string(bytes.TrimSpace(output))
+		}
+	}
+	if version == "" || version == "." || version == ".." || strings.ContainsAny(version, `/\`) {
+		log.Fatalln("-docs-version: invalid version:", version)
+	}
+	return version
+}
+
+// writeVersionSwitcher writes the version switcher of versioned docs.
+// The version list is loaded from the versions.json file in the module
+// docs directory by JavaScript, so that the pages of old versions also
+// list the versions generated later.
+func writeVersionSwitcher(page *htmlPage, currentPageInfo pagePathInfo) {
+	currentHref := generatedPageFilePath(currentPageInfo)
+	moduleDocsRoot := DotDotSlashes(strings.Count(currentHref, "/") + 1)
+	fmt.Fprintf(page, `<span id="version-switcher"><select data-root="%s" data-page="%s" data-version="%s"><option>%s</option></select></span>
+`,
+		moduleDocsRoot, currentHref, docsVersion, docsVersion,
+	)
+}
+
+const docsVersionsFilename = "versions.json"
+
+// updateDocsVersions adds version to the versions.json file in the
+// module docs directory and removes the versions which docs don't
+// exist any more. It also writes an index.html file in the directory,
+// which redirects to the docs of the latest version. The updated
+// versions are returned.
+func updateDocsVersions(moduleDocsDir, version string) ([]string, error) {
+	var versions []string
+	versionsFile := filepath.Join(moduleDocsDir, docsVersionsFilename)
+	if data, err := ioutil.ReadFile(versionsFile); err == nil {
+		if err := json.Unmarshal(data, &versions); err != nil {
+			log.Println("Invalid docs versions file is ignored:", err)
+		}
+	}
+
+	versions = append(versions, version)
+	versions = sortDocsVersions(versions)
+	existingVersions := versions[:0]
+	for _, v := range versions {
+		if _, err := os.Stat(filepath.Join(moduleDocsDir, v, "index.html")); err == nil {
+			existingVersions = append(existingVersions, v)
+		}
+	}
+	versions = existingVersions
+
+	data, err := json.MarshalIndent(versions, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(versionsFile, data, 0644); err != nil {
+		return nil, err
+	}
+
+	latest := url.PathEscape(versions[0]) + "/index.html"
+	index := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="0; url=%[1]s">
+</head>
+<body><a href="%[1]s">%[1]s</a></body>
+</html>
+`, latest)
+	if err := ioutil.WriteFile(filepath.Join(moduleDocsDir, "index.html"), []byte(index), 0644); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// sortDocsVersions sorts versions from new to old and removes duplicates.
+// Semantic versions are listed before others, which are sorted in
+// reverse alphabetical order.
+func sortDocsVersions(versions []string) []string {
+	sort.Slice(versions, func(i, j int) bool {
+		vi, vj := versions[i], versions[j]
+		si, sj := semver.IsValid(vi), semver.IsValid(vj)
+		if si != sj {
+			return si
+		}
+		if si {
+			if c := semver.Compare(vi, vj); c != 0 {
+				return c > 0
+			}
+		}
+		return vi > vj
+	})
+
+	unique := versions[:0]
+	for i, v := range versions {
+		if i == 0 || v != versions[i-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// docsManifest records the content hashes of the generated pages.
+// It is used to make docs generation incremental: unchanged pages
+// are not rewritten, and the pages which are not generated any more
+// are removed. Note that all the pages are still built, for a page
+// might contain information from packages other than its own one.
+type docsManifest struct {
+	Pages map[string]string `json:"pages"` // file path -> content hash
+}
+
+const docsManifestFilename = ".golds-manifest.json"
+
+// loadDocsManifest returns a blank manifest if there is no manifest in dir
+// or the manifest is invalid.
+func loadDocsManifest(dir string) *docsManifest {
+	var m docsManifest
+	if data, err := ioutil.ReadFile(filepath.Join(dir, docsManifestFilename)); err == nil {
+		if err := json.Unmarshal(data, &m); err != nil {
+			log.Println("Invalid docs manifest is ignored:", err)
+		}
+	}
+	if m.Pages == nil {
+		m.Pages = make(map[string]string)
+	}
+	return &m
+}
+
+func (m *docsManifest) save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, docsManifestFilename), data, 0644)
+}
+
+func pageContentHash(c Content) string {
+	h := sha256.New()
+	for _, bs := range c {
+		h.Write(bs)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// removeStalePages removes the pages which are recorded in the old manifest
+// but not in the new one, and the directories which become empty.
+// It returns the number of removed pages.
+func removeStalePages(dir string, oldManifest, newManifest *docsManifest) int {
+	numRemoved := 0
+	for page := range oldManifest.Pages {
+		if _, ok := newManifest.Pages[page]; ok {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(page))
+		if err := os.Remove(path); err != nil {
+			if !os.IsNotExist(err) {
+				log.Println("Remove stale page error:", err)
+			}
+			continue
+		}
+		numRemoved++
+
+		// Remove the empty parent directories. os.Remove fails
+		// for non-empty directories, which is expected.
+		for d := filepath.Dir(path); d != filepath.Clean(dir); d = filepath.Dir(d) {
+			if os.Remove(d) != nil {
+				break
+			}
+		}
+	}
+	return numRemoved
 }
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"go101.org/golds/code"
+	"go101.org/golds/internal/util"
+)
+
+// GenDocset generates the HTML docs pages into a Dash docset bundle,
+// <outputDir>/<docsetName>.docset, which could also be used in Zeal.
+// Besides the pages, the bundle contains an Info.plist file and a
+// docSet.dsidx search index, which maps the packages and package-level
+// resources (and the fields and methods of types) to their page anchors.
+// Table-of-contents anchors are inserted into package details pages.
+func GenDocset(options PageOutputOptions, args []string, outputDir, docsetName string, silentMode bool, printUsage func(io.Writer), increaseGCFrequency bool) {
+	forTesting := outputDir == ""
+
+	bundleDir := filepath.Join(outputDir, docsetName+".docset")
+	resourcesDir := filepath.Join(bundleDir, "Contents", "Resources")
+	docsDir := ""
+	if !forTesting {
+		docsDir = filepath.Join(resourcesDir, "Documents")
+	}
+
+	writeDashAnchors = true
+	options.DocsVersion = "" // docsets are not versioned
+	options.BaseURL = ""     // and not hosted
+	ds := genDocPages(options, args, docsDir, silentMode, printUsage, increaseGCFrequency)
+
+	rows := collectDocsetIndexRows(ds.analyzer)
+	if forTesting {
+		return
+	}
+
+	var buf bytes.Buffer
+	writeDocsetInfoPlist(&buf, docsetName)
+	if err := ioutil.WriteFile(filepath.Join(bundleDir, "Contents", "Info.plist"), buf.Bytes(), 0644); err != nil {
+		log.Fatalln("Write file error:", err)
+	}
+
+	buf.Reset()
+	const createTableSQL = "CREATE TABLE searchIndex(id INTEGER PRIMARY KEY, name TEXT, type TEXT, path TEXT)"
+	if err := util.WriteSQLiteTable(&buf, "searchIndex", createTableSQL, rows); err != nil {
+		log.Fatalln("Build search index error:", err)
+	}
+	if err := os.MkdirAll(resourcesDir, 0700); err != nil {
+		log.Fatalln("Mkdir error:", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(resourcesDir, "docSet.dsidx"), buf.Bytes(), 0644); err != nil {
+		log.Fatalln("Write file error:", err)
+	}
+
+	log.Printf("Docset (%d index entries) is generated in %s.", len(rows), bundleDir)
+}
+
+// dashEntryType returns the Dash entry type of a package-level resource.
+func dashEntryType(res code.Resource) string {
+	switch res := res.(type) {
+	case *code.TypeName:
+		switch res.Denoting.TT.Underlying().(type) {
+		case *types.Interface:
+			return "Interface"
+		case *types.Struct:
+			return "Struct"
+		}
+		return "Type"
+	case *code.Function:
+		return "Function"
+	case *code.Variable:
+		return "Variable"
+	case *code.Constant:
+		return "Constant"
+	}
+	panic("should not")
+}
+
+// writeDashAnchor writes a Dash table-of-contents anchor.
+func writeDashAnchor(page *htmlPage, res code.Resource) {
+	fmt.Fprintf(page, `<a name="//apple_ref/cpp/%s/%s" class="dashAnchor"></a>`, dashEntryType(res), url.PathEscape(res.Name()))
+}
+
+// writeDashSelectorAnchor writes the anchor of a field or method of a type,
+// which is linked by the index entry of the selector.
+func writeDashSelectorAnchor(page *htmlPage, tn *code.TypeName, sel *code.Selector) {
+	entryType := "Method"
+	if sel.Field != nil {
+		entryType = "Field"
+	}
+	name := tn.Name() + "." + sel.Name()
+	fmt.Fprintf(page, `<a id="name-%s" name="//apple_ref/cpp/%s/%s" class="dashAnchor"></a>`, name, entryType, url.PathEscape(name))
+}
+
+// collectDocsetIndexRows collects the rows of the searchIndex table.
+// Each row is in the form of [id, name, type, path].
+// Must be called after the pages are generated.
+func collectDocsetIndexRows(analyzer *code.CodeAnalyzer) [][]interface{} {
+	var rows [][]interface{}
+	add := func(name, entryType, path string) {
+		rows = append(rows, []interface{}{nil, name, entryType, path})
+	}
+	listed := func(name string) bool {
+		return name != "_" && (collectUnexporteds || token.IsExported(name))
+	}
+
+	for i, n := 0, analyzer.NumPackages(); i < n; i++ {
+		pkg := analyzer.PackageAt(i)
+		if !isGeneratedPackage(pkg.Path) {
+			continue // only stub pages are generated for it
+		}
+		pkgPage := generatedPageFilePath(createPagePathInfo1(ResTypePackage, pkg.Path))
+		add(pkg.Path, "Package", pkgPage)
+
+		resPath := func(res code.Resource) string {
+			return pkgPage + "#name-" + res.Name()
+		}
+
+		for _, tn := range pkg.AllTypeNames {
+			if !listed(tn.Name()) {
+				continue
+			}
+			add(tn.Name(), dashEntryType(tn), resPath(tn))
+			for _, sel := range tn.Denoting.DirectSelectors {
+				if !listed(sel.Name()) {
+					continue
+				}
+				name := tn.Name() + "." + sel.Name()
+				if sel.Field != nil {
+					add(name, "Field", pkgPage+"#name-"+name)
+				} else {
+					add(name, "Method", pkgPage+"#name-"+name)
+				}
+			}
+		}
+		for _, f := range pkg.AllFunctions {
+			if !f.IsMethod() && listed(f.Name()) {
+				add(f.Name(), "Function", resPath(f))
+			}
+		}
+		for _, v := range pkg.AllVariables {
+			if listed(v.Name()) {
+				add(v.Name(), "Variable", resPath(v))
+			}
+		}
+		for _, c := range pkg.AllConstants {
+			if listed(c.Name()) {
+				add(c.Name(), "Constant", resPath(c))
+			}
+		}
+	}
+	return rows
+}
+
+// docsetIdentifier converts a docset name to a bundle identifier
+// by lowering the letters and removing the non-alphanumeric characters.
+func docsetIdentifier(docsetName string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(docsetName) {
+		if r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "golds"
+	}
+	return b.String()
+}
+
+func writeDocsetInfoPlist(w io.Writer, docsetName string) {
+	var name strings.Builder
+	util.NewHTMLEscapeWriter(&name).WriteString(docsetName)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleIdentifier</key>
+	<string>%[1]s</string>
+	<key>CFBundleName</key>
+	<string>%[2]s</string>
+	<key>DocSetPlatformFamily</key>
+	<string>%[1]s</string>
+	<key>isDashDocset</key>
+	<true/>
+	<key>isJavaScriptEnabled</key>
+	<true/>
+	<key>dashIndexFilePath</key>
+	<string>index.html</string>
+	<key>DashDocSetFamily</key>
+	<string>dashtoc</string>
+</dict>
+</plist>
+`, docsetIdentifier(docsetName), name.String())
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GenJSON generates the JSON documents of the analysis data.
+// The layout of the generated files mirrors the data API paths:
+//
+// * modules.json, overview.json and statistics.json
+// * pkg/<pkg-path>.json: package details
+// * dep/<pkg-path>.json: package dependencies
+func GenJSON(options PageOutputOptions, args []string, outputDir string, silentMode bool, printUsage func(io.Writer)) {
+	toolchain, err := findToolchainInfo()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	forTesting := outputDir == ""
+	silent := silentMode || forTesting
+
+	ds := &docServer{}
+	ds.analyze(args, options, toolchain, forTesting, printUsage)
+
+	genOutputDir := outputDir
+	if genOutputDir == "." {
+		genOutputDir = ds.initialWorkingDirectory
+	}
+
+	numFiles, numBytes := 0, 0
+	writeJSON := func(path string, v interface{}) {
+		data, err := json.MarshalIndent(v, "", "\t")
+		if err != nil {
+			log.Fatalln("Marshal error:", err)
+		}
+
+		if forTesting {
+			return
+		}
+
+		filePath := filepath.Join(genOutputDir, strings.Replace(path, "/", string(filepath.Separator), -1))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+			log.Fatalln("Mkdir error:", err)
+		}
+		if err := ioutil.WriteFile(filePath, data, 0644); err != nil {
+			log.Fatalln("Write file error:", err)
+		}
+		numFiles++
+		numBytes += len(data)
+
+		if !silent {
+			log.Printf("Generated %s (size: %d).", path, len(data))
+		}
+	}
+
+	writeJSON("modules.json", buildAPIData_Modules(ds.analyzer))
+	writeJSON("overview.json", buildAPIData_Overview(ds.buildOverviewData()))
+	writeJSON("statistics.json", buildAPIData_Statistics(ds.analyzer.Statistics()))
+
+	for i, n := 0, ds.analyzer.NumPackages(); i < n; i++ {
+		pkg := ds.analyzer.PackageAt(i)
+
+		details := buildPackageDetailsData(ds.analyzer, pkg.Path, collectUnexporteds)
+		writeJSON(string(ResTypePackage)+"/"+pkg.Path+".json", buildAPIData_Package(details))
+
+		deps := ds.buildPackageDependenciesData(pkg.Path)
+		writeJSON(string(ResTypeDependency)+"/"+pkg.Path+".json", buildAPIData_Dependencies(deps))
+	}
+
+	if forTesting {
+		return
+	}
+
+	if !silent {
+		log.Printf("Done (%d files are generated and %d bytes are written).", numFiles, numBytes)
+	}
+
+	log.Printf("JSON data are generated in %s.", outputDir)
+}
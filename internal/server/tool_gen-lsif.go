@@ -0,0 +1,412 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"go101.org/golds/code"
+)
+
+// The LSIF format: https://microsoft.github.io/language-server-protocol/specifications/lsif/0.5.0/specification/
+const lsifVersion = "0.5.0"
+
+// The moniker scheme used in the generated LSIF dumps.
+// The moniker identifiers use the same form as the reference IDs
+// used in Golds reference pages: pkg..Identifier or pkg..Type.selector.
+const lsifMonikerScheme = "golds"
+
+// GenLSIF generates a LSIF dump (the dump.lsif file in outputDir),
+// which contains the definitions, references and implementations
+// of the package-level resources and type selectors in the analyzed packages.
+func GenLSIF(options PageOutputOptions, args []string, outputDir string, silentMode bool, printUsage func(io.Writer)) {
+	toolchain, err := findToolchainInfo()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	forTesting := outputDir == ""
+
+	ds := &docServer{}
+	ds.analyze(args, options, toolchain, forTesting, printUsage)
+
+	var w io.Writer = ioutil.Discard
+	dumpFilePath := filepath.Join(outputDir, "dump.lsif")
+	if !forTesting {
+		if err := os.MkdirAll(outputDir, 0700); err != nil {
+			log.Fatalln("Mkdir error:", err)
+		}
+		f, err := os.Create(dumpFilePath)
+		if err != nil {
+			log.Fatalln("Create file error:", err)
+		}
+		defer f.Close()
+		bw := bufio.NewWriter(f)
+		defer func() {
+			if err := bw.Flush(); err != nil {
+				log.Fatalln("Write file error:", err)
+			}
+		}()
+		w = bw
+	}
+
+	lg := &lsifGenerator{
+		analyzer:  ds.analyzer,
+		encoder:   json.NewEncoder(w),
+		documents: make(map[string]*lsifDocument, 1024),
+		symbols:   make(map[types.Object]*lsifSymbol, 1024),
+	}
+	lg.generate(ds.initialWorkingDirectory, options.GoldsVersion)
+
+	if forTesting {
+		return
+	}
+
+	if !silentMode {
+		log.Printf("Done (%d symbols in %d documents).", len(lg.symbols), len(lg.documents))
+	}
+	log.Printf("LSIF dump is generated at %s.", dumpFilePath)
+}
+
+type lsifDocument struct {
+	id      int
+	ranges  []int
+	content []byte // nil if the file fails to be read
+}
+
+type lsifSymbol struct {
+	moniker  string
+	pkg      *code.Package
+	typeName *code.TypeName // for type names only
+	ident    *ast.Ident
+	resultID int // the result set ID
+	rangeID  int // the definition range ID
+	docID    int // the document containing the definition
+}
+
+type lsifGenerator struct {
+	analyzer *code.CodeAnalyzer
+	encoder  *json.Encoder
+
+	lastID     int
+	documents  map[string]*lsifDocument // by filenames
+	docList    []*lsifDocument
+	symbols    map[types.Object]*lsifSymbol
+	symbolList []types.Object // for the stable output order
+}
+
+type lsifPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+func (lg *lsifGenerator) emit(elemType, label string, props map[string]interface{}) int {
+	lg.lastID++
+	if props == nil {
+		props = make(map[string]interface{}, 2)
+	}
+	props["id"] = lg.lastID
+	props["type"] = elemType
+	props["label"] = label
+	if err := lg.encoder.Encode(props); err != nil {
+		log.Fatalln("Write LSIF element error:", err)
+	}
+	return lg.lastID
+}
+
+func (lg *lsifGenerator) vertex(label string, props map[string]interface{}) int {
+	return lg.emit("vertex", label, props)
+}
+
+func (lg *lsifGenerator) edge(label string, outV int, inVs []int, props map[string]interface{}) int {
+	if props == nil {
+		props = make(map[string]interface{}, 4)
+	}
+	props["outV"] = outV
+	if len(inVs) == 1 && label != "contains" && label != "item" {
+		props["inV"] = inVs[0]
+	} else {
+		props["inVs"] = inVs
+	}
+	return lg.emit("edge", label, props)
+}
+
+func lsifFileURI(filename string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(filename)}).String()
+}
+
+// identRange emits a range vertex for an identifier and returns the range ID
+// and the document ID. The characters are counted in UTF-16 code units,
+// as declared in the metaData vertex.
+func (lg *lsifGenerator) identRange(fset *token.FileSet, ident *ast.Ident) (rangeID, docID int) {
+	pos := fset.PositionFor(ident.NamePos, false)
+	doc := lg.documents[pos.Filename]
+	if doc == nil {
+		doc = &lsifDocument{
+			id: lg.vertex("document", map[string]interface{}{
+				"uri":        lsifFileURI(pos.Filename),
+				"languageId": "go",
+			}),
+		}
+		content, err := ioutil.ReadFile(pos.Filename)
+		if err != nil {
+			log.Printf("ReadFile (%s) error: %s", pos.Filename, err)
+		} else {
+			doc.content = content
+		}
+		lg.documents[pos.Filename] = doc
+		lg.docList = append(lg.docList, doc)
+	}
+	column, length := pos.Column-1, len(ident.Name)
+	if lineStart := pos.Offset - column; lineStart >= 0 && pos.Offset <= len(doc.content) {
+		column = utf16Len(doc.content[lineStart:pos.Offset])
+		length = utf16Len([]byte(ident.Name))
+	}
+	start := lsifPosition{Line: pos.Line - 1, Character: column}
+	end := lsifPosition{Line: start.Line, Character: start.Character + length}
+	rangeID = lg.vertex("range", map[string]interface{}{"start": start, "end": end})
+	doc.ranges = append(doc.ranges, rangeID)
+	return rangeID, doc.id
+}
+
+// utf16Len returns the number of UTF-16 code units of the UTF-8 text.
+func utf16Len(text []byte) (n int) {
+	for len(text) > 0 {
+		r, size := utf8.DecodeRune(text)
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+		text = text[size:]
+	}
+	return n
+}
+
+func (lg *lsifGenerator) register(obj types.Object, pkg *code.Package, ident *ast.Ident, moniker string) {
+	if obj == nil || ident == nil || lg.symbols[obj] != nil {
+		return
+	}
+	lg.symbols[obj] = &lsifSymbol{moniker: moniker, pkg: pkg, ident: ident}
+	lg.symbolList = append(lg.symbolList, obj)
+}
+
+func (lg *lsifGenerator) collectSymbols() {
+	for i, n := 0, lg.analyzer.NumPackages(); i < n; i++ {
+		pkg := lg.analyzer.PackageAt(i)
+		if pkg.Path == "builtin" {
+			continue
+		}
+		prefix := pkg.Path + ".."
+
+		for _, tn := range pkg.AllTypeNames {
+			if tn.AstSpec == nil {
+				continue
+			}
+			lg.register(tn.TypeName, pkg, tn.AstSpec.Name, prefix+tn.Name())
+			if tn.IsAlias() {
+				continue
+			}
+			if sym := lg.symbols[tn.TypeName]; sym != nil {
+				sym.typeName = tn
+			}
+			for _, sel := range tn.Denoting.DirectSelectors {
+				var ident *ast.Ident
+				if sel.Field != nil {
+					ident = findIdentByName(sel.Field.AstField.Names, sel.Name())
+				} else if sel.Method.AstFunc != nil {
+					ident = sel.Method.AstFunc.Name
+				} else if len(sel.Method.AstField.Names) > 0 {
+					ident = sel.Method.AstField.Names[0]
+				}
+				if ident != nil {
+					lg.register(sel.Object(), pkg, ident, prefix+tn.Name()+"."+sel.Name())
+				}
+			}
+		}
+		for _, f := range pkg.AllFunctions {
+			if f.Func == nil || f.AstDecl == nil {
+				continue
+			}
+			if f.IsMethod() {
+				if _, tn, _ := f.ReceiverTypeName(); tn != nil {
+					lg.register(f.Func, pkg, f.AstDecl.Name, prefix+tn.Name()+"."+f.Name())
+				}
+				continue
+			}
+			lg.register(f.Func, pkg, f.AstDecl.Name, prefix+f.Name())
+		}
+		for _, v := range pkg.AllVariables {
+			if v.AstSpec == nil {
+				continue
+			}
+			lg.register(v.Var, pkg, findIdentByName(v.AstSpec.Names, v.Name()), prefix+v.Name())
+		}
+		for _, c := range pkg.AllConstants {
+			if c.AstSpec == nil {
+				continue
+			}
+			lg.register(c.Const, pkg, findIdentByName(c.AstSpec.Names, c.Name()), prefix+c.Name())
+		}
+	}
+}
+
+func (lg *lsifGenerator) generate(projectRoot, goldsVersion string) {
+	lg.vertex("metaData", map[string]interface{}{
+		"version":          lsifVersion,
+		"projectRoot":      lsifFileURI(projectRoot),
+		"positionEncoding": "utf-16",
+		"toolInfo": map[string]interface{}{
+			"name":    "golds",
+			"version": goldsVersion,
+		},
+	})
+	projectID := lg.vertex("project", map[string]interface{}{"kind": "go"})
+
+	lg.collectSymbols()
+
+	// Definitions, monikers, hovers and references.
+	for _, obj := range lg.symbolList {
+		sym := lg.symbols[obj]
+		fset := sym.pkg.PPkg.Fset
+
+		sym.resultID = lg.vertex("resultSet", nil)
+		sym.rangeID, sym.docID = lg.identRange(fset, sym.ident)
+		lg.edge("next", sym.rangeID, []int{sym.resultID}, nil)
+
+		kind := "local"
+		if obj.Exported() {
+			kind = "export"
+		}
+		monikerID := lg.vertex("moniker", map[string]interface{}{
+			"scheme":     lsifMonikerScheme,
+			"identifier": sym.moniker,
+			"kind":       kind,
+		})
+		lg.edge("moniker", sym.resultID, []int{monikerID}, nil)
+
+		hoverID := lg.vertex("hoverResult", map[string]interface{}{
+			"result": map[string]interface{}{
+				"contents": []map[string]string{{
+					"language": "go",
+					"value":    types.ObjectString(obj, types.RelativeTo(sym.pkg.PPkg.Types)),
+				}},
+			},
+		})
+		lg.edge("textDocument/hover", sym.resultID, []int{hoverID}, nil)
+
+		defResultID := lg.vertex("definitionResult", nil)
+		lg.edge("textDocument/definition", sym.resultID, []int{defResultID}, nil)
+		lg.edge("item", defResultID, []int{sym.rangeID}, map[string]interface{}{"document": sym.docID})
+
+		refResultID := lg.vertex("referenceResult", nil)
+		lg.edge("textDocument/references", sym.resultID, []int{refResultID}, nil)
+		lg.edge("item", refResultID, []int{sym.rangeID}, map[string]interface{}{"document": sym.docID, "property": "definitions"})
+
+		var refsByDoc = make(map[int][]int)
+		var docs []int
+		for _, id := range lg.analyzer.ObjectReferences(obj) {
+			if id.AstIdent == sym.ident {
+				continue
+			}
+			rangeID, docID := lg.identRange(id.FileInfo.Pkg.PPkg.Fset, id.AstIdent)
+			lg.edge("next", rangeID, []int{sym.resultID}, nil)
+			if refsByDoc[docID] == nil {
+				docs = append(docs, docID)
+			}
+			refsByDoc[docID] = append(refsByDoc[docID], rangeID)
+		}
+		for _, docID := range docs {
+			lg.edge("item", refResultID, refsByDoc[docID], map[string]interface{}{"document": docID, "property": "references"})
+		}
+	}
+
+	// Implementations of interface types and interface methods.
+	for _, obj := range lg.symbolList {
+		tn := lg.symbols[obj].typeName
+		if tn == nil {
+			continue
+		}
+		itype, ok := tn.Denoting.TT.Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		implers := lg.implementersOf(tn.Denoting)
+		lg.emitImplementations(obj, implers, lsifNamedTypeObject)
+		for i := 0; i < itype.NumExplicitMethods(); i++ {
+			m := itype.ExplicitMethod(i)
+			lg.emitImplementations(m, implers, func(t types.Type) types.Object {
+				impl, _, _ := types.LookupFieldOrMethod(t, true, m.Pkg(), m.Name())
+				return impl
+			})
+		}
+	}
+
+	// Containment.
+	docIDs := make([]int, 0, len(lg.docList))
+	for _, doc := range lg.docList {
+		lg.edge("contains", doc.id, doc.ranges, nil)
+		docIDs = append(docIDs, doc.id)
+	}
+	if len(docIDs) > 0 {
+		lg.edge("contains", projectID, docIDs, nil)
+	}
+}
+
+func (lg *lsifGenerator) implementersOf(t *code.TypeInfo) []types.Type {
+	implers := make([]types.Type, 0, len(t.ImplementedBys))
+	for _, impler := range t.ImplementedBys {
+		if _, isInterface := impler.TT.Underlying().(*types.Interface); isInterface {
+			continue
+		}
+		implers = append(implers, impler.TT)
+	}
+	return implers
+}
+
+func (lg *lsifGenerator) emitImplementations(obj types.Object, implers []types.Type, implObject func(types.Type) types.Object) {
+	sym := lg.symbols[obj]
+	if sym == nil {
+		return
+	}
+	var rangesByDoc = make(map[int][]int)
+	var docs []int
+	for _, t := range implers {
+		impl := lg.symbols[implObject(t)]
+		if impl == nil || impl == sym {
+			continue
+		}
+		if rangesByDoc[impl.docID] == nil {
+			docs = append(docs, impl.docID)
+		}
+		rangesByDoc[impl.docID] = append(rangesByDoc[impl.docID], impl.rangeID)
+	}
+	if len(docs) == 0 {
+		return
+	}
+	implResultID := lg.vertex("implementationResult", nil)
+	lg.edge("textDocument/implementation", sym.resultID, []int{implResultID}, nil)
+	for _, docID := range docs {
+		lg.edge("item", implResultID, rangesByDoc[docID], map[string]interface{}{"document": docID})
+	}
+}
+
+// lsifNamedTypeObject returns the type name object of T or *T.
+func lsifNamedTypeObject(t types.Type) types.Object {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return typesNamedOrigin(named).Obj()
+	}
+	return nil
+}
@@ -0,0 +1,419 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"go/doc/comment"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"go101.org/golds/code"
+)
+
+// GenMarkdown generates the docs of the analyzed packages as Markdown files.
+// The layout of the generated files:
+//
+// * index.md: the module index, which lists the packages of each module.
+// * pkg/<pkg-path>.md: the docs of a package.
+//
+// The links between the generated files are relative, so that the files
+// could be committed into and viewed in a code repository.
+func GenMarkdown(options PageOutputOptions, args []string, outputDir string, silentMode bool, printUsage func(io.Writer)) {
+	toolchain, err := findToolchainInfo()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	forTesting := outputDir == ""
+	silent := silentMode || forTesting
+
+	ds := &docServer{}
+	ds.analyze(args, options, toolchain, forTesting, printUsage)
+
+	genOutputDir := outputDir
+	if genOutputDir == "." {
+		genOutputDir = ds.initialWorkingDirectory
+	}
+
+	numFiles, numBytes := 0, 0
+	writeMarkdown := func(path string, data []byte) {
+		if forTesting {
+			return
+		}
+
+		filePath := filepath.Join(genOutputDir, strings.Replace(path, "/", string(filepath.Separator), -1))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+			log.Fatalln("Mkdir error:", err)
+		}
+		if err := ioutil.WriteFile(filePath, data, 0644); err != nil {
+			log.Fatalln("Write file error:", err)
+		}
+		numFiles++
+		numBytes += len(data)
+
+		if !silent {
+			log.Printf("Generated %s (size: %d).", path, len(data))
+		}
+	}
+
+	var buf bytes.Buffer
+	writeMarkdownIndex(&buf, ds.buildOverviewData())
+	writeMarkdown("index.md", buf.Bytes())
+
+	for i, n := 0, ds.analyzer.NumPackages(); i < n; i++ {
+		pkg := ds.analyzer.PackageAt(i)
+		details := buildPackageDetailsData(ds.analyzer, pkg.Path, collectUnexporteds)
+
+		buf.Reset()
+		mp := &markdownPrinter{docPrinter{ds: ds, pkg: pkg, details: details, out: &buf}}
+		mp.printPackage()
+		writeMarkdown(markdownPackageFile(pkg), buf.Bytes())
+	}
+
+	if forTesting {
+		return
+	}
+
+	if !silent {
+		log.Printf("Done (%d files are generated and %d bytes are written).", numFiles, numBytes)
+	}
+
+	log.Printf("Markdown docs are generated in %s.", outputDir)
+}
+
+// writeMarkdownIndex writes the module index, in which
+// the packages are grouped by modules.
+func writeMarkdownIndex(w io.Writer, overview *Overview) {
+	byModule := make(map[string][]*PackageForListing)
+	var modules []string
+	for _, p := range overview.Packages {
+		if byModule[p.Module] == nil {
+			modules = append(modules, p.Module)
+		}
+		byModule[p.Module] = append(byModule[p.Module], p)
+	}
+	sort.Strings(modules)
+
+	io.WriteString(w, "# Packages\n")
+	for _, m := range modules {
+		pkgs := byModule[m]
+		switch mod := pkgs[0].Package.Module(); {
+		case m == "":
+			io.WriteString(w, "\n## Packages not in modules\n\n")
+		case mod != nil && mod.Version != "":
+			fmt.Fprintf(w, "\n## %s@%s\n\n", m, mod.Version)
+		default:
+			fmt.Fprintf(w, "\n## %s\n\n", m)
+		}
+		for _, p := range pkgs {
+			fmt.Fprintf(w, "- [%s](%s/%s.md)", p.Path, ResTypePackage, p.Path)
+			if p.OneLineDoc != "" {
+				fmt.Fprintf(w, " - %s", p.OneLineDoc)
+			}
+			io.WriteString(w, "\n")
+		}
+	}
+}
+
+// markdownHeadingAnchor returns the anchor name generated by
+// the popular Markdown renderers (GitHub, GitLab, etc.) for a heading.
+func markdownHeadingAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			b.WriteByte('-')
+		case r == '-', r == '_', unicode.IsLetter(r), unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// markdownCodeFence returns a code fence which is longer
+// than any backtick sequence in the code.
+func markdownCodeFence(code string) string {
+	n, max := 0, 2
+	for i := 0; i < len(code); i++ {
+		if code[i] == '`' {
+			n++
+			if n > max {
+				max = n
+			}
+		} else {
+			n = 0
+		}
+	}
+	return strings.Repeat("`", max+1)
+}
+
+func markdownResourceHeading(res code.Resource) string {
+	switch res.(type) {
+	case *code.TypeName:
+		return "type " + res.Name()
+	case *code.Function:
+		return "func " + res.Name()
+	case *code.Variable:
+		return "var " + res.Name()
+	case *code.Constant:
+		return "const " + res.Name()
+	}
+	return res.Name()
+}
+
+// markdownPrinter prints the docs of a package in Markdown.
+type markdownPrinter struct {
+	docPrinter
+}
+
+// packageFile returns the relative path of the Markdown file of the
+// specified package, or a blank string for the current package.
+func (mp *markdownPrinter) packageFile(p *code.Package) string {
+	if p == mp.pkg {
+		return ""
+	}
+	return RelativePath(markdownPackageFile(mp.pkg), markdownPackageFile(p))
+}
+
+// markdownPackageFile returns the path of the Markdown file of a package,
+// which is relative to the output directory.
+func markdownPackageFile(p *code.Package) string {
+	return string(ResTypePackage) + "/" + p.Path + ".md"
+}
+
+// resourceURL returns a blank string if the resource is not documented.
+func (mp *markdownPrinter) resourceURL(p *code.Package, res code.Resource) string {
+	if !res.Exported() && !collectUnexporteds {
+		return ""
+	}
+	return mp.packageFile(p) + "#" + markdownHeadingAnchor(markdownResourceHeading(res))
+}
+
+func (mp *markdownPrinter) codeLink(text, url string) string {
+	fence := strings.Repeat("`", len(markdownCodeFence(text))-2)
+	if url == "" {
+		return fence + text + fence
+	}
+	return "[" + fence + text + fence + "](" + url + ")"
+}
+
+func (mp *markdownPrinter) printHeading(level int, title string) {
+	fmt.Fprintf(mp.out, "\n%s %s\n\n", strings.Repeat("#", level), title)
+}
+
+func (mp *markdownPrinter) printCode(lang, code string) {
+	code = strings.TrimRight(code, "\n")
+	if code == "" {
+		return
+	}
+	fence := markdownCodeFence(code)
+	fmt.Fprintf(mp.out, "%s%s\n%s\n%s\n", fence, lang, code, fence)
+}
+
+func (mp *markdownPrinter) printList(title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(mp.out, "\n**%s (%d):**\n\n", title, len(items))
+	for _, item := range items {
+		fmt.Fprintf(mp.out, "- %s\n", item)
+	}
+}
+
+func (mp *markdownPrinter) printDoc(doc string) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+
+	printer := &comment.Printer{
+		HeadingLevel: 4,
+		HeadingID:    func(*comment.Heading) string { return "" },
+		DocLinkURL: func(link *comment.DocLink) string {
+			pkg, res := mp.docLinkTarget(link)
+			switch {
+			case pkg == nil:
+				return ""
+			case res == nil:
+				return mp.packageFile(pkg)
+			default:
+				return mp.resourceURL(pkg, res)
+			}
+		},
+	}
+	io.WriteString(mp.out, "\n")
+	mp.out.Write(printer.Markdown(mp.parseDocComment(doc)))
+}
+
+// printDeclaration prints the declaration of a package-level resource
+// in a Go code block.
+func (mp *markdownPrinter) printDeclaration(res code.Resource) {
+	var buf bytes.Buffer
+	dp := mp.docPrinter
+	dp.out = &buf
+	dp.printDeclaration(res)
+	mp.printCode("go", buf.String())
+}
+
+func (mp *markdownPrinter) printPackage() {
+	details := mp.details
+	fmt.Fprintf(mp.out, "# Package %s\n\n", details.Name)
+	fmt.Fprintf(mp.out, "[Index](%sindex.md)\n\n", DotDotSlashes(strings.Count(details.ImportPath, "/")+1))
+	mp.printCode("go", fmt.Sprintf("import %q", details.ImportPath))
+
+	for _, f := range details.Files {
+		mp.printDoc(f.DocText)
+	}
+
+	mp.printIndex()
+
+	printValues := func(title string, values []ResourceWithPosition) {
+		if len(values) == 0 {
+			return
+		}
+		mp.printHeading(2, title)
+		for _, rwp := range values {
+			mp.printValue(rwp.Value)
+		}
+	}
+	printValues("Constants", details.Constants)
+	printValues("Variables", details.Variables)
+	printValues("Functions", details.Functions)
+
+	if len(details.TypeNames) > 0 {
+		mp.printHeading(2, "Types")
+		for _, rwp := range details.TypeNames {
+			mp.printType(rwp.Type)
+		}
+	}
+
+	mp.printExamples()
+}
+
+func (mp *markdownPrinter) printIndex() {
+	details := mp.details
+	mp.printHeading(2, "Index")
+
+	printItem := func(indent string, res code.Resource) {
+		heading := markdownResourceHeading(res)
+		fmt.Fprintf(mp.out, "%s- [%s](#%s)\n", indent, heading, markdownHeadingAnchor(heading))
+	}
+	printSection := func(title string, values []ResourceWithPosition) {
+		if len(values) == 0 {
+			return
+		}
+		fmt.Fprintf(mp.out, "- [%s](#%s)\n", title, markdownHeadingAnchor(title))
+		for _, rwp := range values {
+			printItem("  ", rwp.Value)
+		}
+	}
+	printSection("Constants", details.Constants)
+	printSection("Variables", details.Variables)
+	printSection("Functions", details.Functions)
+	if len(details.TypeNames) > 0 {
+		fmt.Fprintf(mp.out, "- [Types](#types)\n")
+		for _, rwp := range details.TypeNames {
+			printItem("  ", rwp.Type.TypeName)
+		}
+	}
+	if len(details.Examples) > 0 {
+		fmt.Fprintf(mp.out, "- [Examples](#examples)\n")
+	}
+}
+
+func (mp *markdownPrinter) printValue(v code.ValueResource) {
+	mp.printHeading(3, markdownResourceHeading(v))
+	mp.printDeclaration(v)
+	mp.printDoc(v.Documentation())
+}
+
+func (mp *markdownPrinter) printType(td *TypeDetails) {
+	tn := td.TypeName
+	mp.printHeading(3, markdownResourceHeading(tn))
+	mp.printDeclaration(tn)
+	mp.printDoc(tn.Documentation())
+
+	fields := make([]string, 0, len(td.Fields))
+	for _, f := range td.Fields {
+		fields = append(fields, mp.codeLink(mp.selectorText(f.Selector), ""))
+	}
+	mp.printList("Fields", fields)
+
+	methods := make([]string, 0, len(td.Methods))
+	for _, m := range td.Methods {
+		methods = append(methods, mp.codeLink(mp.selectorText(m), ""))
+	}
+	mp.printList("Methods", methods)
+
+	mp.printList("Implemented By", mp.typesForListingLinks(td.ImplementedBys))
+	mp.printList("Implements", mp.typesForListingLinks(td.Implements))
+	mp.printList("Values", mp.valuesForListingLinks(td.Values))
+	mp.printList("As Inputs Of", mp.valuesForListingLinks(td.AsInputsOf))
+	mp.printList("As Outputs Of", mp.valuesForListingLinks(td.AsOutputsOf))
+}
+
+func (mp *markdownPrinter) typesForListingLinks(tfls []*TypeForListing) []string {
+	links := make([]string, 0, len(tfls))
+	for _, t := range tfls {
+		var url string
+		if tn := t.BaseType.TypeName; tn != nil {
+			url = mp.resourceURL(tn.Package(), tn)
+		}
+		links = append(links, mp.codeLink(mp.typeForListingText(t), url))
+	}
+	return links
+}
+
+func (mp *markdownPrinter) valuesForListingLinks(vfls []*ValueForListing) []string {
+	texts := mp.valuesForListingText(vfls)
+	links := make([]string, 0, len(vfls))
+	for i, v := range vfls {
+		var url string
+		if f, ok := v.ValueResource.(code.FunctionResource); ok && f.IsMethod() {
+			if _, tn, _ := f.ReceiverTypeName(); tn != nil {
+				url = mp.resourceURL(tn.Package(), tn)
+			}
+		} else {
+			url = mp.resourceURL(v.Package(), v.ValueResource)
+		}
+		links = append(links, mp.codeLink(texts[i], url))
+	}
+	return links
+}
+
+func (mp *markdownPrinter) printExamples() {
+	examples := mp.details.Examples
+	if len(examples) == 0 {
+		return
+	}
+
+	mp.printHeading(2, "Examples")
+	for _, ex := range examples {
+		title := "Example"
+		if ex.Name != "" {
+			title += " " + ex.Name
+		}
+		mp.printHeading(3, title)
+		mp.printDoc(ex.Doc)
+
+		var buf bytes.Buffer
+		if ex.Play != nil {
+			format.Node(&buf, mp.details.ExampleFileSet, ex.Play)
+		} else {
+			format.Node(&buf, mp.details.ExampleFileSet, ex.Code)
+		}
+		mp.printCode("go", buf.String())
+
+		if ex.Output != "" {
+			io.WriteString(mp.out, "\nOutput:\n\n")
+			mp.printCode("", ex.Output)
+		}
+	}
+}
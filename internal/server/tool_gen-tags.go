@@ -0,0 +1,298 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go101.org/golds/code"
+)
+
+// GenTags generates a Universal-ctags file (tags) and an Emacs etags file (TAGS)
+// in outputDir for the declarations in the analyzed packages.
+func GenTags(options PageOutputOptions, args []string, outputDir string, silentMode bool, printUsage func(io.Writer)) {
+	toolchain, err := findToolchainInfo()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	forTesting := outputDir == ""
+
+	ds := &docServer{}
+	ds.analyze(args, options, toolchain, forTesting, printUsage)
+
+	entries := collectTagEntries(ds.analyzer)
+	if forTesting {
+		return
+	}
+
+	if err := os.MkdirAll(outputDir, 0700); err != nil {
+		log.Fatalln("Mkdir error:", err)
+	}
+	absOutputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	for i := range entries {
+		e := &entries[i]
+		if rel, err := filepath.Rel(absOutputDir, e.File); err == nil {
+			e.File = filepath.ToSlash(rel)
+		}
+	}
+
+	writeTagsFile := func(filename string, write func(w io.Writer) error) {
+		path := filepath.Join(outputDir, filename)
+		f, err := os.Create(path)
+		if err != nil {
+			log.Fatalln("Create file error:", err)
+		}
+		w := bufio.NewWriter(f)
+		if err := write(w); err != nil {
+			log.Fatalln("Write file error:", err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Fatalln("Write file error:", err)
+		}
+		if err := f.Close(); err != nil {
+			log.Fatalln("Write file error:", err)
+		}
+		if !silentMode {
+			log.Printf("Generated %s.", path)
+		}
+	}
+
+	writeTagsFile("tags", func(w io.Writer) error {
+		return writeCtags(w, entries, options.GoldsVersion)
+	})
+	writeTagsFile("TAGS", func(w io.Writer) error {
+		return writeEtags(w, entries, absOutputDir)
+	})
+
+	log.Printf("%d tags are generated in %s.", len(entries), outputDir)
+}
+
+type tagEntry struct {
+	Name   string
+	File   string
+	Line   int
+	Offset int // byte offset of the start of the name in the file
+	Kind   string
+
+	// Extended fields, in the "key:value" form.
+	Fields []string
+}
+
+func findIdentByName(names []*ast.Ident, name string) *ast.Ident {
+	for _, n := range names {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+func collectTagEntries(analyzer *code.CodeAnalyzer) []tagEntry {
+	var entries []tagEntry
+	add := func(pkg *code.Package, node ast.Node, name, kind string, fields ...string) {
+		pos := pkg.PPkg.Fset.PositionFor(node.Pos(), false)
+		entries = append(entries, tagEntry{
+			Name:   name,
+			File:   pos.Filename,
+			Line:   pos.Line,
+			Offset: pos.Offset,
+			Kind:   kind,
+			Fields: append(fields, "package:"+pkg.Path),
+		})
+	}
+
+	for i, n := 0, analyzer.NumPackages(); i < n; i++ {
+		pkg := analyzer.PackageAt(i)
+		qualifier := types.RelativeTo(pkg.PPkg.Types)
+
+		for _, tn := range pkg.AllTypeNames {
+			if tn.AstSpec == nil {
+				continue
+			}
+			if tn.IsAlias() {
+				add(pkg, tn.AstSpec.Name, tn.Name(), "talias")
+				continue
+			}
+
+			kind, scope := "type", "type:"+tn.Name()
+			_, isInterface := tn.Denoting.TT.Underlying().(*types.Interface)
+			switch {
+			case isInterface:
+				kind, scope = "interface", "interface:"+tn.Name()
+			case tn.Denoting.Kind() == reflect.Struct:
+				kind, scope = "struct", "struct:"+tn.Name()
+			}
+			if impls := tagImplementedInterfaces(tn.Denoting, qualifier); impls != "" {
+				add(pkg, tn.AstSpec.Name, tn.Name(), kind, "inherits:"+impls)
+			} else {
+				add(pkg, tn.AstSpec.Name, tn.Name(), kind)
+			}
+
+			for _, sel := range tn.Denoting.DirectSelectors {
+				if sel.Field != nil {
+					fld := sel.Field
+					if ident := findIdentByName(fld.AstField.Names, fld.Name); ident != nil {
+						add(pkg, ident, fld.Name, "member", scope)
+					} else { // embedded
+						add(pkg, fld.AstField.Type, fld.Name, "anonMember", scope)
+					}
+				} else if mthd := sel.Method; mthd.AstFunc != nil {
+					receiver := tn.Name()
+					if mthd.PointerRecv {
+						receiver = "*" + receiver
+					}
+					add(pkg, mthd.AstFunc.Name, mthd.Name, "method", scope, "receiver:"+receiver)
+				} else if len(mthd.AstField.Names) > 0 {
+					add(pkg, mthd.AstField.Names[0], mthd.Name, "methodSpec", scope)
+				}
+			}
+		}
+		for _, f := range pkg.AllFunctions {
+			if f.AstDecl == nil || f.IsMethod() {
+				continue
+			}
+			add(pkg, f.AstDecl.Name, f.Name(), "func")
+		}
+		for _, v := range pkg.AllVariables {
+			if ident := findIdentByName(v.AstSpec.Names, v.Name()); ident != nil {
+				add(pkg, ident, v.Name(), "var")
+			}
+		}
+		for _, c := range pkg.AllConstants {
+			if ident := findIdentByName(c.AstSpec.Names, c.Name()); ident != nil {
+				add(pkg, ident, c.Name(), "const")
+			}
+		}
+	}
+
+	sortTagEntries(entries)
+	return entries
+}
+
+// tagImplementedInterfaces returns the comma separated named interface types
+// implemented by the type (or the pointer type of the type).
+func tagImplementedInterfaces(t *code.TypeInfo, qualifier types.Qualifier) string {
+	var names []string
+	seen := make(map[*code.TypeInfo]bool, len(t.Implements))
+	for _, impl := range t.Implements {
+		it := impl.Interface
+		if it.TypeName == nil || seen[it] {
+			continue
+		}
+		seen[it] = true
+		names = append(names, types.TypeString(it.TT, qualifier))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func sortTagEntries(entries []tagEntry) {
+	sort.SliceStable(entries, func(a, b int) bool {
+		ea, eb := &entries[a], &entries[b]
+		if ea.Name != eb.Name {
+			return ea.Name < eb.Name
+		}
+		if ea.File != eb.File {
+			return ea.File < eb.File
+		}
+		return ea.Line < eb.Line
+	})
+}
+
+// writeCtags writes entries in the Universal-ctags extended format.
+// The entries must be sorted by name.
+func writeCtags(w io.Writer, entries []tagEntry, goldsVersion string) error {
+	header := "!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;\" to lines/\n" +
+		"!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/\n" +
+		"!_TAG_PROGRAM_NAME\tgolds\t//\n" +
+		"!_TAG_PROGRAM_URL\thttps://go101.org/apps-and-libs/golds.html\t//\n" +
+		"!_TAG_PROGRAM_VERSION\t" + goldsVersion + "\t//\n"
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%d;\"\tkind:%s", e.Name, e.File, e.Line, e.Kind); err != nil {
+			return err
+		}
+		for _, f := range e.Fields {
+			if _, err := fmt.Fprintf(w, "\t%s", f); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEtags writes entries in the Emacs etags format.
+// The file paths in entries are relative to baseDir, if they are not absolute.
+func writeEtags(w io.Writer, entries []tagEntry, baseDir string) error {
+	byFile := make(map[string][]*tagEntry)
+	var files []string
+	for i := range entries {
+		e := &entries[i]
+		if byFile[e.File] == nil {
+			files = append(files, e.File)
+		}
+		byFile[e.File] = append(byFile[e.File], e)
+	}
+	sort.Strings(files)
+
+	var section bytes.Buffer
+	for _, file := range files {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, filepath.FromSlash(path))
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		fileEntries := byFile[file]
+		sort.SliceStable(fileEntries, func(a, b int) bool {
+			return fileEntries[a].Offset < fileEntries[b].Offset
+		})
+
+		section.Reset()
+		for _, e := range fileEntries {
+			writeEtagsLine(&section, content, e)
+		}
+		if _, err := fmt.Fprintf(w, "\x0c\n%s,%d\n", file, section.Len()); err != nil {
+			return err
+		}
+		if _, err := w.Write(section.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEtagsLine writes a tag line, which consists of the line content
+// until the end of the tag name, the tag name, the line number and the
+// byte offset of the line start.
+func writeEtagsLine(w *bytes.Buffer, content []byte, e *tagEntry) {
+	end := e.Offset + len(e.Name)
+	if end > len(content) {
+		end = len(content)
+	}
+	lineStart := bytes.LastIndexByte(content[:e.Offset], '\n') + 1
+	w.Write(content[lineStart:end])
+	fmt.Fprintf(w, "\x7f%s\x01%d,%d\n", e.Name, e.Line, lineStart)
+}
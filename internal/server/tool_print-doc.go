@@ -0,0 +1,462 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/doc/comment"
+	"go/format"
+	"go/types"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"go101.org/golds/code"
+	"go101.org/golds/internal/util"
+)
+
+// PrintDoc prints the plain-text docs of a package, or a package-level
+// identifier, or a selector of a type, in the "go doc" way, plus some
+// extra info collected by Golds, such as implementation relations
+// and reference counts.
+//
+// The query is in the form of pkg[.Identifier[.Selector]].
+// If the package path contains dots in its last token,
+// use ".." to separate the package path and the identifier,
+// for example, "gopkg.in/yaml.v3..Node.Kind".
+//
+// The packages to analyze are specified by args.
+// If args is blank, then only the queried package
+// (and its dependencies) will be analyzed.
+func PrintDoc(options PageOutputOptions, query string, args []string, out io.Writer, printUsage func(io.Writer)) error {
+	pkgPath, idents := splitDocQuery(query)
+	if pkgPath == "" {
+		return errors.New("package is not specified")
+	}
+	if len(idents) > 2 {
+		return fmt.Errorf("invalid identifier: %s", strings.Join(idents, "."))
+	}
+	ds, pkg, err := analyzeForQueriedPackage(options, pkgPath, args, printUsage)
+	if err != nil {
+		return err
+	}
+
+	details := buildPackageDetailsData(ds.analyzer, pkg.Path, collectUnexporteds)
+	dp := &docPrinter{ds: ds, pkg: pkg, details: details, out: out}
+
+	fmt.Fprintf(out, "package %s // import %q\n\n", details.Name, details.ImportPath)
+	switch len(idents) {
+	case 0:
+		dp.printPackage()
+		return nil
+	case 1:
+		for _, rwp := range details.TypeNames {
+			if rwp.Type.TypeName.Name() == idents[0] {
+				dp.printType(rwp.Type)
+				return nil
+			}
+		}
+		for _, list := range [][]ResourceWithPosition{details.Functions, details.Variables, details.Constants} {
+			for _, rwp := range list {
+				if rwp.Value.Name() == idents[0] {
+					dp.printValue(rwp.Value)
+					return nil
+				}
+			}
+		}
+	case 2:
+		for _, rwp := range details.TypeNames {
+			if rwp.Type.TypeName.Name() == idents[0] {
+				if dp.printSelector(rwp.Type, idents[1]) {
+					return nil
+				}
+				break
+			}
+		}
+	}
+	return fmt.Errorf("%s is not found in package %s", strings.Join(idents, "."), pkg.Path)
+}
+
+// analyzeForQueriedPackage analyzes the packages specified by args
+// (or the queried package if args is blank), then finds the queried package.
+func analyzeForQueriedPackage(options PageOutputOptions, pkgPath string, args []string, printUsage func(io.Writer)) (*docServer, *code.Package, error) {
+	if len(args) == 0 {
+		args = []string{pkgPath}
+	}
+
+	toolchain, err := findToolchainInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ds := &docServer{}
+	ds.analyze(args, options, toolchain, false, printUsage)
+
+	pkg := ds.analyzer.PackageByPath(pkgPath)
+	if pkg == nil && (pkgPath == "." || strings.HasPrefix(pkgPath, "./") || strings.HasPrefix(pkgPath, "../")) {
+		dir, err := filepath.Abs(pkgPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i, n := 0, ds.analyzer.NumPackages(); i < n; i++ {
+			if p := ds.analyzer.PackageAt(i); p.Directory == dir {
+				pkg = p
+				break
+			}
+		}
+	}
+	if pkg == nil {
+		return nil, nil, fmt.Errorf("package %s is not found", pkgPath)
+	}
+	return ds, pkg, nil
+}
+
+// splitDocQuery splits a doc query into the package path and identifiers.
+// For example, "net/http.Client.Do" is split into ("net/http", [Client Do])
+// and "gopkg.in/yaml.v3..Node" is split into ("gopkg.in/yaml.v3", [Node]).
+func splitDocQuery(query string) (pkgPath string, idents []string) {
+	start := strings.LastIndex(query, "/") + 1
+	if strings.Trim(query[start:], ".") == "" { // ".", "..", "./..", etc.
+		return query, nil
+	}
+	index, sep := strings.Index(query[start:], ".."), ".."
+	if index < 0 {
+		index, sep = strings.Index(query[start:], "."), "."
+	}
+	if index < 0 {
+		return query, nil
+	}
+	if index == 0 && start == 0 && sep == "." { // ".Ident" is not supported.
+		return "", nil
+	}
+	index += start
+	if index+len(sep) == len(query) { // "gopkg.in/yaml.v3.."
+		return query[:index], nil
+	}
+	return query[:index], strings.Split(query[index+len(sep):], ".")
+}
+
+type docPrinter struct {
+	ds      *docServer
+	pkg     *code.Package
+	details *PackageDetails
+	out     io.Writer
+}
+
+const docIndent = "    "
+
+func (dp *docPrinter) printDoc(doc string) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+	w := util.NewIndentWriter(dp.out, []byte(docIndent))
+	io.WriteString(w, doc)
+	io.WriteString(dp.out, "\n")
+}
+
+// parseDocComment parses a doc comment in the current package with go/doc/comment.
+// The doc links in the comment are resolved in the same way as the HTML pages.
+func (dp *docPrinter) parseDocComment(doc string) *comment.Doc {
+	parser := &comment.Parser{
+		LookupPackage: func(name string) (importPath string, ok bool) {
+			pkg, res, _ := dp.ds.resolveDocLink(dp.pkg, name)
+			if pkg == nil || res != nil {
+				return "", false
+			}
+			return pkg.Path, true
+		},
+		LookupSym: func(recv, name string) bool {
+			if recv != "" {
+				name = recv + "." + name
+			}
+			pkg, res, _ := dp.ds.resolveDocLink(dp.pkg, name)
+			return pkg == dp.pkg && res != nil
+		},
+	}
+	return parser.Parse(doc)
+}
+
+// docLinkTarget returns the package and the package-level resource
+// a doc link links to. For a link to a selector, the resource is the
+// type name. A nil res means the link is a package link.
+func (dp *docPrinter) docLinkTarget(link *comment.DocLink) (pkg *code.Package, res code.Resource) {
+	pkg = dp.pkg
+	if link.ImportPath != "" {
+		if pkg = dp.ds.analyzer.PackageByPath(link.ImportPath); pkg == nil {
+			return nil, nil
+		}
+	}
+	name := link.Name
+	if link.Recv != "" {
+		name = link.Recv
+	}
+	if name == "" {
+		return pkg, nil
+	}
+	if res = pkg.SearchResourceByName(name); res == nil {
+		return nil, nil
+	}
+	return pkg, res
+}
+
+func (dp *docPrinter) printNode(node interface{}) {
+	format.Node(dp.out, dp.pkg.PPkg.Fset, node)
+	io.WriteString(dp.out, "\n")
+}
+
+func (dp *docPrinter) printSection(title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(dp.out, "\n%s (%d):\n", title, len(lines))
+	for _, ln := range lines {
+		fmt.Fprintf(dp.out, "%s%s\n", docIndent, ln)
+	}
+}
+
+func (dp *docPrinter) qualifier() types.Qualifier {
+	return types.RelativeTo(dp.pkg.PPkg.Types)
+}
+
+func (dp *docPrinter) typeString(tt types.Type) string {
+	if tt == nil {
+		return ""
+	}
+	return types.TypeString(tt, dp.qualifier())
+}
+
+func (dp *docPrinter) printReferences(tokens ...string) {
+	result, err := dp.ds.buildReferencesData(dp.pkg.Path, tokens...)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(dp.out, "\nReferences: %d uses in %d packages\n", result.UsesCount, len(result.References))
+}
+
+func (dp *docPrinter) printPackage() {
+	for _, f := range dp.details.Files {
+		dp.printDoc(f.DocText)
+	}
+
+	names := func(list []ResourceWithPosition) []string {
+		r := make([]string, 0, len(list))
+		for _, rwp := range list {
+			if rwp.Value.Exported() {
+				r = append(r, rwp.Value.Name())
+			}
+		}
+		return r
+	}
+	dp.printSection("Constants", names(dp.details.Constants))
+	dp.printSection("Variables", names(dp.details.Variables))
+	dp.printSection("Functions", names(dp.details.Functions))
+
+	typeNames := make([]string, 0, len(dp.details.TypeNames))
+	for _, rwp := range dp.details.TypeNames {
+		if tn := rwp.Type.TypeName; tn.Exported() {
+			typeNames = append(typeNames, tn.Name())
+		}
+	}
+	dp.printSection("Types", typeNames)
+}
+
+func (dp *docPrinter) printType(td *TypeDetails) {
+	tn := td.TypeName
+	dp.printDeclaration(tn)
+	dp.printDoc(tn.Documentation())
+
+	fields := make([]string, 0, len(td.Fields))
+	for _, f := range td.Fields {
+		fields = append(fields, dp.selectorText(f.Selector))
+	}
+	dp.printSection("Fields", fields)
+
+	methods := make([]string, 0, len(td.Methods))
+	for _, m := range td.Methods {
+		methods = append(methods, dp.selectorText(m))
+	}
+	dp.printSection("Methods", methods)
+
+	dp.printSection("Implemented By", dp.typesForListingText(td.ImplementedBys))
+	dp.printSection("Implements", dp.typesForListingText(td.Implements))
+	dp.printSection("Values", dp.valuesForListingText(td.Values))
+	dp.printSection("As Inputs Of", dp.valuesForListingText(td.AsInputsOf))
+	dp.printSection("As Outputs Of", dp.valuesForListingText(td.AsOutputsOf))
+
+	dp.printReferences(tn.Name())
+}
+
+func (dp *docPrinter) printValue(v code.ValueResource) {
+	dp.printDeclaration(v)
+	dp.printDoc(v.Documentation())
+
+	dp.printReferences(v.Name())
+}
+
+// printDeclaration prints the declaration (without docs and function bodies)
+// of a package-level resource.
+func (dp *docPrinter) printDeclaration(res code.Resource) {
+	valueSpecWithoutDoc := func(spec *ast.ValueSpec) *ast.ValueSpec {
+		s := *spec
+		s.Doc, s.Comment = nil, nil
+		return &s
+	}
+
+	switch res := res.(type) {
+	case *code.TypeName:
+		if res.AstSpec != nil {
+			io.WriteString(dp.out, "type ")
+			dp.printNode(res.AstSpec)
+		} else {
+			fmt.Fprintf(dp.out, "type %s %s\n", res.Name(), dp.typeString(res.Denoting.TT.Underlying()))
+		}
+	case *code.Function:
+		if res.AstDecl != nil {
+			decl := *res.AstDecl
+			decl.Doc, decl.Body = nil, nil
+			dp.printNode(&decl)
+		} else {
+			fmt.Fprintf(dp.out, "func %s%s\n", res.Name(), strings.TrimPrefix(dp.typeString(res.TType()), "func"))
+		}
+	case *code.Variable:
+		io.WriteString(dp.out, "var ")
+		dp.printNode(valueSpecWithoutDoc(res.AstSpec))
+	case *code.Constant:
+		io.WriteString(dp.out, "const ")
+		dp.printNode(valueSpecWithoutDoc(res.AstSpec))
+	}
+}
+
+// printSelector returns false if the selector is not found.
+func (dp *docPrinter) printSelector(td *TypeDetails, selName string) bool {
+	var sel *code.Selector
+	for _, f := range td.Fields {
+		if f.Name() == selName {
+			sel = f.Selector
+			break
+		}
+	}
+	for _, m := range td.Methods {
+		if sel != nil {
+			break
+		}
+		if m.Name() == selName {
+			sel = m
+		}
+	}
+	if sel == nil {
+		return false
+	}
+
+	fmt.Fprintf(dp.out, "%s\n", dp.selectorText(sel))
+	if sel.Field != nil {
+		dp.printDoc(sel.Field.Documentation())
+	} else {
+		dp.printDoc(sel.Method.Documentation())
+	}
+
+	tn := td.TypeName
+	if sel.Method != nil && (collectUnexporteds || tn.Exported()) {
+		if result, err := dp.ds.buildImplementationData(dp.ds.analyzer, dp.pkg.Path, tn.Name()); err == nil {
+			for _, m := range result.Methods {
+				if m.Method.Name() != selName {
+					continue
+				}
+				impls := make([]string, 0, len(m.Implementations))
+				for _, impl := range m.Implementations {
+					impls = append(impls, dp.typeForListingText(impl.Receiver)+"."+selName)
+				}
+				dp.printSection("Implementations", impls)
+				break
+			}
+		}
+	}
+
+	if sel.Depth == 0 {
+		dp.printReferences(tn.Name(), selName)
+	}
+	return true
+}
+
+func (dp *docPrinter) selectorText(sel *code.Selector) string {
+	var b strings.Builder
+	if sel.Field != nil {
+		b.WriteString(sel.Name())
+		b.WriteByte(' ')
+		if t := sel.Type(); t != nil {
+			b.WriteString(dp.typeString(t.TT))
+		}
+	} else {
+		b.WriteString("func ")
+		b.WriteString(sel.Name())
+		if t := sel.Type(); t != nil {
+			b.WriteString(strings.TrimPrefix(dp.typeString(t.TT), "func"))
+		}
+	}
+	if sel.Depth > 0 {
+		b.WriteString(" // promoted via ")
+		b.WriteString(sel.String())
+	}
+	return b.String()
+}
+
+func (dp *docPrinter) typeForListingText(t *TypeForListing) string {
+	var b strings.Builder
+	if t.IsPointer {
+		b.WriteByte('*')
+	}
+	if tn := t.BaseType.TypeName; tn != nil {
+		if p := tn.Package(); p != dp.pkg {
+			b.WriteString(p.Path)
+			b.WriteByte('.')
+		}
+		if t.NameWithTypeArgs != "" {
+			b.WriteString(t.NameWithTypeArgs)
+		} else {
+			b.WriteString(tn.Name())
+		}
+	} else {
+		b.WriteString(dp.typeString(t.BaseType.TT))
+	}
+	return b.String()
+}
+
+func (dp *docPrinter) typesForListingText(tfls []*TypeForListing) []string {
+	lines := make([]string, 0, len(tfls))
+	for _, t := range tfls {
+		lines = append(lines, dp.typeForListingText(t))
+	}
+	return lines
+}
+
+func (dp *docPrinter) valuesForListingText(vfls []*ValueForListing) []string {
+	lines := make([]string, 0, len(vfls))
+	for _, v := range vfls {
+		var b strings.Builder
+		switch v.ValueResource.(type) {
+		case *code.Variable:
+			b.WriteString("var ")
+		case *code.Constant:
+			b.WriteString("const ")
+		default:
+			b.WriteString("func ")
+		}
+		if p := v.Package(); p != dp.pkg {
+			b.WriteString(p.Path)
+			b.WriteByte('.')
+		}
+		if f, ok := v.ValueResource.(code.FunctionResource); ok && f.IsMethod() {
+			if _, tn, isStar := f.ReceiverTypeName(); tn != nil {
+				if isStar {
+					fmt.Fprintf(&b, "(*%s).", tn.Name())
+				} else {
+					fmt.Fprintf(&b, "%s.", tn.Name())
+				}
+			}
+		}
+		b.WriteString(v.Name())
+		lines = append(lines, b.String())
+	}
+	return lines
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Query answers a relation query non-interactively.
+// Supported relations:
+//
+// * implements pkg.Type: the types implementing the specified interface type.
+// * refs pkg.Identifier[.Selector]: the references of the specified identifier.
+// * importers pkg: the packages importing the specified package.
+// * methods pkg.Type: the methods (including promoted ones) of the specified type.
+//
+// The target is in the same form as the PrintDoc query.
+// The results are output as JSON if jsonOutput is true,
+// otherwise, one result per line.
+func Query(options PageOutputOptions, relation, target string, args []string, jsonOutput bool, out io.Writer, printUsage func(io.Writer)) error {
+	pkgPath, idents := splitDocQuery(target)
+	if pkgPath == "" {
+		return errors.New("package is not specified")
+	}
+
+	switch relation {
+	default:
+		return fmt.Errorf("unknown query relation: %s", relation)
+	case "importers":
+		if len(idents) != 0 {
+			return fmt.Errorf("a package is expected: %s", target)
+		}
+	case "implements", "methods":
+		if len(idents) != 1 {
+			return fmt.Errorf("a type is expected: %s", target)
+		}
+	case "refs":
+		if len(idents) == 0 || len(idents) > 2 {
+			return fmt.Errorf("an identifier or a selector is expected: %s", target)
+		}
+	}
+
+	ds, pkg, err := analyzeForQueriedPackage(options, pkgPath, args, printUsage)
+	if err != nil {
+		return err
+	}
+
+	var result interface{}
+	var lines []string
+	switch relation {
+	case "importers":
+		deps := buildAPIData_Dependencies(ds.buildPackageDependenciesData(pkg.Path))
+		result, lines = deps.ImportedBys, deps.ImportedBys
+	case "implements", "methods":
+		details := buildPackageDetailsData(ds.analyzer, pkg.Path, collectUnexporteds)
+		var td *TypeDetails
+		for _, rwp := range details.TypeNames {
+			if rwp.Type.TypeName.Name() == idents[0] {
+				td = rwp.Type
+				break
+			}
+		}
+		if td == nil {
+			return fmt.Errorf("type %s is not found in package %s", idents[0], pkg.Path)
+		}
+		if relation == "methods" {
+			methods := apiDataSelectors(td.Methods, pkg)
+			for _, m := range methods {
+				lines = append(lines, m.Name+" "+m.Type)
+			}
+			result = methods
+		} else {
+			impls := apiDataTypeRefs(td.ImplementedBys)
+			for _, t := range impls {
+				lines = append(lines, queryTypeRefText(t))
+			}
+			result = impls
+		}
+	case "refs":
+		refs, err := ds.buildReferencesData(pkg.Path, idents...)
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs.References {
+			for i := range ref.Identifiers {
+				pos := ref.Pkg.PPkg.Fset.PositionFor(ref.Identifiers[i].AstIdent.NamePos, false)
+				lines = append(lines, pos.String())
+			}
+		}
+		result = buildAPIData_References(refs)
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(result, "", "\t")
+		if err != nil {
+			return err
+		}
+		out.Write(data)
+		io.WriteString(out, "\n")
+		return nil
+	}
+
+	for _, ln := range lines {
+		io.WriteString(out, ln)
+		io.WriteString(out, "\n")
+	}
+	return nil
+}
+
+func queryTypeRefText(t APIData_TypeRef) string {
+	var b strings.Builder
+	if t.IsPointer {
+		b.WriteByte('*')
+	}
+	if t.Package != "" {
+		b.WriteString(t.Package)
+		b.WriteByte('.')
+	}
+	b.WriteString(t.Name)
+	return b.String()
+}
@@ -0,0 +1,401 @@
+package translations
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Catalog is a translation whose messages are loaded from a message
+// catalog file, so that new languages could be supported without
+// writing Go code. The English translation is used for the messages
+// missing in the catalog.
+//
+// The key of a message is the name of the corresponding Text_Xxx method
+// (see the Translation interface in the server package) without the
+// "Text_" prefix, such as "Overview" and "ImportStat". A message is a
+// text/template template, in which the arguments of the method could be
+// referenced by their parameter names, such as
+//
+//	imports {{.numImports}} packages, and imported by {{.numImportedBys}}
+//
+// The segments of the statistics messages (the methods returning string
+// slices) use the keys suffixed with their indexes, such as
+// "TypeStatistics.1", and their data are the statistics values.
+//
+// Two catalog file formats are supported. A .json file contains
+//
+//	{
+//		"name": "日本語",
+//		"lang": "ja",
+//		"messages": {
+//			"Overview": "概要",
+//			...
+//		}
+//	}
+//
+// and a .po file is a gettext PO file, in which the msgids are the
+// message keys. The lang and name of a PO file are specified in the
+// "Language" and "X-Language-Name" header fields. Fuzzy and blank
+// translations are ignored. In both formats, the lang defaults to
+// the file name without the extension, and the name defaults to lang.
+//
+// Unknown message keys and the messages failing to be executed with
+// the arguments of their methods are reported when loading a catalog.
+// The statistics messages could only be checked when being used, and
+// their execution errors are logged (once for each message).
+type Catalog struct {
+	English
+
+	name     string
+	langTag  string
+	messages map[string]*template.Template
+
+	// The execution errors are collected into checkErrors
+	// (if it is not nil) when checking the messages.
+	checkErrors *[]error
+
+	mu       sync.Mutex
+	reported map[string]bool // the keys of the logged failed messages
+}
+
+func (c *Catalog) Name() string { return c.name }
+
+func (c *Catalog) LangTag() string { return c.langTag }
+
+type catalogData map[string]interface{}
+
+var catalogFuncs = template.FuncMap{
+	// div is used to calculate averages.
+	"div": func(a, b interface{}) (float64, error) {
+		x, err := catalogFloat(a)
+		if err != nil {
+			return 0, err
+		}
+		y, err := catalogFloat(b)
+		if err != nil {
+			return 0, err
+		}
+		return x / y, nil
+	},
+}
+
+func catalogFloat(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	}
+	return 0, fmt.Errorf("not a number: %v", v)
+}
+
+// text returns the message of key. ok is false if the message
+// is missing in the catalog or fails to be executed.
+func (c *Catalog) text(key string, data catalogData) (s string, ok bool) {
+	t := c.messages[key]
+	if t == nil {
+		return "", false
+	}
+	var buf strings.Builder
+	if err := t.Execute(&buf, data); err != nil {
+		if c.checkErrors != nil {
+			*c.checkErrors = append(*c.checkErrors, fmt.Errorf("message %s: %w", key, err))
+			return "", true // no needs to build the fallback text
+		}
+		c.reportError(key, err)
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// reportError logs the execution error of a message. The error of
+// a message is only logged once, for messages are used frequently.
+func (c *Catalog) reportError(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reported[key] {
+		return
+	}
+	if c.reported == nil {
+		c.reported = make(map[string]bool)
+	}
+	c.reported[key] = true
+	log.Printf("catalog %s: message %s: %s (the English text is used)", c.langTag, key, err)
+}
+
+// texts replaces the segments in fallbacks with the ones in the catalog.
+func (c *Catalog) texts(key string, data catalogData, fallbacks []string) []string {
+	for i := range fallbacks {
+		if s, ok := c.text(key+"."+strconv.Itoa(i), data); ok {
+			fallbacks[i] = s
+		}
+	}
+	return fallbacks
+}
+
+// catalogMessages returns the Text_Xxx methods of Catalog by their
+// message keys. The keys of the segments of a statistics message are
+// mapped to the method of the message.
+func catalogMessages() map[string]reflect.Method {
+	var english = reflect.ValueOf(&English{})
+	var catalogType = reflect.TypeOf(&Catalog{})
+	var stringsType = reflect.TypeOf([]string(nil))
+
+	methods := make(map[string]reflect.Method, catalogType.NumMethod())
+	for i := 0; i < catalogType.NumMethod(); i++ {
+		m := catalogType.Method(i)
+		if !strings.HasPrefix(m.Name, "Text_") {
+			continue
+		}
+		key := strings.TrimPrefix(m.Name, "Text_")
+		if m.Type.NumOut() == 1 && m.Type.Out(0) == stringsType {
+			// The English texts are used to get the number of segments.
+			n := english.MethodByName(m.Name).Call(zeroArguments(m.Type, 1))[0].Len()
+			for k := 0; k < n; k++ {
+				methods[key+"."+strconv.Itoa(k)] = m
+			}
+			continue
+		}
+		methods[key] = m
+	}
+	return methods
+}
+
+// zeroArguments returns the zero values of the parameters of a function
+// type, from the parameter at index from. Pointers point to zero values.
+func zeroArguments(funcType reflect.Type, from int) []reflect.Value {
+	args := make([]reflect.Value, funcType.NumIn()-from)
+	for i := range args {
+		t := funcType.In(from + i)
+		if t.Kind() == reflect.Ptr {
+			args[i] = reflect.New(t.Elem())
+		} else {
+			args[i] = reflect.Zero(t)
+		}
+	}
+	return args
+}
+
+// check reports the unknown message keys, and the messages which
+// fail to be executed with zero argument values (mostly for using
+// misspelled argument names). The statistics messages are not
+// executed, for their data are maps of dynamic values.
+func (c *Catalog) check() error {
+	methods := catalogMessages()
+
+	var unknowns []string
+	for key := range c.messages {
+		if _, ok := methods[key]; !ok {
+			unknowns = append(unknowns, key)
+		}
+	}
+	if len(unknowns) > 0 {
+		sort.Strings(unknowns)
+		return fmt.Errorf("unknown message keys: %s", strings.Join(unknowns, ", "))
+	}
+
+	var errs []error
+	c.checkErrors = &errs
+	defer func() { c.checkErrors = nil }()
+
+	keys := make([]string, 0, len(c.messages))
+	for key := range c.messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	catalog := reflect.ValueOf(c)
+	for _, key := range keys {
+		m := methods[key]
+		if strings.Contains(key, ".") {
+			continue // a segment of a statistics message
+		}
+		catalog.Method(m.Index).Call(zeroArguments(m.Type, 1))
+	}
+	return errors.Join(errs...)
+}
+
+// LoadCatalogs loads the .json and .po catalog files in dir.
+// Invalid catalog files are skipped and the errors for them are returned.
+func LoadCatalogs(dir string) (catalogs []*Catalog, errs []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, []error{err}
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch filepath.Ext(e.Name()) {
+		default:
+			continue
+		case ".json", ".po":
+		}
+		c, err := LoadCatalog(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		catalogs = append(catalogs, c)
+	}
+	return catalogs, errs
+}
+
+// LoadCatalog loads a .json or .po catalog file.
+func LoadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var name, lang string
+	var messages map[string]string
+	switch ext := filepath.Ext(path); ext {
+	default:
+		return nil, fmt.Errorf("catalog %s: unsupported file format: %s", path, ext)
+	case ".json":
+		var catalog struct {
+			Name     string            `json:"name"`
+			Lang     string            `json:"lang"`
+			Messages map[string]string `json:"messages"`
+		}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("catalog %s: %w", path, err)
+		}
+		name, lang, messages = catalog.Name, catalog.Lang, catalog.Messages
+	case ".po":
+		var header map[string]string
+		header, messages, err = parsePO(data)
+		if err != nil {
+			return nil, fmt.Errorf("catalog %s: %w", path, err)
+		}
+		name, lang = header["X-Language-Name"], header["Language"]
+	}
+
+	if lang == "" {
+		lang = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if name == "" {
+		name = lang
+	}
+
+	c := &Catalog{
+		name:     name,
+		langTag:  lang,
+		messages: make(map[string]*template.Template, len(messages)),
+	}
+	for key, msg := range messages {
+		t, err := template.New(key).Funcs(catalogFuncs).Option("missingkey=error").Parse(msg)
+		if err != nil {
+			return nil, fmt.Errorf("catalog %s: %w", path, err)
+		}
+		c.messages[key] = t
+	}
+	if err := c.check(); err != nil {
+		return nil, fmt.Errorf("catalog %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// parsePO parses the header fields and translations in a PO file.
+// Only the features used by the catalogs are supported.
+func parsePO(data []byte) (header, messages map[string]string, err error) {
+	messages = make(map[string]string)
+
+	var msgid, msgstr *strings.Builder
+	var current *strings.Builder
+	var fuzzy, hasEntry bool
+	flush := func() {
+		if hasEntry {
+			if msgid.Len() == 0 {
+				// The header entry is often marked as fuzzy.
+				header = parsePOHeader(msgstr.String())
+			} else if msgstr.Len() > 0 && !fuzzy {
+				messages[msgid.String()] = msgstr.String()
+			}
+		}
+		msgid, msgstr, current = &strings.Builder{}, &strings.Builder{}, nil
+		fuzzy, hasEntry = false, false
+	}
+	flush()
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		var quoted string
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#"):
+			if hasEntry {
+				flush()
+			}
+			if strings.HasPrefix(line, "#,") && strings.Contains(line, "fuzzy") {
+				fuzzy = true
+			}
+			continue
+		case strings.HasPrefix(line, "msgctxt "):
+			if hasEntry {
+				flush()
+			}
+			current = nil // contexts are not used
+			continue
+		case strings.HasPrefix(line, "msgid "):
+			if hasEntry {
+				flush()
+			}
+			hasEntry, current, quoted = true, msgid, line[len("msgid "):]
+		case strings.HasPrefix(line, "msgstr "):
+			current, quoted = msgstr, line[len("msgstr "):]
+		case strings.HasPrefix(line, "msgid_plural "), strings.HasPrefix(line, "msgstr["):
+			return nil, nil, fmt.Errorf("line %d: plural forms are not supported (use templates instead)", lineNumber)
+		case strings.HasPrefix(line, `"`):
+			if current == nil {
+				continue
+			}
+			quoted = line
+		default:
+			return nil, nil, fmt.Errorf("line %d: invalid line: %s", lineNumber, line)
+		}
+
+		s, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		current.WriteString(s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	flush()
+
+	if len(messages) == 0 {
+		return nil, nil, errors.New("no translations")
+	}
+	return header, messages, nil
+}
+
+func parsePOHeader(s string) map[string]string {
+	header := make(map[string]string)
+	for _, line := range strings.Split(s, "\n") {
+		if i := strings.Index(line, ":"); i > 0 {
+			header[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	return header
+}
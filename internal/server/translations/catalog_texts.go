@@ -0,0 +1,702 @@
+package translations
+
+import (
+	"html"
+	"time"
+
+	"go101.org/golds/code"
+)
+
+///////////////////////////////////////////////////////////////////
+// common
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_Space() string {
+	if s, ok := c.text("Space", nil); ok {
+		return s
+	}
+	return c.English.Text_Space()
+}
+
+func (c *Catalog) Text_Comma() string {
+	if s, ok := c.text("Comma", nil); ok {
+		return s
+	}
+	return c.English.Text_Comma()
+}
+
+func (c *Catalog) Text_Colon(atLineEnd bool) string {
+	if s, ok := c.text("Colon", catalogData{"atLineEnd": atLineEnd}); ok {
+		return s
+	}
+	return c.English.Text_Colon(atLineEnd)
+}
+
+func (c *Catalog) Text_Period(paragraphEnd bool) string {
+	if s, ok := c.text("Period", catalogData{"paragraphEnd": paragraphEnd}); ok {
+		return s
+	}
+	return c.English.Text_Period(paragraphEnd)
+}
+
+func (c *Catalog) Text_Parenthesis(close bool) string {
+	if s, ok := c.text("Parenthesis", catalogData{"close": close}); ok {
+		return s
+	}
+	return c.English.Text_Parenthesis(close)
+}
+
+func (c *Catalog) Text_EnclosedInOarentheses(text string) string {
+	if s, ok := c.text("EnclosedInOarentheses", catalogData{"text": text}); ok {
+		return s
+	}
+	return c.English.Text_EnclosedInOarentheses(text)
+}
+
+func (c *Catalog) Text_PreferredFontList() string {
+	if s, ok := c.text("PreferredFontList", nil); ok {
+		return s
+	}
+	return c.English.Text_PreferredFontList()
+}
+
+///////////////////////////////////////////////////////////////////
+// server
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_Server_Started() string {
+	if s, ok := c.text("Server_Started", nil); ok {
+		return s
+	}
+	return c.English.Text_Server_Started()
+}
+
+///////////////////////////////////////////////////////////////////
+// analyzing
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_Analyzing() string {
+	if s, ok := c.text("Analyzing", nil); ok {
+		return s
+	}
+	return c.English.Text_Analyzing()
+}
+
+func (c *Catalog) Text_AnalyzingRefresh(currentPageURL string) string {
+	if s, ok := c.text("AnalyzingRefresh", catalogData{"currentPageURL": currentPageURL}); ok {
+		return s
+	}
+	return c.English.Text_AnalyzingRefresh(currentPageURL)
+}
+
+func (c *Catalog) Text_Analyzing_Start() string {
+	if s, ok := c.text("Analyzing_Start", nil); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_Start()
+}
+
+func (c *Catalog) Text_Analyzing_Done(d time.Duration, memoryUse string) string {
+	if s, ok := c.text("Analyzing_Done", catalogData{"d": d, "memoryUse": memoryUse}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_Done(d, memoryUse)
+}
+
+func (c *Catalog) Text_Analyzing_PreparationDone(d time.Duration) string {
+	if s, ok := c.text("Analyzing_PreparationDone", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_PreparationDone(d)
+}
+
+func (c *Catalog) Text_Analyzing_NFilesParsed(numFiles int, d time.Duration) string {
+	if s, ok := c.text("Analyzing_NFilesParsed", catalogData{"numFiles": numFiles, "d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_NFilesParsed(numFiles, d)
+}
+
+func (c *Catalog) Text_Analyzing_ParsePackagesDone(numFiles int, d time.Duration) string {
+	if s, ok := c.text("Analyzing_ParsePackagesDone", catalogData{"numFiles": numFiles, "d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_ParsePackagesDone(numFiles, d)
+}
+
+func (c *Catalog) Text_Analyzing_CollectPackages(numMods int, d time.Duration) string {
+	if s, ok := c.text("Analyzing_CollectPackages", catalogData{"numMods": numMods, "d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_CollectPackages(numMods, d)
+}
+
+func (c *Catalog) Text_Analyzing_CollectModules(numPkgs int, d time.Duration) string {
+	if s, ok := c.text("Analyzing_CollectModules", catalogData{"numPkgs": numPkgs, "d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_CollectModules(numPkgs, d)
+}
+
+func (c *Catalog) Text_Analyzing_CollectExamples(d time.Duration) string {
+	if s, ok := c.text("Analyzing_CollectExamples", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_CollectExamples(d)
+}
+
+func (c *Catalog) Text_Analyzing_SortPackagesByDependencies(d time.Duration) string {
+	if s, ok := c.text("Analyzing_SortPackagesByDependencies", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_SortPackagesByDependencies(d)
+}
+
+func (c *Catalog) Text_Analyzing_CollectDeclarations(d time.Duration) string {
+	if s, ok := c.text("Analyzing_CollectDeclarations", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_CollectDeclarations(d)
+}
+
+func (c *Catalog) Text_Analyzing_CollectRuntimeFunctionPositions(d time.Duration) string {
+	if s, ok := c.text("Analyzing_CollectRuntimeFunctionPositions", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_CollectRuntimeFunctionPositions(d)
+}
+
+func (c *Catalog) Text_Analyzing_CollectSelectors(d time.Duration) string {
+	if s, ok := c.text("Analyzing_CollectSelectors", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_CollectSelectors(d)
+}
+
+func (c *Catalog) Text_Analyzing_FindImplementations(d time.Duration) string {
+	if s, ok := c.text("Analyzing_FindImplementations", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_FindImplementations(d)
+}
+
+func (c *Catalog) Text_Analyzing_RegisterInterfaceMethodsForTypes(d time.Duration) string {
+	if s, ok := c.text("Analyzing_RegisterInterfaceMethodsForTypes", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_RegisterInterfaceMethodsForTypes(d)
+}
+
+func (c *Catalog) Text_Analyzing_MakeStatistics(d time.Duration) string {
+	if s, ok := c.text("Analyzing_MakeStatistics", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_MakeStatistics(d)
+}
+
+func (c *Catalog) Text_Analyzing_CollectSourceFiles(d time.Duration) string {
+	if s, ok := c.text("Analyzing_CollectSourceFiles", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_CollectSourceFiles(d)
+}
+
+func (c *Catalog) Text_Analyzing_CollectObjectReferences(d time.Duration) string {
+	if s, ok := c.text("Analyzing_CollectObjectReferences", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_CollectObjectReferences(d)
+}
+
+func (c *Catalog) Text_Analyzing_CacheSourceFiles(d time.Duration) string {
+	if s, ok := c.text("Analyzing_CacheSourceFiles", catalogData{"d": d}); ok {
+		return s
+	}
+	return c.English.Text_Analyzing_CacheSourceFiles(d)
+}
+
+///////////////////////////////////////////////////////////////////
+// overview page
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_Overview() string {
+	if s, ok := c.text("Overview", nil); ok {
+		return s
+	}
+	return c.English.Text_Overview()
+}
+
+func (c *Catalog) Text_PackageList() string {
+	if s, ok := c.text("PackageList", nil); ok {
+		return s
+	}
+	return c.English.Text_PackageList()
+}
+
+func (c *Catalog) Text_StatisticsWithMoreLink(detailedStatsLink string) string {
+	if s, ok := c.text("StatisticsWithMoreLink", catalogData{"detailedStatsLink": detailedStatsLink}); ok {
+		return s
+	}
+	return c.English.Text_StatisticsWithMoreLink(detailedStatsLink)
+}
+
+func (c *Catalog) Text_SimpleStats(stats *code.Stats) string {
+	if s, ok := c.text("SimpleStats", catalogData{"stats": stats}); ok {
+		return s
+	}
+	return c.English.Text_SimpleStats(stats)
+}
+
+func (c *Catalog) Text_Modules() string {
+	if s, ok := c.text("Modules", nil); ok {
+		return s
+	}
+	return c.English.Text_Modules()
+}
+
+func (c *Catalog) Text_BelongingModule() string {
+	if s, ok := c.text("BelongingModule", nil); ok {
+		return s
+	}
+	return c.English.Text_BelongingModule()
+}
+
+func (c *Catalog) Text_RequireStat(numRequires, numRequiredBys int) string {
+	if s, ok := c.text("RequireStat", catalogData{"numRequires": numRequires, "numRequiredBys": numRequiredBys}); ok {
+		return s
+	}
+	return c.English.Text_RequireStat(numRequires, numRequiredBys)
+}
+
+func (c *Catalog) Text_UpdateTip(tipName string) string {
+	if s, ok := c.text("UpdateTip", catalogData{"tipName": tipName}); ok {
+		return s
+	}
+	return c.English.Text_UpdateTip(tipName)
+}
+
+func (c *Catalog) Text_SortBy(whatToSort string) string {
+	if s, ok := c.text("SortBy", catalogData{"whatToSort": whatToSort}); ok {
+		return s
+	}
+	return c.English.Text_SortBy(whatToSort)
+}
+
+func (c *Catalog) Text_SortByItem(by string) string {
+	if s, ok := c.text("SortByItem", catalogData{"by": by}); ok {
+		return s
+	}
+	return c.English.Text_SortByItem(by)
+}
+
+///////////////////////////////////////////////////////////////////
+// package details page
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_Package(pkgPath string) string {
+	if s, ok := c.text("Package", catalogData{"pkgPath": pkgPath}); ok {
+		return s
+	}
+	return c.English.Text_Package(pkgPath)
+}
+
+func (c *Catalog) Text_BelongingPackage() string {
+	if s, ok := c.text("BelongingPackage", nil); ok {
+		return s
+	}
+	return c.English.Text_BelongingPackage()
+}
+
+func (c *Catalog) Text_PackageDocsLinksOnOtherWebsites(pkgPath string, isStdPkg bool) string {
+	if s, ok := c.text("PackageDocsLinksOnOtherWebsites", catalogData{"pkgPath": pkgPath, "isStdPkg": isStdPkg}); ok {
+		return s
+	}
+	return c.English.Text_PackageDocsLinksOnOtherWebsites(pkgPath, isStdPkg)
+}
+
+func (c *Catalog) Text_ImportPath() string {
+	if s, ok := c.text("ImportPath", nil); ok {
+		return s
+	}
+	return c.English.Text_ImportPath()
+}
+
+func (c *Catalog) Text_PageNotFound() string {
+	if s, ok := c.text("PageNotFound", nil); ok {
+		return s
+	}
+	return c.English.Text_PageNotFound()
+}
+
+func (c *Catalog) Text_PackageDocsNotGenerated() string {
+	if s, ok := c.text("PackageDocsNotGenerated", nil); ok {
+		return s
+	}
+	return c.English.Text_PackageDocsNotGenerated()
+}
+
+func (c *Catalog) Text_ImportStat(numImports, numImportedBys int, depPageURL string) string {
+	if s, ok := c.text("ImportStat", catalogData{"numImports": numImports, "numImportedBys": numImportedBys, "depPageURL": depPageURL}); ok {
+		return s
+	}
+	return c.English.Text_ImportStat(numImports, numImportedBys, depPageURL)
+}
+
+func (c *Catalog) Text_InvolvedFiles(num int) string {
+	if s, ok := c.text("InvolvedFiles", catalogData{"num": num}); ok {
+		return s
+	}
+	return c.English.Text_InvolvedFiles(num)
+}
+
+func (c *Catalog) Text_Examples(num int) string {
+	if s, ok := c.text("Examples", catalogData{"num": num}); ok {
+		return s
+	}
+	return c.English.Text_Examples(num)
+}
+
+func (c *Catalog) Text_PackageLevelTypeNames() string {
+	if s, ok := c.text("PackageLevelTypeNames", nil); ok {
+		return s
+	}
+	return c.English.Text_PackageLevelTypeNames()
+}
+
+func (c *Catalog) Text_TypeParameters() string {
+	if s, ok := c.text("TypeParameters", nil); ok {
+		return s
+	}
+	return c.English.Text_TypeParameters()
+}
+
+func (c *Catalog) Text_PackageLevelFunctions() string {
+	if s, ok := c.text("PackageLevelFunctions", nil); ok {
+		return s
+	}
+	return c.English.Text_PackageLevelFunctions()
+}
+
+func (c *Catalog) Text_PackageLevelVariables() string {
+	if s, ok := c.text("PackageLevelVariables", nil); ok {
+		return s
+	}
+	return c.English.Text_PackageLevelVariables()
+}
+
+func (c *Catalog) Text_PackageLevelConstants() string {
+	if s, ok := c.text("PackageLevelConstants", nil); ok {
+		return s
+	}
+	return c.English.Text_PackageLevelConstants()
+}
+
+func (c *Catalog) Text_PackageLevelResourceSimpleStat(statsAreExact bool, num, numExporteds int, mentionExporteds bool) string {
+	if s, ok := c.text("PackageLevelResourceSimpleStat", catalogData{"statsAreExact": statsAreExact, "num": num, "numExporteds": numExporteds, "mentionExporteds": mentionExporteds}); ok {
+		return s
+	}
+	return c.English.Text_PackageLevelResourceSimpleStat(statsAreExact, num, numExporteds, mentionExporteds)
+}
+
+func (c *Catalog) Text_UnexportedResourcesHeader(show bool, numUnexporteds int, exact bool) string {
+	if s, ok := c.text("UnexportedResourcesHeader", catalogData{"show": show, "numUnexporteds": numUnexporteds, "exact": exact}); ok {
+		return s
+	}
+	return c.English.Text_UnexportedResourcesHeader(show, numUnexporteds, exact)
+}
+
+func (c *Catalog) Text_ListUnexportes() string {
+	if s, ok := c.text("ListUnexportes", nil); ok {
+		return s
+	}
+	return c.English.Text_ListUnexportes()
+}
+
+func (c *Catalog) Text_BasicType() string {
+	if s, ok := c.text("BasicType", nil); ok {
+		return s
+	}
+	return c.English.Text_BasicType()
+}
+
+func (c *Catalog) Text_Fields() string {
+	if s, ok := c.text("Fields", nil); ok {
+		return s
+	}
+	return c.English.Text_Fields()
+}
+
+func (c *Catalog) Text_Methods() string {
+	if s, ok := c.text("Methods", nil); ok {
+		return s
+	}
+	return c.English.Text_Methods()
+}
+
+func (c *Catalog) Text_ImplementedBy() string {
+	if s, ok := c.text("ImplementedBy", nil); ok {
+		return s
+	}
+	return c.English.Text_ImplementedBy()
+}
+
+func (c *Catalog) Text_Implements() string {
+	if s, ok := c.text("Implements", nil); ok {
+		return s
+	}
+	return c.English.Text_Implements()
+}
+
+func (c *Catalog) Text_AsOutputsOf() string {
+	if s, ok := c.text("AsOutputsOf", nil); ok {
+		return s
+	}
+	return c.English.Text_AsOutputsOf()
+}
+
+func (c *Catalog) Text_AsInputsOf() string {
+	if s, ok := c.text("AsInputsOf", nil); ok {
+		return s
+	}
+	return c.English.Text_AsInputsOf()
+}
+
+func (c *Catalog) Text_AsTypesOf() string {
+	if s, ok := c.text("AsTypesOf", nil); ok {
+		return s
+	}
+	return c.English.Text_AsTypesOf()
+}
+
+///////////////////////////////////////////////////////////////////
+// package dependencies page
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_DependencyRelations(pkgPath string) string {
+	if s, ok := c.text("DependencyRelations", catalogData{"pkgPath": pkgPath}); ok {
+		return s
+	}
+	return c.English.Text_DependencyRelations(pkgPath)
+}
+
+func (c *Catalog) Text_Imports() string {
+	if s, ok := c.text("Imports", nil); ok {
+		return s
+	}
+	return c.English.Text_Imports()
+}
+
+func (c *Catalog) Text_ImportedBy() string {
+	if s, ok := c.text("ImportedBy", nil); ok {
+		return s
+	}
+	return c.English.Text_ImportedBy()
+}
+
+///////////////////////////////////////////////////////////////////
+// method implementation page
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_MethodImplementations() string {
+	if s, ok := c.text("MethodImplementations", nil); ok {
+		return s
+	}
+	return c.English.Text_MethodImplementations()
+}
+
+func (c *Catalog) Text_NumMethodsImplementingNothing(count int) string {
+	if s, ok := c.text("NumMethodsImplementingNothing", catalogData{"count": count}); ok {
+		return s
+	}
+	return c.English.Text_NumMethodsImplementingNothing(count)
+}
+
+func (c *Catalog) Text_ViewMethodImplementations() string {
+	if s, ok := c.text("ViewMethodImplementations", nil); ok {
+		return s
+	}
+	return c.English.Text_ViewMethodImplementations()
+}
+
+///////////////////////////////////////////////////////////////////
+// object references(uses) page
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_ReferenceList() string {
+	if s, ok := c.text("ReferenceList", nil); ok {
+		return s
+	}
+	return c.English.Text_ReferenceList()
+}
+
+func (c *Catalog) Text_CurrentPackage() string {
+	if s, ok := c.text("CurrentPackage", nil); ok {
+		return s
+	}
+	return c.English.Text_CurrentPackage()
+}
+
+func (c *Catalog) Text_ObjectKind(kind string) string {
+	if s, ok := c.text("ObjectKind", catalogData{"kind": kind}); ok {
+		return s
+	}
+	return c.English.Text_ObjectKind(kind)
+}
+
+func (c *Catalog) Text_ObjectUses(num int) string {
+	if s, ok := c.text("ObjectUses", catalogData{"num": num}); ok {
+		return s
+	}
+	return c.English.Text_ObjectUses(num)
+}
+
+///////////////////////////////////////////////////////////////////
+// source code page
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_SourceCode(pkgPath, bareFilename string) string {
+	if s, ok := c.text("SourceCode", catalogData{"pkgPath": pkgPath, "bareFilename": bareFilename}); ok {
+		return s
+	}
+	return c.English.Text_SourceCode(pkgPath, bareFilename)
+}
+
+func (c *Catalog) Text_SourceFilePath() string {
+	if s, ok := c.text("SourceFilePath", nil); ok {
+		return s
+	}
+	return c.English.Text_SourceFilePath()
+}
+
+func (c *Catalog) Text_GeneratedFrom() string {
+	if s, ok := c.text("GeneratedFrom", nil); ok {
+		return s
+	}
+	return c.English.Text_GeneratedFrom()
+}
+
+///////////////////////////////////////////////////////////////////
+// statistics
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_Statistics() string {
+	if s, ok := c.text("Statistics", nil); ok {
+		return s
+	}
+	return c.English.Text_Statistics()
+}
+
+func (c *Catalog) Text_ChartTitle(chartName string) string {
+	if s, ok := c.text("ChartTitle", catalogData{"chartName": chartName}); ok {
+		return s
+	}
+	return c.English.Text_ChartTitle(chartName)
+}
+
+func (c *Catalog) Text_StatisticsTitle(titleName string) string {
+	if s, ok := c.text("StatisticsTitle", catalogData{"titleName": titleName}); ok {
+		return s
+	}
+	return c.English.Text_StatisticsTitle(titleName)
+}
+
+func (c *Catalog) Text_PackageStatistics(values map[string]interface{}) []string {
+	return c.texts("PackageStatistics", values, c.English.Text_PackageStatistics(values))
+}
+
+func (c *Catalog) Text_TypeStatistics(values map[string]interface{}) []string {
+	return c.texts("TypeStatistics", values, c.English.Text_TypeStatistics(values))
+}
+
+func (c *Catalog) Text_ValueStatistics(values map[string]interface{}) []string {
+	return c.texts("ValueStatistics", values, c.English.Text_ValueStatistics(values))
+}
+
+func (c *Catalog) Text_Othertatistics(values map[string]interface{}) []string {
+	return c.texts("Othertatistics", values, c.English.Text_Othertatistics(values))
+}
+
+///////////////////////////////////////////////////////////////////
+// search
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_Search() string {
+	if s, ok := c.text("Search", nil); ok {
+		return s
+	}
+	return c.English.Text_Search()
+}
+
+func (c *Catalog) Text_SearchResultKind(kind string) string {
+	if s, ok := c.text("SearchResultKind", catalogData{"kind": kind}); ok {
+		return s
+	}
+	return c.English.Text_SearchResultKind(kind)
+}
+
+func (c *Catalog) Text_SearchResultStat(numResults int, query string) string {
+	if s, ok := c.text("SearchResultStat", catalogData{"numResults": numResults, "query": html.EscapeString(query)}); ok {
+		return s
+	}
+	return c.English.Text_SearchResultStat(numResults, query)
+}
+
+func (c *Catalog) Text_CodeSearch() string {
+	if s, ok := c.text("CodeSearch", nil); ok {
+		return s
+	}
+	return c.English.Text_CodeSearch()
+}
+
+func (c *Catalog) Text_CodeSearchOption(option string) string {
+	if s, ok := c.text("CodeSearchOption", catalogData{"option": option}); ok {
+		return s
+	}
+	return c.English.Text_CodeSearchOption(option)
+}
+
+func (c *Catalog) Text_CodeSearchResultStat(numMatches, numFiles int, truncated bool) string {
+	if s, ok := c.text("CodeSearchResultStat", catalogData{"numMatches": numMatches, "numFiles": numFiles, "truncated": truncated}); ok {
+		return s
+	}
+	return c.English.Text_CodeSearchResultStat(numMatches, numFiles, truncated)
+}
+
+func (c *Catalog) Text_SignatureSearch() string {
+	if s, ok := c.text("SignatureSearch", nil); ok {
+		return s
+	}
+	return c.English.Text_SignatureSearch()
+}
+
+func (c *Catalog) Text_SignatureSearchTip() string {
+	if s, ok := c.text("SignatureSearchTip", nil); ok {
+		return s
+	}
+	return c.English.Text_SignatureSearchTip()
+}
+
+func (c *Catalog) Text_SignatureSearchResultStat(numFunctions int, truncated bool) string {
+	if s, ok := c.text("SignatureSearchResultStat", catalogData{"numFunctions": numFunctions, "truncated": truncated}); ok {
+		return s
+	}
+	return c.English.Text_SignatureSearchResultStat(numFunctions, truncated)
+}
+
+///////////////////////////////////////////////////////////////////
+// footer
+///////////////////////////////////////////////////////////////////
+
+func (c *Catalog) Text_GeneratedPageFooter(goldsVersion, qrCodeLink, goOS, goArch string) string {
+	if s, ok := c.text("GeneratedPageFooter", catalogData{"goldsVersion": goldsVersion, "qrCodeLink": qrCodeLink, "goOS": goOS, "goArch": goArch}); ok {
+		return s
+	}
+	return c.English.Text_GeneratedPageFooter(goldsVersion, qrCodeLink, goOS, goArch)
+}
+
+func (c *Catalog) Text_GeneratedPageFooterSimple(goldsVersion, goOS, goArch string) string {
+	if s, ok := c.text("GeneratedPageFooterSimple", catalogData{"goldsVersion": goldsVersion, "goOS": goOS, "goArch": goArch}); ok {
+		return s
+	}
+	return c.English.Text_GeneratedPageFooterSimple(goldsVersion, goOS, goArch)
+}
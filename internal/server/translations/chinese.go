@@ -2,6 +2,7 @@ package translations
 
 import (
 	"fmt"
+	"html"
 	"time"
 
 	"go101.org/golds/code"
@@ -216,6 +217,12 @@ func (*Chinese) Text_SortByItem(by string) string {
 		return "按依赖距离排序"
 	case "codelines":
 		return "按代码行数排序"
+	case "instability":
+		return "按不稳定度排序"
+	case "abstractness":
+		return "按抽象度排序"
+	case "distance":
+		return "按主序列距离排序"
 	default:
 		panic("unknown sort-by: " + by)
 	}
@@ -241,6 +248,12 @@ func (*Chinese) Text_PackageDocsLinksOnOtherWebsites(pkgPath string, isStdPkg bo
 
 func (*Chinese) Text_ImportPath() string { return "引入路径" }
 
+func (*Chinese) Text_PageNotFound() string { return "页面未找到" }
+
+func (*Chinese) Text_PackageDocsNotGenerated() string {
+	return "此代码包的完整文档未被生成。"
+}
+
 func (*Chinese) Text_ImportStat(numImports, numImportedBys int, depPageURL string) string {
 	importsStr := fmt.Sprintf("%d个代码包", numImports)
 	if numImports > 0 {
@@ -491,6 +504,12 @@ func (*Chinese) Text_ChartTitle(chartName string) string {
 		return "导出的非接口类型名数量按照导出方法数的分布"
 	case "exportedinterfacetypes-by-exportedmethods":
 		return "导出的接口类型名数量按照导出方法数的分布"
+	case "packages-by-instability":
+		return "库包数量按照不稳定度的分布"
+	case "packages-by-abstractness":
+		return "库包数量按照抽象度的分布"
+	case "packages-by-distance":
+		return "库包数量按照主序列距离的分布"
 	default:
 		panic("unknown char name: " + chartName)
 	}
@@ -684,6 +703,84 @@ func (*Chinese) Text_Othertatistics(values map[string]interface{}) []string {
 	}
 }
 
+///////////////////////////////////////////////////////////////////
+// search
+///////////////////////////////////////////////////////////////////
+
+func (*Chinese) Text_Search() string { return "搜索" }
+
+func (*Chinese) Text_SearchResultKind(kind string) string {
+	switch kind {
+	case "package":
+		return "库包"
+	case "type":
+		return "类型"
+	case "function":
+		return "函数"
+	case "method":
+		return "方法"
+	case "field":
+		return "字段"
+	case "variable":
+		return "变量"
+	case "constant":
+		return "常量"
+	default:
+		panic("unknown search result kind: " + kind)
+	}
+}
+
+func (*Chinese) Text_SearchResultStat(numResults int, query string) string {
+	query = html.EscapeString(query)
+	if numResults == 0 {
+		return fmt.Sprintf("没有找到<b>%s</b>的搜索结果。", query)
+	}
+	return fmt.Sprintf("找到%d个<b>%s</b>的搜索结果。", numResults, query)
+}
+
+func (*Chinese) Text_CodeSearch() string { return "代码搜索" }
+
+func (*Chinese) Text_CodeSearchOption(option string) string {
+	switch option {
+	case "regexp":
+		return "正则表达式"
+	case "scope":
+		return "模块路径或者库包路径前缀"
+	default:
+		panic("unknown code search option: " + option)
+	}
+}
+
+func (*Chinese) Text_CodeSearchResultStat(numMatches, numFiles int, truncated bool) string {
+	if numMatches == 0 {
+		return "没有匹配的代码行。"
+	}
+	more := ""
+	if truncated {
+		more = "（只列出了前面的部分）"
+	}
+	return fmt.Sprintf("在%d个文件中找到%d个匹配的代码行%s。", numFiles, numMatches, more)
+}
+
+func (*Chinese) Text_SignatureSearch() string { return "函数签名搜索" }
+
+func (*Chinese) Text_SignatureSearchTip() string {
+	return `
+示例：<b>func(io.Reader) ([]byte, error)</b>、<b>returns *http.Request</b>、<b>takes string, _</b>、<b>time.Duration</b>。
+参数和结果的顺序不要求完全一致。使用<b>_</b>来匹配任何类型。`
+}
+
+func (*Chinese) Text_SignatureSearchResultStat(numFunctions int, truncated bool) string {
+	switch {
+	case numFunctions == 0:
+		return "没有匹配的函数。"
+	case truncated:
+		return fmt.Sprintf("前%d个匹配的函数：", numFunctions)
+	default:
+		return fmt.Sprintf("%d个匹配的函数：", numFunctions)
+	}
+}
+
 ///////////////////////////////////////////////////////////////////
 // footer
 ///////////////////////////////////////////////////////////////////
@@ -2,6 +2,7 @@ package translations
 
 import (
 	"fmt"
+	"html"
 	"time"
 
 	"go101.org/golds/code"
@@ -238,6 +239,12 @@ func (*English) Text_SortByItem(by string) string {
 		return "dependency distance"
 	case "codelines":
 		return "lines of code"
+	case "instability":
+		return "instability"
+	case "abstractness":
+		return "abstractness"
+	case "distance":
+		return "distance from main sequence"
 	default:
 		panic("unknown sort-by: " + by)
 	}
@@ -264,6 +271,12 @@ func (*English) Text_PackageDocsLinksOnOtherWebsites(pkgPath string, isStdPkg bo
 
 func (*English) Text_ImportPath() string { return "Import Path" }
 
+func (*English) Text_PageNotFound() string { return "Page not found" }
+
+func (*English) Text_PackageDocsNotGenerated() string {
+	return "The full docs of this package are not generated."
+}
+
 func (*English) Text_ImportStat(numImports, numImportedBys int, depPageURL string) string {
 	var importsStr, importedBysStr string
 
@@ -559,6 +572,12 @@ func (*English) Text_ChartTitle(chartName string) string {
 		return "Numbers of Exported Non-Interface Types by Exported Method Counts"
 	case "exportedinterfacetypes-by-exportedmethods":
 		return "Numbers of Exported Interface Types by Exported Method Counts"
+	case "packages-by-instability":
+		return "Numbers of Packages by Instability"
+	case "packages-by-abstractness":
+		return "Numbers of Packages by Abstractness"
+	case "packages-by-distance":
+		return "Numbers of Packages by Distance from Main Sequence"
 	default:
 		panic("unknown char name: " + chartName)
 	}
@@ -755,6 +774,90 @@ func (*English) Text_Othertatistics(values map[string]interface{}) []string {
 	}
 }
 
+///////////////////////////////////////////////////////////////////
+// search
+///////////////////////////////////////////////////////////////////
+
+func (*English) Text_Search() string { return "Search" }
+
+func (*English) Text_SearchResultKind(kind string) string {
+	switch kind {
+	case "package":
+		return "Packages"
+	case "type":
+		return "Types"
+	case "function":
+		return "Functions"
+	case "method":
+		return "Methods"
+	case "field":
+		return "Fields"
+	case "variable":
+		return "Variables"
+	case "constant":
+		return "Constants"
+	default:
+		panic("unknown search result kind: " + kind)
+	}
+}
+
+func (*English) Text_SearchResultStat(numResults int, query string) string {
+	query = html.EscapeString(query)
+	switch numResults {
+	case 0:
+		return fmt.Sprintf("No results for <b>%s</b>.", query)
+	case 1:
+		return fmt.Sprintf("One result for <b>%s</b>.", query)
+	default:
+		return fmt.Sprintf("%d results for <b>%s</b>.", numResults, query)
+	}
+}
+
+func (*English) Text_CodeSearch() string { return "Code Search" }
+
+func (*English) Text_CodeSearchOption(option string) string {
+	switch option {
+	case "regexp":
+		return "regular expression"
+	case "scope":
+		return "module path or package path prefix"
+	default:
+		panic("unknown code search option: " + option)
+	}
+}
+
+func (*English) Text_CodeSearchResultStat(numMatches, numFiles int, truncated bool) string {
+	if numMatches == 0 {
+		return "No matched lines."
+	}
+	more := ""
+	if truncated {
+		more = " (only the first ones are listed)"
+	}
+	return fmt.Sprintf("%d matched lines in %d files%s.", numMatches, numFiles, more)
+}
+
+func (*English) Text_SignatureSearch() string { return "Signature Search" }
+
+func (*English) Text_SignatureSearchTip() string {
+	return `
+Examples: <b>func(io.Reader) ([]byte, error)</b>, <b>returns *http.Request</b>, <b>takes string, _</b>, <b>time.Duration</b>.
+Parameter and result orders are not required to be exact. Use <b>_</b> to match any type.`
+}
+
+func (*English) Text_SignatureSearchResultStat(numFunctions int, truncated bool) string {
+	switch {
+	case numFunctions == 0:
+		return "No matched functions."
+	case truncated:
+		return fmt.Sprintf("The first %d matched functions:", numFunctions)
+	case numFunctions == 1:
+		return "One matched function:"
+	default:
+		return fmt.Sprintf("%d matched functions:", numFunctions)
+	}
+}
+
 ///////////////////////////////////////////////////////////////////
 // footer
 ///////////////////////////////////////////////////////////////////
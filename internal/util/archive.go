@@ -0,0 +1,236 @@
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing/fstest"
+	"time"
+)
+
+// IsArchivePath returns whether or not a path is
+// a .zip or .tar.gz (or .tgz) archive path.
+func IsArchivePath(p string) bool {
+	return isZipPath(p) || isTarGzipPath(p)
+}
+
+func isZipPath(p string) bool {
+	return strings.HasSuffix(strings.ToLower(p), ".zip")
+}
+
+func isTarGzipPath(p string) bool {
+	p = strings.ToLower(p)
+	return strings.HasSuffix(p, ".tar.gz") || strings.HasSuffix(p, ".tgz")
+}
+
+// ArchiveWriter writes files into a .zip or .tar.gz archive file.
+type ArchiveWriter struct {
+	file *os.File
+
+	zipWriter  *zip.Writer
+	gzipWriter *gzip.Writer
+	tarWriter  *tar.Writer
+
+	modTime time.Time
+	dirs    map[string]struct{} // created directories in tar archives
+}
+
+// NewArchiveWriter creates an archive file. The archive format
+// is determined by the extension of the path (see IsArchivePath).
+func NewArchiveWriter(archivePath string) (*ArchiveWriter, error) {
+	if !IsArchivePath(archivePath) {
+		return nil, errors.New("not a .zip or .tar.gz path: " + archivePath)
+	}
+
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	aw := &ArchiveWriter{file: f, modTime: time.Now()}
+	if isZipPath(archivePath) {
+		aw.zipWriter = zip.NewWriter(f)
+	} else {
+		aw.gzipWriter = gzip.NewWriter(f)
+		aw.tarWriter = tar.NewWriter(aw.gzipWriter)
+		aw.dirs = make(map[string]struct{}, 1024)
+	}
+	return aw, nil
+}
+
+// WriteFile writes a file, whose content is the concatenation of
+// chunks, into the archive. name must be a slash-separated path.
+// It returns the number of written content bytes.
+func (aw *ArchiveWriter) WriteFile(name string, chunks ...[]byte) (int, error) {
+	if aw.zipWriter != nil {
+		w, err := aw.zipWriter.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: aw.modTime,
+		})
+		if err != nil {
+			return 0, err
+		}
+		return writeChunks(w, chunks)
+	}
+
+	if err := aw.createTarDirs(path.Dir(name)); err != nil {
+		return 0, err
+	}
+	size := 0
+	for _, c := range chunks {
+		size += len(c)
+	}
+	err := aw.tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     int64(size),
+		Mode:     0644,
+		ModTime:  aw.modTime,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return writeChunks(aw.tarWriter, chunks)
+}
+
+// Some tar tools need the directory entries.
+func (aw *ArchiveWriter) createTarDirs(dir string) error {
+	if dir == "." || dir == "/" {
+		return nil
+	}
+	if _, ok := aw.dirs[dir]; ok {
+		return nil
+	}
+	if err := aw.createTarDirs(path.Dir(dir)); err != nil {
+		return err
+	}
+	aw.dirs[dir] = struct{}{}
+	return aw.tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     dir + "/",
+		Mode:     0755,
+		ModTime:  aw.modTime,
+	})
+}
+
+func writeChunks(w io.Writer, chunks [][]byte) (n int, err error) {
+	for _, c := range chunks {
+		x, err := w.Write(c)
+		n += x
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Close finishes the archive and closes the archive file.
+func (aw *ArchiveWriter) Close() error {
+	var err error
+	if aw.zipWriter != nil {
+		err = aw.zipWriter.Close()
+	} else {
+		err = aw.tarWriter.Close()
+		if err2 := aw.gzipWriter.Close(); err == nil {
+			err = err2
+		}
+	}
+	if err2 := aw.file.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// OpenArchiveFS opens a .zip or .tar.gz archive file as a file system,
+// in which the files are seekable (as http.FileServer needs).
+// The files in a zip archive are read on opening, whereas the whole
+// content of a tar.gz archive is loaded into memory, for the format
+// doesn't support random access.
+func OpenArchiveFS(archivePath string) (fs.FS, error) {
+	if isZipPath(archivePath) {
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		return seekableFS{&zr.Reader}, nil
+	}
+
+	if !isTarGzipPath(archivePath) {
+		return nil, errors.New("not a .zip or .tar.gz path: " + archivePath)
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	tarReader := tar.NewReader(gzipReader)
+	mapFS := make(fstest.MapFS, 1024)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		mapFS[path.Clean(strings.TrimPrefix(header.Name, "/"))] = &fstest.MapFile{
+			Data:    data,
+			Mode:    0444,
+			ModTime: header.ModTime,
+		}
+	}
+	return mapFS, nil
+}
+
+// seekableFS reads a regular file into memory on opening it,
+// so that the opened file is seekable.
+type seekableFS struct {
+	fs.FS
+}
+
+func (sfs seekableFS) Open(name string) (fs.File, error) {
+	f, err := sfs.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return f, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return &memoryFile{Reader: bytes.NewReader(data), info: info}, nil
+}
+
+type memoryFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (mf *memoryFile) Stat() (fs.FileInfo, error) { return mf.info, nil }
+func (mf *memoryFile) Close() error               { return nil }
@@ -0,0 +1,70 @@
+package util
+
+import (
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Archive(t *testing.T) {
+	var files = []struct {
+		name   string
+		chunks [][]byte
+	}{
+		{"index.html", [][]byte{[]byte("<html>"), []byte("</html>")}},
+		{"pkg/net/http.html", [][]byte{[]byte("http")}},
+		{"css/default.css", nil},
+	}
+
+	for _, archiveName := range []string{"docs.zip", "docs.tar.gz"} {
+		archivePath := filepath.Join(t.TempDir(), archiveName)
+		aw, err := NewArchiveWriter(archivePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			if _, err := aw.WriteFile(f.name, f.chunks...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := aw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		fsys, err := OpenArchiveFS(archivePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			var content []byte
+			for _, c := range f.chunks {
+				content = append(content, c...)
+			}
+
+			file, err := fsys.Open(f.name)
+			if err != nil {
+				t.Errorf("%s: open %s error: %s", archiveName, f.name, err)
+				continue
+			}
+			if _, ok := file.(io.Seeker); !ok {
+				t.Errorf("%s: %s is not seekable", archiveName, f.name)
+			}
+			file.Close()
+
+			data, err := fs.ReadFile(fsys, f.name)
+			if err != nil {
+				t.Errorf("%s: read %s error: %s", archiveName, f.name, err)
+			} else if string(data) != string(content) {
+				t.Errorf("%s: content of %s not match: %q vs. %q", archiveName, f.name, data, content)
+			}
+		}
+		if info, err := fs.Stat(fsys, "pkg/net"); err != nil || !info.IsDir() {
+			t.Errorf("%s: directory pkg/net not found", archiveName)
+		}
+	}
+
+	if IsArchivePath("docs") || !IsArchivePath("docs.TAR.GZ") || !IsArchivePath("a/docs.zip") {
+		t.Errorf("IsArchivePath not match")
+	}
+}
@@ -88,7 +88,11 @@ NextTry:
 	go func() {
 		time.Sleep(time.Second)
 
-		log.Println("Serving directory:")
+		if IsArchivePath(dir) {
+			log.Println("Serving archive:")
+		} else {
+			log.Println("Serving directory:")
+		}
 		log.Print("   ", dir)
 		log.Println("Running at:")
 		log.Print("   http://localhost:", port)
@@ -109,7 +113,18 @@ NextTry:
 		}
 	}()
 
-	handler := NoCacheHandler(http.FileServer(http.Dir(dir)))
+	// The files in a .zip or .tar.gz archive are served directly,
+	// without extracting the archive.
+	var fileSystem http.FileSystem = http.Dir(dir)
+	if IsArchivePath(dir) {
+		fsys, err := OpenArchiveFS(dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fileSystem = http.FS(fsys)
+	}
+
+	handler := NoCacheHandler(http.FileServer(fileSystem))
 	if err = http.Serve(l, handler); err != nil {
 		log.Printf("Failed to start server: %v\n", err)
 	}
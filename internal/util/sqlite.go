@@ -0,0 +1,261 @@
+package util
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// WriteSQLiteTable writes a SQLite 3 database file which contains
+// only one rowid table. The table is created by createTableSQL, whose
+// first column must be an INTEGER PRIMARY KEY column (an alias of the
+// rowid). The rowid of rows[i] is i+1. The values in rows should be
+// nil, int, int64 or string values, and the first value of each row
+// (the one for the INTEGER PRIMARY KEY column) is ignored.
+//
+// No indexes are created, and there must not be large rows
+// which need overflow pages.
+func WriteSQLiteTable(w io.Writer, tableName, createTableSQL string, rows [][]interface{}) error {
+	cells := make([][]byte, len(rows))
+	for i, row := range rows {
+		values := append([]interface{}{nil}, row[1:]...)
+		cell, err := sqliteTableLeafCell(int64(i+1), values)
+		if err != nil {
+			return err
+		}
+		cells[i] = cell
+	}
+
+	// Build the table b-tree from bottom to up.
+	// The page numbers are assigned after the whole tree is built.
+	type node struct {
+		cells    [][]byte // for leaf nodes
+		children []*node  // for interior nodes
+		maxRowid int64
+		page     uint32
+	}
+
+	var level []*node
+	for start := 0; start < len(cells) || start == 0; {
+		n := &node{}
+		size := sqliteLeafHeaderSize
+		for ; start < len(cells); start++ {
+			cellSize := 2 + len(cells[start])
+			if size+cellSize > sqlitePageSize {
+				break
+			}
+			size += cellSize
+			n.cells = append(n.cells, cells[start])
+		}
+		if len(n.cells) == 0 {
+			if start < len(cells) {
+				return fmt.Errorf("row %d is too large", start)
+			}
+			level = append(level, n)
+			break
+		}
+		n.maxRowid = int64(start)
+		level = append(level, n)
+	}
+	for len(level) > 1 {
+		var upper []*node
+		for start := 0; start < len(level); {
+			n := &node{}
+			size := sqliteInteriorHeaderSize
+			for ; start < len(level); start++ {
+				cellSize := 2 + 4 + sqliteVarintLen(uint64(level[start].maxRowid))
+				if len(n.children) > 1 && size+cellSize > sqlitePageSize {
+					break
+				}
+				size += cellSize
+				n.children = append(n.children, level[start])
+			}
+			n.maxRowid = n.children[len(n.children)-1].maxRowid
+			upper = append(upper, n)
+		}
+		level = upper
+	}
+
+	// Page 1 is for the sqlite_schema table.
+	// Assign page numbers in breadth-first order, starting from 2.
+	var nodes = level
+	for i := 0; i < len(nodes); i++ {
+		nodes[i].page = uint32(i + 2)
+		nodes = append(nodes, nodes[i].children...)
+	}
+	numPages := uint32(len(nodes) + 1)
+
+	schemaCell, err := sqliteTableLeafCell(1, []interface{}{"table", tableName, tableName, int64(nodes[0].page), createTableSQL})
+	if err != nil {
+		return err
+	}
+
+	page := make([]byte, sqlitePageSize)
+	writeSQLiteHeader(page, numPages)
+	writeSQLiteLeafPage(page, sqliteHeaderSize, [][]byte{schemaCell})
+	if _, err := w.Write(page); err != nil {
+		return err
+	}
+
+	for _, n := range nodes {
+		for i := range page {
+			page[i] = 0
+		}
+		if n.children == nil {
+			writeSQLiteLeafPage(page, 0, n.cells)
+		} else {
+			interiorCells := make([][]byte, 0, len(n.children)-1)
+			for _, c := range n.children[:len(n.children)-1] {
+				cell := binary.BigEndian.AppendUint32(nil, c.page)
+				cell = sqliteAppendVarint(cell, uint64(c.maxRowid))
+				interiorCells = append(interiorCells, cell)
+			}
+			writeSQLitePage(page, 0, 0x05, interiorCells, n.children[len(n.children)-1].page)
+		}
+		if _, err := w.Write(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	sqlitePageSize           = 4096
+	sqliteHeaderSize         = 100
+	sqliteLeafHeaderSize     = 8
+	sqliteInteriorHeaderSize = 12
+
+	// The max payload size of a table leaf cell without overflow pages.
+	sqliteMaxLocalPayload = sqlitePageSize - 35
+)
+
+func writeSQLiteHeader(page []byte, numPages uint32) {
+	copy(page, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(page[16:], sqlitePageSize)
+	page[18], page[19] = 1, 1 // legacy file format
+	page[20] = 0              // reserved space
+	page[21], page[22], page[23] = 64, 32, 32
+	binary.BigEndian.PutUint32(page[24:], 1) // file change counter
+	binary.BigEndian.PutUint32(page[28:], numPages)
+	binary.BigEndian.PutUint32(page[40:], 1) // schema cookie
+	binary.BigEndian.PutUint32(page[44:], 4) // schema format
+	binary.BigEndian.PutUint32(page[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(page[92:], 1) // version-valid-for
+	binary.BigEndian.PutUint32(page[96:], 3008000)
+}
+
+func writeSQLiteLeafPage(page []byte, headerOffset int, cells [][]byte) {
+	writeSQLitePage(page, headerOffset, 0x0D, cells, 0)
+}
+
+// writeSQLitePage writes a b-tree page. headerOffset is 100 for page 1.
+func writeSQLitePage(page []byte, headerOffset int, pageType byte, cells [][]byte, rightMostPointer uint32) {
+	header := page[headerOffset:]
+	header[0] = pageType
+	binary.BigEndian.PutUint16(header[3:], uint16(len(cells)))
+	pointers := header[sqliteLeafHeaderSize:]
+	if pageType == 0x05 {
+		binary.BigEndian.PutUint32(header[8:], rightMostPointer)
+		pointers = header[sqliteInteriorHeaderSize:]
+	}
+
+	contentStart := len(page)
+	for i, cell := range cells {
+		contentStart -= len(cell)
+		copy(page[contentStart:], cell)
+		binary.BigEndian.PutUint16(pointers[2*i:], uint16(contentStart))
+	}
+	binary.BigEndian.PutUint16(header[5:], uint16(contentStart))
+}
+
+func sqliteTableLeafCell(rowid int64, values []interface{}) ([]byte, error) {
+	var header, body []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+			header = sqliteAppendVarint(header, 0)
+		case int:
+			header, body = sqliteAppendInteger(header, body, int64(v))
+		case int64:
+			header, body = sqliteAppendInteger(header, body, v)
+		case string:
+			header = sqliteAppendVarint(header, uint64(13+2*len(v)))
+			body = append(body, v...)
+		default:
+			return nil, fmt.Errorf("unsupported value type: %T", v)
+		}
+	}
+
+	// The header size includes the size varint itself.
+	headerSize := len(header) + 1
+	if sqliteVarintLen(uint64(headerSize)) > 1 {
+		headerSize++
+	}
+	payloadSize := headerSize + len(body)
+	if payloadSize > sqliteMaxLocalPayload {
+		return nil, fmt.Errorf("row %d is too large", rowid)
+	}
+
+	cell := sqliteAppendVarint(nil, uint64(payloadSize))
+	cell = sqliteAppendVarint(cell, uint64(rowid))
+	cell = sqliteAppendVarint(cell, uint64(headerSize))
+	cell = append(cell, header...)
+	cell = append(cell, body...)
+	return cell, nil
+}
+
+func sqliteAppendInteger(header, body []byte, v int64) ([]byte, []byte) {
+	switch {
+	case v == 0:
+		return sqliteAppendVarint(header, 8), body
+	case v == 1:
+		return sqliteAppendVarint(header, 9), body
+	}
+	var serialType uint64
+	var n int
+	switch {
+	case -1<<7 <= v && v < 1<<7:
+		serialType, n = 1, 1
+	case -1<<15 <= v && v < 1<<15:
+		serialType, n = 2, 2
+	case -1<<23 <= v && v < 1<<23:
+		serialType, n = 3, 3
+	case -1<<31 <= v && v < 1<<31:
+		serialType, n = 4, 4
+	case -1<<47 <= v && v < 1<<47:
+		serialType, n = 5, 6
+	default:
+		serialType, n = 6, 8
+	}
+	for i := n - 1; i >= 0; i-- {
+		body = append(body, byte(v>>(8*i)))
+	}
+	return sqliteAppendVarint(header, serialType), body
+}
+
+// sqliteAppendVarint appends v in the SQLite varint format,
+// which is big-endian and at most 9 bytes.
+func sqliteAppendVarint(buf []byte, v uint64) []byte {
+	if v > 1<<56-1 {
+		buf = append(buf, byte(v>>57)|0x80, byte(v>>50)|0x80, byte(v>>43)|0x80,
+			byte(v>>36)|0x80, byte(v>>29)|0x80, byte(v>>22)|0x80,
+			byte(v>>15)|0x80, byte(v>>8)|0x80)
+		return append(buf, byte(v))
+	}
+	n := sqliteVarintLen(v)
+	for i := n - 1; i > 0; i-- {
+		buf = append(buf, byte(v>>(7*i))|0x80)
+	}
+	return append(buf, byte(v)&0x7f)
+}
+
+func sqliteVarintLen(v uint64) int {
+	if v > 1<<56-1 {
+		return 9
+	}
+	n := 1
+	for v >>= 7; v > 0; v >>= 7 {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,53 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func Test_SQLiteVarint(t *testing.T) {
+	var testCases = []struct {
+		v       uint64
+		encoded []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x81, 0x00}},
+		{300, []byte{0x82, 0x2c}},
+		{1<<56 - 1, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+		{1 << 63, []byte{0xc0, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}},
+	}
+	for _, tc := range testCases {
+		encoded := sqliteAppendVarint(nil, tc.v)
+		if !bytes.Equal(encoded, tc.encoded) {
+			t.Errorf("sqlite varint of %d not match: %x vs. %x", tc.v, encoded, tc.encoded)
+		}
+		if n := sqliteVarintLen(tc.v); n != len(tc.encoded) {
+			t.Errorf("sqlite varint length of %d not match: %d vs. %d", tc.v, n, len(tc.encoded))
+		}
+	}
+}
+
+func Test_WriteSQLiteTable(t *testing.T) {
+	for _, n := range []int{0, 10, 10000} {
+		rows := make([][]interface{}, n)
+		for i := range rows {
+			rows[i] = []interface{}{nil, "name", "Type", int64(i) * 1000}
+		}
+		var buf bytes.Buffer
+		err := WriteSQLiteTable(&buf, "t", "CREATE TABLE t(id INTEGER PRIMARY KEY, name TEXT, type TEXT, n INTEGER)", rows)
+		if err != nil {
+			t.Errorf("write sqlite table (%d rows) error: %s", n, err)
+			continue
+		}
+		data := buf.Bytes()
+		if len(data)%sqlitePageSize != 0 {
+			t.Errorf("sqlite file size (%d rows) not match: %d", n, len(data))
+			continue
+		}
+		if numPages := binary.BigEndian.Uint32(data[28:]); int(numPages) != len(data)/sqlitePageSize {
+			t.Errorf("sqlite page count (%d rows) not match: %d vs. %d", n, numPages, len(data)/sqlitePageSize)
+		}
+	}
+}